@@ -0,0 +1,271 @@
+package abstraction
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type EqualOptions struct {
+	IgnoreRawPayload   bool          //RawPayload 비교 안 함
+	IgnoreExtras       bool          //Extras 비교 안 함
+	TimestampPrecision time.Duration //0보다 클 시 이 단위로 버린 뒤 비교(예: time.Second)
+	NilEqualsZero      bool          //nil *big.Int와 0을 같게 봄(0을 생략하는 포맷)
+} //Equal/Diff 비교 옵션(Original* 메타데이터는 항상 비교 안 함)
+
+type Change struct {
+	Path string      `json:"path"` //필드 경로(예: Height, CommitSeals[1], ViewChanges[0].Signature, Extras[key])
+	Old  interface{} `json:"old"`  //기준 메시지의 값(없을 시 nil)
+	New  interface{} `json:"new"`  //비교 대상 메시지의 값(없을 시 nil)
+} //필드 하나의 차이
+
+func (c Change) Field() string {
+	if strings.HasPrefix(c.Path, "Extras[") {
+		return c.Path
+	}
+	if i := strings.IndexAny(c.Path, ".["); i >= 0 {
+		return c.Path[:i]
+	}
+	return c.Path
+} //경로가 속한 최상위 필드명(Extras는 "Extras[key]" 그대로)
+
+type Diff []Change //두 메시지의 차이 목록(필드 선언 순서, Extras는 key 순)
+
+func (d Diff) String() string {
+	var sb strings.Builder
+	for _, c := range d {
+		fmt.Fprintf(&sb, "%s: %s -> %s\n", c.Path, diffText(c.Old), diffText(c.New))
+	}
+	return sb.String()
+} //"Height: 1 -> 2" 형태의 줄 목록
+
+func (d Diff) MarshalJSON() ([]byte, error) {
+	if d == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]Change(d))
+} //[{"path":"Height","old":1,"new":2}, ...] 형태(차이 없을 시 [])
+
+func diffText(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "<none>"
+	case string:
+		return strconv.Quote(t)
+	case MsgType:
+		return string(t)
+	case *big.Int:
+		return t.String()
+	case time.Time:
+		return t.Format(time.RFC3339Nano)
+	case []byte:
+		return "0x" + hex.EncodeToString(t)
+	case ViewChangeEntry:
+		return fmt.Sprintf("{View:%s Height:%s Validator:%q Signature:%q}", diffText(optBig(t.View)), diffText(optBig(t.Height)), t.Validator, t.Signature)
+	case ExtraValue:
+		if t.Kind == ExtraString {
+			return strconv.Quote(t.Str)
+		}
+		return t.Text()
+	}
+	return fmt.Sprint(v)
+} //Diff 텍스트 출력용 값 표기
+
+func (m *AbstractMessage) Clone() *AbstractMessage {
+	if m == nil {
+		return nil
+	}
+	c := *m
+	c.Height, c.Round, c.View = cloneBig(m.Height), cloneBig(m.Round), cloneBig(m.View)
+	if m.CommitSeals != nil {
+		c.CommitSeals = append([]string(nil), m.CommitSeals...)
+	}
+	if m.ViewChanges != nil {
+		c.ViewChanges = make([]ViewChangeEntry, len(m.ViewChanges))
+		for i, e := range m.ViewChanges {
+			c.ViewChanges[i] = ViewChangeEntry{View: cloneBig(e.View), Height: cloneBig(e.Height), Validator: e.Validator, Signature: e.Signature}
+		}
+	}
+	if m.Extras != nil {
+		c.Extras = make(map[string]ExtraValue, len(m.Extras))
+		for k, v := range m.Extras {
+			c.Extras[k] = v.Clone()
+		}
+	}
+	if m.RawPayload != nil {
+		c.RawPayload = append([]byte(nil), m.RawPayload...)
+	}
+	if m.OriginalFieldNames != nil {
+		c.OriginalFieldNames = make(map[string]string, len(m.OriginalFieldNames))
+		for k, v := range m.OriginalFieldNames {
+			c.OriginalFieldNames[k] = v
+		}
+	}
+	if m.OriginalPaths != nil {
+		c.OriginalPaths = make(map[string]string, len(m.OriginalPaths))
+		for k, v := range m.OriginalPaths {
+			c.OriginalPaths[k] = v
+		}
+	}
+	return &c //변경 추적 기준 상태(clean)는 읽기 전용이므로 공유
+} //깊은 복사(변경 추적 상태 포함)
+
+func cloneBig(x *big.Int) *big.Int {
+	if x == nil {
+		return nil
+	}
+	return new(big.Int).Set(x)
+} //*big.Int 복사
+
+func (m *AbstractMessage) Equal(o *AbstractMessage, opts EqualOptions) bool {
+	return len(m.Diff(o, opts)) == 0
+} //opts 기준으로 두 메시지가 같은지 확인
+
+func (m *AbstractMessage) Diff(o *AbstractMessage, opts EqualOptions) Diff {
+	if m == nil {
+		m = &AbstractMessage{}
+	}
+	if o == nil {
+		o = &AbstractMessage{}
+	}
+	var d Diff
+	add := func(path string, before, after interface{}) {
+		d = append(d, Change{Path: path, Old: before, New: after})
+	}
+	bigs := func(path string, a, b *big.Int) {
+		if !bigEqual(a, b, opts.NilEqualsZero) {
+			add(path, optBig(a), optBig(b))
+		}
+	}
+	strs := func(path, a, b string) {
+		if a != b {
+			add(path, optString(a), optString(b))
+		}
+	}
+	if m.Type != o.Type {
+		add("Type", optString(string(m.Type)), optString(string(o.Type)))
+	}
+	bigs("Height", m.Height, o.Height)
+	bigs("Round", m.Round, o.Round)
+	bigs("View", m.View, o.View)
+	ta, tb := m.Timestamp, o.Timestamp
+	if opts.TimestampPrecision > 0 {
+		ta, tb = ta.Truncate(opts.TimestampPrecision), tb.Truncate(opts.TimestampPrecision)
+	}
+	if !ta.Equal(tb) {
+		add("Timestamp", optTime(m.Timestamp), optTime(o.Timestamp))
+	}
+	strs("BlockHash", m.BlockHash, o.BlockHash)
+	strs("PrevHash", m.PrevHash, o.PrevHash)
+	strs("Proposer", m.Proposer, o.Proposer)
+	strs("Validator", m.Validator, o.Validator)
+	strs("Signature", m.Signature, o.Signature)
+	for i := 0; i < len(m.CommitSeals) || i < len(o.CommitSeals); i++ {
+		path := "CommitSeals[" + strconv.Itoa(i) + "]"
+		switch {
+		case i >= len(m.CommitSeals):
+			add(path, nil, o.CommitSeals[i])
+		case i >= len(o.CommitSeals):
+			add(path, m.CommitSeals[i], nil)
+		default:
+			strs(path, m.CommitSeals[i], o.CommitSeals[i])
+		}
+	}
+	for i := 0; i < len(m.ViewChanges) || i < len(o.ViewChanges); i++ {
+		path := "ViewChanges[" + strconv.Itoa(i) + "]"
+		switch {
+		case i >= len(m.ViewChanges):
+			add(path, nil, o.ViewChanges[i])
+		case i >= len(o.ViewChanges):
+			add(path, m.ViewChanges[i], nil)
+		default:
+			a, b := m.ViewChanges[i], o.ViewChanges[i]
+			bigs(path+".View", a.View, b.View)
+			bigs(path+".Height", a.Height, b.Height)
+			strs(path+".Validator", a.Validator, b.Validator)
+			strs(path+".Signature", a.Signature, b.Signature)
+		}
+	}
+	if !opts.IgnoreExtras {
+		for _, k := range extraKeys(m.Extras, o.Extras) {
+			a, okA := m.Extras[k]
+			b, okB := o.Extras[k]
+			switch {
+			case !okA:
+				add(ExtraField(k), nil, b)
+			case !okB:
+				add(ExtraField(k), a, nil)
+			case !a.Equal(b):
+				add(ExtraField(k), a, b)
+			}
+		}
+	}
+	if !opts.IgnoreRawPayload && !bytes.Equal(m.RawPayload, o.RawPayload) {
+		add("RawPayload", optBytes(m.RawPayload), optBytes(o.RawPayload))
+	}
+	return d
+} //m을 기준으로 o와 달라진 필드 경로와 이전/이후 값(차이 없을 시 nil)
+
+func bigEqual(a, b *big.Int, nilZero bool) bool {
+	if nilZero {
+		if a == nil {
+			a = new(big.Int)
+		}
+		if b == nil {
+			b = new(big.Int)
+		}
+	}
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Cmp(b) == 0
+} //*big.Int 비교(nilZero일 시 nil을 0으로)
+
+func extraKeys(a, b map[string]ExtraValue) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	keys := make([]string, 0, len(a)+len(b))
+	for _, m := range []map[string]ExtraValue{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+} //두 Extras의 key 합집합(정렬)
+
+func optBig(x *big.Int) interface{} {
+	if x == nil {
+		return nil
+	}
+	return x
+} //nil *big.Int는 값 없음(nil interface)으로
+
+func optString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+} //빈 문자열은 값 없음으로
+
+func optTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+} //zero time은 값 없음으로
+
+func optBytes(b []byte) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+	return b
+} //빈 바이트는 값 없음으로
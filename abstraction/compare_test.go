@@ -0,0 +1,226 @@
+package abstraction
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testTime = time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+func testMessage() *AbstractMessage {
+	return &AbstractMessage{
+		Type:        MsgTypeCommit,
+		Height:      big.NewInt(10),
+		Round:       big.NewInt(1),
+		View:        big.NewInt(2),
+		Timestamp:   testTime,
+		BlockHash:   "0xab",
+		Proposer:    "n1",
+		Signature:   "sig",
+		CommitSeals: []string{"s1", "s2"},
+		ViewChanges: []ViewChangeEntry{{View: big.NewInt(1), Height: big.NewInt(9), Validator: "n2", Signature: "vc"}},
+		Extras: map[string]ExtraValue{
+			"nonce":  IntExtra(big.NewInt(7)),
+			"raw":    BytesExtra([]byte{1, 2}),
+			"nested": MapExtra(map[string]ExtraValue{"list": ListExtra(StringExtra("a"), IntExtra(big.NewInt(1)))}),
+		},
+		RawPayload:         []byte{0xc0},
+		OriginalFormat:     "json",
+		OriginalFieldNames: map[string]string{"height": "Height"},
+		OriginalPaths:      map[string]string{"Height": "vote.height"},
+	}
+} //모든 필드와 중첩 Extras를 채운 메시지
+
+func TestClone(t *testing.T) {
+	m := testMessage()
+	m.MarkClean()
+	c := m.Clone()
+	if !reflect.DeepEqual(c, m) {
+		t.Fatalf("Clone = %+v, want %+v", c, m)
+	}
+	c.Height.SetInt64(11)
+	c.Round.SetInt64(5)
+	c.View.SetInt64(5)
+	c.CommitSeals[0] = "x"
+	c.ViewChanges[0].View.SetInt64(5)
+	c.ViewChanges[0].Height.SetInt64(5)
+	c.ViewChanges[0].Validator = "x"
+	c.Extras["nonce"].Int.SetInt64(8)
+	c.Extras["raw"].Bytes[0] = 9
+	c.Extras["nested"].Map["list"].List[0] = StringExtra("b")
+	c.Extras["nested"].Map["list"].List[1].Int.SetInt64(2)
+	c.Extras["added"] = NullExtra()
+	c.RawPayload[0] = 0xc1
+	c.OriginalFieldNames["height"] = "x"
+	c.OriginalPaths["Height"] = "x"
+	want := testMessage()
+	want.MarkClean()
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("changing the clone changed the original: %s", want.Diff(m, EqualOptions{}))
+	}
+	if !c.Tracked() {
+		t.Error("Clone dropped the MarkClean state")
+	}
+	if (*AbstractMessage)(nil).Clone() != nil {
+		t.Error("nil Clone is not nil")
+	}
+	if e := (&AbstractMessage{}).Clone(); e.Extras != nil || e.CommitSeals != nil || e.RawPayload != nil {
+		t.Errorf("Clone of an empty message allocated %+v", e)
+	}
+} //깊은 복사(big.Int, slice, Extras 중첩 값, 원본 메타데이터 map)
+
+func TestEqualOptions(t *testing.T) {
+	cases := []struct {
+		name   string
+		change func(m *AbstractMessage)
+		opts   EqualOptions
+		want   bool
+	}{
+		{"same", func(m *AbstractMessage) {}, EqualOptions{}, true},
+		{"raw payload", func(m *AbstractMessage) { m.RawPayload = []byte{0xc1} }, EqualOptions{}, false},
+		{"raw payload ignored", func(m *AbstractMessage) { m.RawPayload = nil }, EqualOptions{IgnoreRawPayload: true}, true},
+		{"extras", func(m *AbstractMessage) { m.Extras["nonce"] = IntExtra(big.NewInt(8)) }, EqualOptions{}, false},
+		{"extras ignored", func(m *AbstractMessage) { m.Extras = nil }, EqualOptions{IgnoreExtras: true}, true},
+		{"sub-second timestamp", func(m *AbstractMessage) { m.Timestamp = testTime.Add(500 * time.Millisecond) }, EqualOptions{}, false},
+		{"timestamp precision", func(m *AbstractMessage) { m.Timestamp = testTime.Add(500 * time.Millisecond) }, EqualOptions{TimestampPrecision: time.Second}, true},
+		{"timestamp beyond precision", func(m *AbstractMessage) { m.Timestamp = testTime.Add(time.Second) }, EqualOptions{TimestampPrecision: time.Second}, false},
+		{"timestamp zone", func(m *AbstractMessage) { m.Timestamp = testTime.In(time.FixedZone("KST", 9*3600)) }, EqualOptions{}, true},
+		{"nil vs zero", func(m *AbstractMessage) { m.Round = new(big.Int) }, EqualOptions{}, false},
+		{"nil equals zero", func(m *AbstractMessage) { m.Round = new(big.Int) }, EqualOptions{NilEqualsZero: true}, true},
+		{"nil equals zero, not one", func(m *AbstractMessage) { m.Round = big.NewInt(1) }, EqualOptions{NilEqualsZero: true}, false},
+		{"original metadata", func(m *AbstractMessage) {
+			m.OriginalFormat, m.OriginalMsgName, m.OriginalSchema = "rlp", "Seal", "x.Y"
+			m.OriginalFieldNames, m.OriginalPaths = nil, nil
+		}, EqualOptions{}, true},
+		{"extra encoding", func(m *AbstractMessage) {
+			e := m.Extras["nonce"]
+			e.Encoding = "msgpack"
+			m.Extras["nonce"] = e
+		}, EqualOptions{}, true},
+		{"extra int as float", func(m *AbstractMessage) { m.Extras["nonce"] = FloatExtra(7) }, EqualOptions{}, true},
+		{"extra bytes as base64", func(m *AbstractMessage) { m.Extras["raw"] = StringExtra("AQI=") }, EqualOptions{}, true},
+	}
+	for _, tc := range cases {
+		a, b := testMessage(), testMessage()
+		a.Round = nil
+		b.Round = nil
+		tc.change(b)
+		if got := a.Equal(b, tc.opts); got != tc.want {
+			t.Errorf("%s: Equal = %v, want %v (diff %s)", tc.name, got, tc.want, a.Diff(b, tc.opts))
+		}
+		if got := b.Equal(a, tc.opts); got != tc.want {
+			t.Errorf("%s: Equal is not symmetric", tc.name)
+		}
+	}
+} //EqualOptions별 비교(Original* 메타데이터와 Extras Encoding은 항상 무시)
+
+func TestDiff(t *testing.T) {
+	a, b := testMessage(), testMessage()
+	b.Type = MsgTypePrepare
+	b.Height = nil
+	b.Timestamp = testTime.Add(time.Second)
+	b.BlockHash = ""
+	b.CommitSeals = []string{"s1", "x", "s3"}
+	b.ViewChanges = append(b.ViewChanges, ViewChangeEntry{Validator: "n3"})
+	b.ViewChanges[0].Signature = "vc2"
+	delete(b.Extras, "nonce")
+	b.Extras["raw"] = StringExtra("other")
+	b.Extras["added"] = BoolExtra(true)
+	b.RawPayload = nil
+	d := a.Diff(b, EqualOptions{})
+	want := []struct {
+		path, field string
+		old, new    interface{}
+	}{
+		{"Type", "Type", "Commit", "Prepare"},
+		{"Height", "Height", big.NewInt(10), nil},
+		{"Timestamp", "Timestamp", testTime, testTime.Add(time.Second)},
+		{"BlockHash", "BlockHash", "0xab", nil},
+		{"CommitSeals[1]", "CommitSeals", "s2", "x"},
+		{"CommitSeals[2]", "CommitSeals", nil, "s3"},
+		{"ViewChanges[0].Signature", "ViewChanges", "vc", "vc2"},
+		{"ViewChanges[1]", "ViewChanges", nil, ViewChangeEntry{Validator: "n3"}},
+		{"Extras[added]", "Extras[added]", nil, BoolExtra(true)},
+		{"Extras[nonce]", "Extras[nonce]", IntExtra(big.NewInt(7)), nil},
+		{"Extras[raw]", "Extras[raw]", BytesExtra([]byte{1, 2}), StringExtra("other")},
+		{"RawPayload", "RawPayload", []byte{0xc0}, nil},
+	}
+	if len(d) != len(want) {
+		t.Fatalf("Diff has %d changes, want %d:\n%s", len(d), len(want), d)
+	}
+	for i, w := range want {
+		c := d[i]
+		if c.Path != w.path || c.Field() != w.field || !reflect.DeepEqual(c.Old, w.old) || !reflect.DeepEqual(c.New, w.new) {
+			t.Errorf("change %d = %s (%s) %#v -> %#v, want %s (%s) %#v -> %#v", i, c.Path, c.Field(), c.Old, c.New, w.path, w.field, w.old, w.new)
+		}
+	}
+	wantText := `Type: "Commit" -> "Prepare"
+Height: 10 -> <none>
+Timestamp: 2024-05-06T07:08:09Z -> 2024-05-06T07:08:10Z
+BlockHash: "0xab" -> <none>
+CommitSeals[1]: "s2" -> "x"
+CommitSeals[2]: <none> -> "s3"
+ViewChanges[0].Signature: "vc" -> "vc2"
+ViewChanges[1]: <none> -> {View:<none> Height:<none> Validator:"n3" Signature:""}
+Extras[added]: <none> -> true
+Extras[nonce]: 7 -> <none>
+Extras[raw]: AQI= -> "other"
+RawPayload: 0xc0 -> <none>
+`
+	if got := d.String(); got != wantText {
+		t.Errorf("Diff.String =\n%s\nwant\n%s", got, wantText)
+	}
+	if d := (*AbstractMessage)(nil).Diff(&AbstractMessage{Proposer: "n1"}, EqualOptions{}); len(d) != 1 || d[0].Path != "Proposer" || d[0].Old != nil {
+		t.Errorf("nil receiver Diff = %v", d)
+	}
+	if d := a.Diff(a.Clone(), EqualOptions{}); d != nil || d.String() != "" {
+		t.Errorf("Diff of equal messages = %#v", d)
+	}
+} //변경 경로, 이전/이후 값, 최상위 필드명, 텍스트 출력
+
+func TestDiffMarshalJSON(t *testing.T) {
+	var none Diff
+	if b, err := json.Marshal(none); err != nil || string(b) != "[]" {
+		t.Errorf("empty Diff JSON = %s, %v", b, err)
+	}
+	a, b := testMessage(), testMessage()
+	b.Height = big.NewInt(11)
+	b.Extras["nested"] = MapExtra(map[string]ExtraValue{"z": FloatExtra(0.5), "a": NullExtra()})
+	b.Extras["nonce"] = NullExtra()
+	got, err := json.Marshal(a.Diff(b, EqualOptions{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"path":"Height","old":10,"new":11},` +
+		`{"path":"Extras[nested]","old":{"list":["a",1]},"new":{"a":null,"z":0.5}},` +
+		`{"path":"Extras[nonce]","old":7,"new":null}]`
+	if string(got) != want {
+		t.Errorf("Diff JSON = %s, want %s", got, want)
+	}
+} //Diff JSON 출력(값은 자연스러운 JSON, 없을 시 [])
+
+func TestMessageMarshalJSON(t *testing.T) {
+	m := testMessage()
+	m.Extras = map[string]ExtraValue{"big": IntExtra(new(big.Int).Lsh(big.NewInt(1), 70)), "raw": BytesExtra([]byte{1, 2})}
+	got, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"Commit","height":10,"round":1,"view":2,"timestamp":"2024-05-06T07:08:09Z","block_hash":"0xab",` +
+		`"proposer":"n1","signature":"sig","commit_seals":["s1","s2"],` +
+		`"view_changes":[{"view":1,"height":9,"validator":"n2","signature":"vc"}],` +
+		`"extras":{"big":1180591620717411303424,"raw":"AQI="},"raw_payload":"wA=="}`
+	if string(got) != want {
+		t.Errorf("Marshal =\n%s\nwant\n%s", got, want)
+	}
+	var back AbstractMessage
+	if err := json.Unmarshal(got, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !back.Equal(m, EqualOptions{}) || back.OriginalFormat != "" || back.Extras["big"].Kind != ExtraInt {
+		t.Errorf("Unmarshal differs: %s", m.Diff(&back, EqualOptions{}))
+	}
+} //JSON 출력(Original* 제외, 큰 정수 Extras는 정밀도 유지)
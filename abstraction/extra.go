@@ -0,0 +1,264 @@
+package abstraction
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+)
+
+type ExtraKind string
+
+const (
+	ExtraNull   ExtraKind = "null"
+	ExtraString ExtraKind = "string"
+	ExtraInt    ExtraKind = "int"   //임의 정밀도 정수
+	ExtraFloat  ExtraKind = "float" //정수가 아닌 수
+	ExtraBool   ExtraKind = "bool"
+	ExtraBytes  ExtraKind = "bytes" //원시 바이트(msgpack bin, protobuf bytes 등)
+	ExtraList   ExtraKind = "list"
+	ExtraMap    ExtraKind = "map"
+) //Extras 값의 타입
+
+type ExtraValue struct {
+	Kind     ExtraKind
+	Str      string                //ExtraString
+	Int      *big.Int              //ExtraInt
+	Float    float64               //ExtraFloat
+	Bool     bool                  //ExtraBool
+	Bytes    []byte                //ExtraBytes
+	List     []ExtraValue          //ExtraList
+	Map      map[string]ExtraValue //ExtraMap
+	Encoding string                //값을 읽어 온 원본 포맷(json, generic, msgpack, protobuf 등, 비교 시 무시)
+} //표준화되지 않은 필드의 타입 있는 값
+
+func StringExtra(s string) ExtraValue {
+	return ExtraValue{Kind: ExtraString, Str: s}
+} //문자열 값
+
+func IntExtra(x *big.Int) ExtraValue {
+	if x == nil {
+		return NullExtra()
+	}
+	return ExtraValue{Kind: ExtraInt, Int: new(big.Int).Set(x)}
+} //정수 값(nil일 시 null)
+
+func FloatExtra(f float64) ExtraValue {
+	return ExtraValue{Kind: ExtraFloat, Float: f}
+} //실수 값
+
+func BoolExtra(b bool) ExtraValue {
+	return ExtraValue{Kind: ExtraBool, Bool: b}
+} //bool 값
+
+func BytesExtra(b []byte) ExtraValue {
+	return ExtraValue{Kind: ExtraBytes, Bytes: append([]byte(nil), b...)}
+} //바이트 값
+
+func ListExtra(items ...ExtraValue) ExtraValue {
+	return ExtraValue{Kind: ExtraList, List: items}
+} //리스트 값
+
+func MapExtra(m map[string]ExtraValue) ExtraValue {
+	return ExtraValue{Kind: ExtraMap, Map: m}
+} //map 값
+
+func NullExtra() ExtraValue {
+	return ExtraValue{Kind: ExtraNull}
+} //null 값
+
+func (v ExtraValue) Text() string {
+	switch v.Kind {
+	case ExtraString:
+		return v.Str
+	case ExtraInt:
+		return v.Int.String()
+	case ExtraFloat:
+		return strconv.FormatFloat(v.Float, 'g', -1, 64)
+	case ExtraBool:
+		return strconv.FormatBool(v.Bool)
+	case ExtraBytes: //JSON의 []byte 관례와 같은 base64
+		return base64.StdEncoding.EncodeToString(v.Bytes)
+	case ExtraNull, "":
+		return "null"
+	}
+	b, _ := json.Marshal(v)
+	return string(b) //list/map은 JSON 표기
+} //값의 텍스트 표기
+
+func (v ExtraValue) Clone() ExtraValue {
+	out := v
+	if v.Int != nil {
+		out.Int = new(big.Int).Set(v.Int)
+	}
+	if v.Bytes != nil {
+		out.Bytes = append([]byte(nil), v.Bytes...)
+	}
+	if v.List != nil {
+		out.List = make([]ExtraValue, len(v.List))
+		for i, e := range v.List {
+			out.List[i] = e.Clone()
+		}
+	}
+	if v.Map != nil {
+		out.Map = make(map[string]ExtraValue, len(v.Map))
+		for k, e := range v.Map {
+			out.Map[k] = e.Clone()
+		}
+	}
+	return out
+} //깊은 복사
+
+func (v ExtraValue) Equal(o ExtraValue) bool {
+	if v.Kind != o.Kind {
+		return crossKindEqual(v, o) || crossKindEqual(o, v)
+	}
+	switch v.Kind {
+	case ExtraString:
+		return v.Str == o.Str
+	case ExtraInt:
+		return v.Int.Cmp(o.Int) == 0
+	case ExtraFloat:
+		return v.Float == o.Float || (math.IsNaN(v.Float) && math.IsNaN(o.Float)) //NaN끼리도 같은 값
+	case ExtraBool:
+		return v.Bool == o.Bool
+	case ExtraBytes:
+		return bytes.Equal(v.Bytes, o.Bytes)
+	case ExtraList:
+		if len(v.List) != len(o.List) {
+			return false
+		}
+		for i := range v.List {
+			if !v.List[i].Equal(o.List[i]) {
+				return false
+			}
+		}
+		return true
+	case ExtraMap:
+		if len(v.Map) != len(o.Map) {
+			return false
+		}
+		for k, e := range v.Map {
+			oe, ok := o.Map[k]
+			if !ok || !e.Equal(oe) {
+				return false
+			}
+		}
+		return true
+	}
+	return true //null
+} //값 비교(Encoding 무시)
+
+func crossKindEqual(a, b ExtraValue) bool {
+	switch {
+	case a.Kind == ExtraBytes && b.Kind == ExtraString: //바이트 타입이 없는 포맷을 거친 값
+		return b.Str == base64.StdEncoding.EncodeToString(a.Bytes) || b.Str == "0x"+hex.EncodeToString(a.Bytes)
+	case a.Kind == ExtraInt && b.Kind == ExtraString: //64비트 정수만 있는 포맷(msgpack)은 큰 정수를 10진수 문자열로 전달
+		return !a.Int.IsInt64() && !a.Int.IsUint64() && b.Str == a.Int.String()
+	case a.Kind == ExtraInt && b.Kind == ExtraFloat: //1과 1.0
+		if math.IsNaN(b.Float) || math.IsInf(b.Float, 0) {
+			return false
+		}
+		y, acc := new(big.Float).SetFloat64(b.Float).Int(nil)
+		return acc == big.Exact && a.Int.Cmp(y) == 0
+	}
+	return false
+} //포맷 간 표현 차이로 Kind가 달라진 같은 값인지 확인
+
+func (v ExtraValue) MarshalJSON() ([]byte, error) {
+	switch v.Kind {
+	case ExtraString:
+		return json.Marshal(v.Str)
+	case ExtraInt:
+		return []byte(v.Int.String()), nil
+	case ExtraFloat:
+		if math.IsInf(v.Float, 0) || math.IsNaN(v.Float) { //JSON 수로 표현 불가
+			return json.Marshal(v.Text())
+		}
+		return json.Marshal(v.Float)
+	case ExtraBool:
+		return json.Marshal(v.Bool)
+	case ExtraBytes:
+		return json.Marshal(v.Bytes) //base64 문자열
+	case ExtraList:
+		if v.List == nil {
+			return []byte("[]"), nil
+		}
+		return json.Marshal(v.List)
+	case ExtraMap:
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		keys := make([]string, 0, len(v.Map))
+		for k := range v.Map {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys) //출력 순서 고정
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			kb, _ := json.Marshal(k)
+			vb, err := v.Map[k].MarshalJSON()
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(kb)
+			buf.WriteByte(':')
+			buf.Write(vb)
+		}
+		buf.WriteByte('}')
+		return buf.Bytes(), nil
+	}
+	return []byte("null"), nil
+} //자연스러운 JSON 값으로 출력(bytes는 base64 문자열)
+
+func (v *ExtraValue) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var raw interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	*v = ExtraFromJSON(raw)
+	return nil
+} //JSON 값을 타입 있는 값으로 읽음(정수는 임의 정밀도)
+
+func ExtraFromJSON(raw interface{}) ExtraValue {
+	switch t := raw.(type) {
+	case nil:
+		return NullExtra()
+	case string:
+		return StringExtra(t)
+	case bool:
+		return BoolExtra(t)
+	case json.Number:
+		if x, ok := new(big.Int).SetString(t.String(), 10); ok {
+			return IntExtra(x)
+		}
+		f, _ := t.Float64()
+		return FloatExtra(f)
+	case float64:
+		if !math.IsNaN(t) && !math.IsInf(t, 0) && new(big.Float).SetFloat64(t).IsInt() {
+			x, _ := new(big.Float).SetFloat64(t).Int(nil)
+			return IntExtra(x)
+		}
+		return FloatExtra(t)
+	case []interface{}:
+		items := make([]ExtraValue, 0, len(t))
+		for _, e := range t {
+			items = append(items, ExtraFromJSON(e))
+		}
+		return ListExtra(items...)
+	case map[string]interface{}:
+		m := make(map[string]ExtraValue, len(t))
+		for k, e := range t {
+			m[k] = ExtraFromJSON(e)
+		}
+		return MapExtra(m)
+	}
+	return StringExtra("")
+} //encoding/json으로 디코딩한 값을 ExtraValue로 변환
@@ -0,0 +1,145 @@
+package abstraction
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestExtraValueKinds(t *testing.T) {
+	big70 := new(big.Int).Lsh(big.NewInt(1), 70)
+	cases := []struct {
+		name     string
+		v        ExtraValue
+		kind     ExtraKind
+		text     string
+		json     string
+		backKind ExtraKind //JSON을 다시 읽은 Kind
+	}{
+		{"string", StringExtra("a\"b"), ExtraString, `a"b`, `"a\"b"`, ExtraString},
+		{"int", IntExtra(big.NewInt(-3)), ExtraInt, "-3", "-3", ExtraInt},
+		{"big int", IntExtra(big70), ExtraInt, "1180591620717411303424", "1180591620717411303424", ExtraInt},
+		{"nil int", IntExtra(nil), ExtraNull, "null", "null", ExtraNull},
+		{"float", FloatExtra(1.5), ExtraFloat, "1.5", "1.5", ExtraFloat},
+		{"integral float", FloatExtra(2), ExtraFloat, "2", "2", ExtraInt},
+		{"NaN", FloatExtra(math.NaN()), ExtraFloat, "NaN", `"NaN"`, ExtraString},
+		{"inf", FloatExtra(math.Inf(-1)), ExtraFloat, "-Inf", `"-Inf"`, ExtraString},
+		{"bool", BoolExtra(true), ExtraBool, "true", "true", ExtraBool},
+		{"bytes", BytesExtra([]byte{1, 2}), ExtraBytes, "AQI=", `"AQI="`, ExtraString},
+		{"nil list", ListExtra(), ExtraList, "[]", "[]", ExtraList},
+		{"list", ListExtra(StringExtra("a"), NullExtra()), ExtraList, `["a",null]`, `["a",null]`, ExtraList},
+		{"map", MapExtra(map[string]ExtraValue{"b": IntExtra(big.NewInt(1)), "a": StringExtra("x")}), ExtraMap, `{"a":"x","b":1}`, `{"a":"x","b":1}`, ExtraMap},
+		{"null", NullExtra(), ExtraNull, "null", "null", ExtraNull},
+		{"zero value", ExtraValue{}, "", "null", "null", ExtraNull},
+	}
+	for _, tc := range cases {
+		if tc.v.Kind != tc.kind {
+			t.Errorf("%s: Kind = %q, want %q", tc.name, tc.v.Kind, tc.kind)
+		}
+		if got := tc.v.Text(); got != tc.text {
+			t.Errorf("%s: Text = %q, want %q", tc.name, got, tc.text)
+		}
+		b, err := json.Marshal(tc.v)
+		if err != nil || string(b) != tc.json {
+			t.Errorf("%s: MarshalJSON = %s, %v, want %s", tc.name, b, err, tc.json)
+			continue
+		}
+		var back ExtraValue
+		if err := json.Unmarshal(b, &back); err != nil || back.Kind != tc.backKind {
+			t.Errorf("%s: UnmarshalJSON(%s) = %+v, %v, want kind %q", tc.name, b, back, err, tc.backKind)
+		}
+		if back.Kind == tc.kind && !back.Equal(tc.v) { //Kind가 유지되면 값도 같음
+			t.Errorf("%s: UnmarshalJSON(%s) = %+v, want %+v", tc.name, b, back, tc.v)
+		}
+	}
+	x := big.NewInt(5)
+	v := IntExtra(x)
+	x.SetInt64(6)
+	b := []byte{1}
+	bv := BytesExtra(b)
+	b[0] = 2
+	if v.Int.Int64() != 5 || bv.Bytes[0] != 1 {
+		t.Error("IntExtra/BytesExtra share the caller's value")
+	}
+} //생성자별 Kind, Text, JSON 출력과 다시 읽은 Kind
+
+func TestExtraFromJSON(t *testing.T) {
+	cases := []struct {
+		in   interface{}
+		want ExtraValue
+	}{
+		{nil, NullExtra()},
+		{"s", StringExtra("s")},
+		{false, BoolExtra(false)},
+		{json.Number("18446744073709551616"), IntExtra(new(big.Int).Lsh(big.NewInt(1), 64))},
+		{json.Number("1e3"), FloatExtra(1000)},
+		{json.Number("0.25"), FloatExtra(0.25)},
+		{float64(3), IntExtra(big.NewInt(3))},
+		{3.5, FloatExtra(3.5)},
+		{math.Inf(1), FloatExtra(math.Inf(1))},
+		{[]interface{}{"a", float64(1)}, ListExtra(StringExtra("a"), IntExtra(big.NewInt(1)))},
+		{[]interface{}{}, ListExtra()},
+		{map[string]interface{}{"k": nil}, MapExtra(map[string]ExtraValue{"k": NullExtra()})},
+		{42, StringExtra("")}, //encoding/json이 만들지 않는 타입
+	}
+	for _, tc := range cases {
+		got := ExtraFromJSON(tc.in)
+		if got.Kind != tc.want.Kind || !got.Equal(tc.want) {
+			t.Errorf("ExtraFromJSON(%#v) = %+v, want %+v", tc.in, got, tc.want)
+		}
+	}
+} //encoding/json 값의 변환(정수로 표현되는 수는 ExtraInt)
+
+func TestExtraEqual(t *testing.T) {
+	big70 := IntExtra(new(big.Int).Lsh(big.NewInt(1), 70))
+	cases := []struct {
+		name string
+		a, b ExtraValue
+		want bool
+	}{
+		{"NaN", FloatExtra(math.NaN()), FloatExtra(math.NaN()), true},
+		{"bytes as base64", BytesExtra([]byte{1, 2}), StringExtra("AQI="), true},
+		{"bytes as hex", BytesExtra([]byte{1, 2}), StringExtra("0x0102"), true},
+		{"bytes as other text", BytesExtra([]byte{1, 2}), StringExtra("0102"), false},
+		{"big int as decimal text", big70, StringExtra("1180591620717411303424"), true},
+		{"small int as text", IntExtra(big.NewInt(5)), StringExtra("5"), false}, //64비트 포맷에서 문자열이 될 수 없는 값
+		{"int as float", IntExtra(big.NewInt(1)), FloatExtra(1), true},
+		{"int as fraction", IntExtra(big.NewInt(1)), FloatExtra(1.5), false},
+		{"int as inf", IntExtra(big.NewInt(1)), FloatExtra(math.Inf(1)), false},
+		{"string vs bool", StringExtra("true"), BoolExtra(true), false},
+		{"list length", ListExtra(NullExtra()), ListExtra(), false},
+		{"list items", ListExtra(IntExtra(big.NewInt(1))), ListExtra(FloatExtra(1)), true},
+		{"map key", MapExtra(map[string]ExtraValue{"a": NullExtra()}), MapExtra(map[string]ExtraValue{"b": NullExtra()}), false},
+		{"map value", MapExtra(map[string]ExtraValue{"a": BoolExtra(true)}), MapExtra(map[string]ExtraValue{"a": BoolExtra(false)}), false},
+		{"encoding", ExtraValue{Kind: ExtraString, Str: "x", Encoding: "msgpack"}, StringExtra("x"), true},
+		{"null", NullExtra(), NullExtra(), true},
+	}
+	for _, tc := range cases {
+		if got := tc.a.Equal(tc.b); got != tc.want {
+			t.Errorf("%s: Equal = %v, want %v", tc.name, got, tc.want)
+		}
+		if got := tc.b.Equal(tc.a); got != tc.want {
+			t.Errorf("%s: reversed Equal = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+} //같은 Kind 비교와 포맷 간 표현 차이(bytes/base64, 큰 정수/문자열, 정수/실수)
+
+func TestExtraClone(t *testing.T) {
+	v := MapExtra(map[string]ExtraValue{
+		"list":  ListExtra(IntExtra(big.NewInt(1)), BytesExtra([]byte{1})),
+		"inner": MapExtra(map[string]ExtraValue{"s": StringExtra("a")}),
+	})
+	c := v.Clone()
+	if !reflect.DeepEqual(c, v) {
+		t.Fatalf("Clone = %+v", c)
+	}
+	c.Map["list"].List[0].Int.SetInt64(2)
+	c.Map["list"].List[1].Bytes[0] = 2
+	c.Map["inner"].Map["s"] = StringExtra("b")
+	c.Map["new"] = NullExtra()
+	if v.Map["list"].List[0].Int.Int64() != 1 || v.Map["list"].List[1].Bytes[0] != 1 || v.Map["inner"].Map["s"].Str != "a" || len(v.Map) != 2 {
+		t.Errorf("changing the clone changed the original: %+v", v)
+	}
+} //중첩 list/map/bytes/int 깊은 복사
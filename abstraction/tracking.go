@@ -0,0 +1,42 @@
+package abstraction
+
+func (m *AbstractMessage) MarkClean() {
+	m.clean = m.snapshot()
+} //현재 필드 값을 기준 상태로 기록(parsing 직후 codec이 호출)
+
+func (m *AbstractMessage) Tracked() bool {
+	return m.clean != nil
+} //MarkClean으로 기준 상태가 기록되어 있는지 확인
+
+func (m *AbstractMessage) ChangedFields() []string {
+	if m.clean == nil {
+		return nil
+	}
+	var out []string
+	seen := map[string]bool{}
+	for _, c := range m.clean.Diff(m, EqualOptions{IgnoreRawPayload: true}) {
+		if f := c.Field(); !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	return out
+} //기준 상태 이후 값이 바뀐 필드명 목록(Extras는 "Extras[key]"), 기록이 없을 시 nil
+
+func ExtraField(key string) string {
+	return "Extras[" + key + "]"
+} //ChangedFields에서 Extras key를 나타내는 이름
+
+func ExtraKey(field string) (string, bool) {
+	const prefix = "Extras["
+	if len(field) > len(prefix) && field[:len(prefix)] == prefix && field[len(field)-1] == ']' {
+		return field[len(prefix) : len(field)-1], true
+	}
+	return "", false
+} //"Extras[key]"에서 key 추출
+
+func (m *AbstractMessage) snapshot() *AbstractMessage {
+	c := *m
+	c.RawPayload, c.clean = nil, nil //RawPayload는 복사하지 않음
+	return c.Clone()
+} //비교용 깊은 복사(표준 필드와 Extras만 사용)
@@ -0,0 +1,57 @@
+package abstraction
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestChangedFields(t *testing.T) {
+	m := testMessage()
+	if m.Tracked() || m.ChangedFields() != nil {
+		t.Fatal("message is tracked before MarkClean")
+	}
+	m.MarkClean()
+	if !m.Tracked() || m.ChangedFields() != nil {
+		t.Fatalf("after MarkClean: Tracked %v, ChangedFields %v", m.Tracked(), m.ChangedFields())
+	}
+	m.Height.SetInt64(11)  //제자리 변경도 기준 상태와 비교
+	m.CommitSeals[1] = "x" //같은 필드의 여러 경로는 한 번만
+	m.CommitSeals = append(m.CommitSeals, "y")
+	m.ViewChanges[0].Signature = "vc2"
+	m.Extras["nested"].Map["list"].List[0] = StringExtra("b")
+	m.Extras["added"] = NullExtra()
+	m.RawPayload = []byte{0xff}             //원본 바이트는 변경으로 보지 않음
+	m.OriginalFieldNames["round"] = "Round" //메타데이터도 마찬가지
+	want := []string{"Height", "CommitSeals", "ViewChanges", "Extras[added]", "Extras[nested]"}
+	if got := m.ChangedFields(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedFields = %v, want %v", got, want)
+	}
+	c := m.Clone() //기준 상태 공유, 이후 변경은 각자
+	c.Proposer = "n9"
+	if got := m.ChangedFields(); len(got) != len(want) {
+		t.Errorf("changing the clone changed the original's ChangedFields: %v", got)
+	}
+	wantClone := []string{"Height", "Proposer", "CommitSeals", "ViewChanges", "Extras[added]", "Extras[nested]"}
+	if got := c.ChangedFields(); !reflect.DeepEqual(got, wantClone) {
+		t.Errorf("clone ChangedFields = %v, want %v", got, wantClone)
+	}
+	m.Height = big.NewInt(10)
+	m.MarkClean() //다시 기록하면 현재 값이 기준
+	if got := m.ChangedFields(); got != nil {
+		t.Errorf("ChangedFields after a second MarkClean = %v", got)
+	}
+} //MarkClean 이후 바뀐 최상위 필드명(선언 순서, Extras는 key별)
+
+func TestExtraKey(t *testing.T) {
+	for _, key := range []string{"nonce", "a.b", "[x]", ""} {
+		if got, ok := ExtraKey(ExtraField(key)); !ok || got != key {
+			t.Errorf("ExtraKey(ExtraField(%q)) = %q, %v", key, got, ok)
+		}
+	}
+	for _, field := range []string{"Height", "Extras", "Extras[", "Extras[a]b", "extras[a]"} {
+		if key, ok := ExtraKey(field); ok {
+			t.Errorf("ExtraKey(%q) = %q, want not an Extras field", field, key)
+		}
+	}
+} //ExtraField와 ExtraKey는 서로 역변환
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"codec/codec"
+	"codec/framing"
+)
+
+func main() {
+	from := flag.String("from", "auto", "입력 payload 포맷(auto, generic, json, protobuf, rlp, msgpack, bcs)")
+	to := flag.String("to", "json", "출력 payload 포맷")
+	inFraming := flag.String("in-framing", "", "입력 envelope(len32, uvarint, newline, devp2p+<inner>), 빈 값일 시 입력 포맷 고유 framing")
+	outFraming := flag.String("out-framing", "", "출력 envelope, 빈 값일 시 출력 포맷 고유 framing")
+	inPath := flag.String("in", "-", "입력 파일(-는 stdin)")
+	outPath := flag.String("out", "-", "출력 파일(-는 stdout)")
+	protoDesc := flag.String("proto-desc", "", "protobuf descriptor set(.protoset) 파일")
+	protoMsg := flag.String("proto-msg", "", "protobuf 메시지 full name(예: pbft.AbstractMessage)")
+	vocab := flag.String("vocab", "", "출력 구현체 어휘(tendermint, fabric, ibft 등)")
+	inComp := flag.String("in-compression", "", "입력 payload 압축(snappy, snappy-block, zstd, none), 빈 값일 시 magic으로 감지")
+	outComp := flag.String("out-compression", "", "출력 payload 압축(snappy, snappy-block, zstd), 빈 값일 시 압축 안 함")
+	maxSize := flag.Int("max", codec.DefaultMaxMessageSize, "메시지 하나의 최대 바이트 수")
+	keepGoing := flag.Bool("keep-going", false, "parsing/직렬화에 실패한 메시지를 건너뛰고 계속")
+	flag.Parse()
+
+	if *protoDesc != "" { //protobuf descriptor 등록
+		if err := codec.RegisterDescriptorSetFile(*protoDesc); err != nil {
+			log.Fatalf("register %s: %v", *protoDesc, err)
+		}
+	}
+	in, out := openInput(*inPath), openOutput(*outPath)
+	defer in.Close()
+	w := bufio.NewWriter(out)
+	defer func() {
+		if err := w.Flush(); err != nil {
+			log.Fatalf("write: %v", err)
+		}
+		out.Close()
+	}()
+
+	limits := codec.DefaultLimits //peer 캡처 등 신뢰할 수 없는 입력 기준
+	limits.MaxBytes = *maxSize
+	dec := codec.NewDecoder(in, codec.ParseOptions{
+		Format:               codec.Format(*from),
+		ProtoMessageFullName: *protoMsg,
+		Compression:          codec.Compression(*inComp),
+		Limits:               limits,
+		TrackChanges:         *from == *to, //같은 포맷으로 다시 쓸 때만 원본 바이트 재사용
+	})
+	dec.SetMaxMessageSize(*maxSize)
+	if *inFraming != "" {
+		f, err := framing.Lookup(*inFraming)
+		if err != nil {
+			log.Fatal(err)
+		}
+		dec.SetFramer(f)
+	}
+	enc := codec.NewEncoder(w, codec.SerializeOptions{Format: codec.Format(*to), ProtoMessageFullName: *protoMsg, ProtoDiscardUnknown: true, Vocabulary: *vocab, Compression: codec.Compression(*outComp)})
+	if *outFraming != "" {
+		f, err := framing.Lookup(*outFraming)
+		if err != nil {
+			log.Fatal(err)
+		}
+		enc.SetFramer(f)
+	}
+
+	n, failed := 0, 0
+	for {
+		am, err := dec.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var pe *codec.ParseError
+		if err != nil && !(*keepGoing && errors.As(err, &pe) && dec.Frame().Payload != nil) { //경계를 잃은 framing 에러는 계속할 수 없음
+			log.Fatalf("message %d at offset %d: %v", n, dec.InputOffset(), err)
+		}
+		if err == nil {
+			err = enc.EncodeFrame(am, dec.Frame()) //입력 frame의 코드/peer 유지
+		}
+		if err != nil {
+			if !*keepGoing {
+				log.Fatalf("message %d: %v", n, err)
+			}
+			log.Printf("skip message %d: %v", n, err)
+			failed++
+		}
+		n++
+	}
+	log.Printf("converted %d messages (%d skipped)", n-failed, failed)
+} //스트림의 메시지를 다른 포맷/envelope로 변환
+
+func openInput(path string) io.ReadCloser {
+	if path == "-" {
+		return os.Stdin
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return f
+} //입력 파일 열기(- 는 stdin)
+
+func openOutput(path string) io.WriteCloser {
+	if path == "-" {
+		return os.Stdout
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return f
+} //출력 파일 생성(- 는 stdout)
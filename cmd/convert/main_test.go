@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"codec/codec"
+	"codec/framing"
+)
+
+func TestMain(m *testing.M) {
+	if os.Getenv("CONVERT_TEST_MAIN") == "1" { //테스트 바이너리를 convert 명령으로 실행
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+} //CONVERT_TEST_MAIN=1일 시 테스트 대신 main 실행
+
+func convert(t *testing.T, stdin []byte, args ...string) ([]byte, string, error) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "CONVERT_TEST_MAIN=1")
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	return stdout.Bytes(), stderr.String(), err
+} //convert를 별도 프로세스로 실행(log.Fatal이 테스트를 끝내지 않도록)
+
+func TestConvert(t *testing.T) {
+	in := filepath.Join(t.TempDir(), "in.jsonl")
+	lines := "{\"type\":\"Prepare\",\"height\":7,\"proposer\":\"n1\"}\n\n{\"type\":\"Commit\",\"height\":8,\"block_hash\":\"0xab\"}\n"
+	if err := os.WriteFile(in, []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, stderr, err := convert(t, nil, "-in", in, "-from", "json", "-in-framing", "newline", "-to", "rlp", "-out-framing", "devp2p+len32")
+	if err != nil {
+		t.Fatalf("convert: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stderr, "converted 2 messages (0 skipped)") {
+		t.Errorf("stderr = %q", stderr)
+	}
+	r := bufio.NewReader(bytes.NewReader(out))
+	for _, want := range []struct {
+		typ    string
+		height int64
+	}{{"Prepare", 7}, {"Commit", 8}} {
+		f, err := framing.Devp2p(framing.LengthPrefix32()).ReadFrame(r, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		am, err := codec.Parse(f.Payload, codec.ParseOptions{Format: codec.FormatRLP})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(am.Type) != want.typ || am.Height == nil || am.Height.Int64() != want.height || !f.HasCode {
+			t.Errorf("converted message = %s height %v (code %v), want %s height %d", am.Type, am.Height, f.HasCode, want.typ, want.height)
+		}
+	}
+	if _, err := framing.LengthPrefix32().ReadFrame(r, 0); err != io.EOF {
+		t.Errorf("trailing output: %v", err)
+	}
+} //newline JSON 파일을 devp2p+len32 RLP로 변환
+
+func TestConvertErrors(t *testing.T) {
+	bad := []byte("{\"type\":\"Commit\",\"height\":1}\n[1,2]\n{\"type\":\"Commit\",\"height\":2}\n")
+	if _, stderr, err := convert(t, bad, "-from", "json", "-to", "generic"); err == nil || !strings.Contains(stderr, "message 1") {
+		t.Errorf("malformed message without -keep-going = %v, %q", err, stderr)
+	}
+	out, stderr, err := convert(t, bad, "-from", "json", "-to", "generic", "-keep-going")
+	if err != nil || !strings.Contains(stderr, "converted 2 messages (1 skipped)") || bytes.Count(out, []byte("\n")) != 2 {
+		t.Errorf("-keep-going = %v, %q, output %q", err, stderr, out)
+	}
+	if _, stderr, err := convert(t, []byte{0, 0, 0, 9, 1}, "-from", "rlp", "-in-framing", "len32", "-keep-going"); err == nil || !strings.Contains(stderr, "truncated") {
+		t.Errorf("truncated frame with -keep-going = %v, %q (framing errors cannot be skipped)", err, stderr)
+	}
+	if _, stderr, err := convert(t, nil, "-in-framing", "devp2p"); err == nil || !strings.Contains(stderr, "inner framer") {
+		t.Errorf("bad -in-framing = %v, %q", err, stderr)
+	}
+	if _, stderr, err := convert(t, []byte("{\"type\":\"Commit\"}\n"), "-from", "json", "-max", "4"); err == nil || !strings.Contains(stderr, "too large") {
+		t.Errorf("message over -max = %v, %q", err, stderr)
+	}
+} //parsing 실패(-keep-going 유무), framing 에러, 잘못된 framing 이름, 크기 제한
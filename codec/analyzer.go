@@ -0,0 +1,241 @@
+package codec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type FieldCollision struct {
+	Field  string   //표준 필드명
+	Winner string   //채택된 원본 key(모든 값이 빈 값일 시 빈 문자열)
+	Losers []string //Extras로 보존된 원본 key(우선순위 순)
+} //한 메시지 안에서 같은 표준 필드로 매핑되는 key가 여러 개인 경우
+
+func AnalyzeKeys(keys []string, opts ParseOptions) ([]FieldCollision, error) {
+	return resolveKeys(keys, -1, make([]string, len(keys)), nil, opts)
+} //메시지의 key 목록에서 유의어 충돌을 찾아 우선순위로 해소한 결과 반환
+
+func resolveFields(keys []string, empty func(string) bool, opts ParseOptions) (map[string]string, []FieldCollision, error) {
+	var emptyAt func(int) bool
+	if empty != nil {
+		emptyAt = func(i int) bool { return empty(keys[i]) }
+	}
+	canon := make([]string, len(keys))
+	collisions, err := resolveKeys(keys, -1, canon, emptyAt, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	assign := make(map[string]string, len(keys))
+	for i, f := range canon {
+		if f != "" {
+			assign[keys[i]] = f
+		}
+	}
+	return assign, collisions, nil
+} //원본 key -> 표준 필드명 매핑(필드당 우선순위가 가장 높은 key 하나만)과 충돌 목록(empty가 nil이 아닐 시 값이 빈 key는 가장 뒤, 빈 값만 있으면 채택 안 함)
+
+func resolveKeys(keys []string, skip int, canon []string, empty func(int) bool, opts ParseOptions) ([]FieldCollision, error) {
+	idx := opts.synonyms().index()
+	dup := false
+	for i, k := range keys {
+		canon[i] = ""
+		if i == skip {
+			continue
+		}
+		f, ok, err := opts.resolveIn(idx, "field", k)
+		if err != nil {
+			return nil, &ParseError{Format: opts.Format, Field: k, Offset: -1, Err: err}
+		}
+		if !ok {
+			continue
+		}
+		for _, prev := range canon[:i] {
+			dup = dup || prev == f
+		}
+		canon[i] = f
+	}
+	var collisions []FieldCollision
+	for i, f := range canon {
+		if f == "" {
+			continue
+		}
+		var group []int //같은 표준 필드로 매핑되는 key 위치(충돌이 있을 때만)
+		for j := i + 1; dup && j < len(canon); j++ {
+			if canon[j] == f {
+				group = append(group, j)
+			}
+		}
+		if group == nil {
+			if empty != nil && empty(i) {
+				canon[i] = ""
+			}
+			continue
+		}
+		group = append(group, i)
+		syn := opts.synonyms()
+		raws := make([]string, 0, len(group))
+		exact := map[string]bool{} //사전과 정확히 일치한 원본 key
+		emptyKey := map[string]bool{}
+		for _, j := range group {
+			raws = append(raws, keys[j])
+			if _, hit, _ := idx.lookup("field", keys[j], true); hit {
+				exact[keys[j]] = true
+			}
+			if empty != nil && empty(j) {
+				emptyKey[keys[j]] = true
+			}
+			canon[j] = ""
+		}
+		var emptyRaw func(string) bool
+		if empty != nil {
+			emptyRaw = func(k string) bool { return emptyKey[k] }
+		}
+		prio := syn.Priority(f)
+		sort.Slice(raws, func(a, b int) bool {
+			return fieldRankLess(f, raws[a], raws[b], prio, exact, emptyRaw)
+		})
+		if emptyRaw != nil && emptyRaw(raws[0]) { //모두 빈 값: 아무 key도 채택하지 않음
+			collisions = append(collisions, FieldCollision{Field: f, Losers: raws})
+			continue
+		}
+		collisions = append(collisions, FieldCollision{Field: f, Winner: raws[0], Losers: raws[1:]})
+		for _, j := range group {
+			if keys[j] == raws[0] {
+				canon[j] = f
+			}
+		}
+	}
+	if len(collisions) > 1 {
+		sort.Slice(collisions, func(a, b int) bool { return collisions[a].Field < collisions[b].Field })
+	}
+	return collisions, nil
+} //canon[i]에 keys[i]로 채택된 표준 필드명(필드당 우선순위가 가장 높은 key 하나만, 채택 안 되거나 skip 위치는 빈 문자열)과 충돌 목록
+
+func fieldRankLess(canon, a, b string, prio []string, exact map[string]bool, empty func(string) bool) bool {
+	if empty != nil && empty(a) != empty(b) { //0) 값이 빈(null, "") key는 다른 유의어의 값을 밀어내지 않음
+		return empty(b)
+	}
+	ia, ib := indexOf(prio, a), indexOf(prio, b)
+	if ia != ib { //1) 설정된 우선순위 목록 순(목록에 없는 key는 뒤)
+		return ia < ib
+	}
+	if key := canonicalFieldKeys[canon]; (a == key) != (b == key) { //2) 표준 key(block_hash 등)
+		return a == key
+	}
+	if exact[a] != exact[b] { //3) 사전과 정확히 일치한 key가 정규화로 일치한 key보다 우선
+		return exact[a]
+	}
+	return a < b //4) 사전순
+} //같은 표준 필드로 매핑되는 두 key의 우선순위 비교
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return len(list)
+} //list 내 s의 위치(없을 시 len(list))
+
+type LintSeverity string
+
+const (
+	LintError   LintSeverity = "error"   //parsing 결과가 입력 순서 등에 따라 달라질 수 있음
+	LintWarning LintSeverity = "warning" //의미가 다른 이름이 섞였을 가능성
+	LintInfo    LintSeverity = "info"    //정보 손실이 있는 정규화
+)
+
+type SynonymIssue struct {
+	Severity LintSeverity
+	Rule     string   //규칙 이름
+	Kind     string   //"phase" 또는 "field"
+	Names    []string //관련 원본 이름
+	Message  string
+} //사전 검사 결과 항목
+
+func (i SynonymIssue) String() string {
+	return fmt.Sprintf("%s [%s] %s: %s", i.Severity, i.Rule, strings.Join(i.Names, ", "), i.Message)
+} //"warning [plural-mismatch] commit_seal, commit_seals: ..." 형태
+
+var knownPhases = map[string]bool{
+	"Proposal": true, "Prepare": true, "Vote": true, "Commit": true, "ViewChange": true, "NewView": true,
+} //abstraction.MsgType 표준값
+
+var integerFields = map[string]bool{"Height": true, "Round": true, "View": true} //정수 필드
+var digestFields = map[string]bool{"BlockHash": true, "PrevHash": true}          //해시 필드
+
+func LintSynonyms(s *SynonymSet) []SynonymIssue {
+	var issues []SynonymIssue
+	for _, c := range s.Collisions() { //정규화 충돌
+		issues = append(issues, SynonymIssue{
+			Severity: LintError, Rule: "fold-collision", Kind: c.Kind, Names: c.Names,
+			Message: fmt.Sprintf("names fold to %q but map to %s", c.Folded, strings.Join(c.Candidates, " and ")),
+		})
+	}
+	phases, fields := s.Phases(), s.Fields()
+	for _, name := range sortedMapKeys(phases) {
+		if canon := phases[name]; !knownPhases[canon] {
+			issues = append(issues, SynonymIssue{Severity: LintWarning, Rule: "unknown-target", Kind: "phase", Names: []string{name},
+				Message: fmt.Sprintf("maps to non-standard type %q", canon)})
+		}
+	}
+	for _, name := range sortedMapKeys(fields) {
+		canon := fields[name]
+		if _, ok := canonicalFieldKeys[canon]; !ok {
+			issues = append(issues, SynonymIssue{Severity: LintWarning, Rule: "unknown-target", Kind: "field", Names: []string{name},
+				Message: fmt.Sprintf("maps to unknown field %q", canon)})
+			continue
+		}
+		if other, ok := fields[name+"s"]; ok && other != canon { //단수/복수형이 다른 필드로 매핑
+			issues = append(issues, SynonymIssue{Severity: LintWarning, Rule: "plural-mismatch", Kind: "field", Names: []string{name, name + "s"},
+				Message: fmt.Sprintf("singular maps to %s, plural maps to %s", canon, other)})
+		}
+		for _, otherCanon := range sortedMapKeys(canonicalFieldKeys) { //다른 필드의 표준 key를 포함
+			key := canonicalFieldKeys[otherCanon]
+			if otherCanon != canon && name != canonicalFieldKeys[canon] && containsToken(name, key) {
+				issues = append(issues, SynonymIssue{Severity: LintWarning, Rule: "embedded-key", Kind: "field", Names: []string{name},
+					Message: fmt.Sprintf("maps to %s but contains %q (%s)", canon, key, otherCanon)})
+			}
+		}
+		toks := strings.Split(name, "_")
+		last := toks[len(toks)-1]
+		switch {
+		case integerFields[canon] && (last == "hash" || last == "digest" || last == "id"):
+			issues = append(issues, SynonymIssue{Severity: LintWarning, Rule: "type-hint", Kind: "field", Names: []string{name},
+				Message: fmt.Sprintf("integer field %s from a name that suggests an identifier", canon)})
+		case digestFields[canon] && (last == "id" || last == "num" || last == "number"):
+			issues = append(issues, SynonymIssue{Severity: LintWarning, Rule: "type-hint", Kind: "field", Names: []string{name},
+				Message: fmt.Sprintf("hash field %s from a name that suggests an identifier, not a digest", canon)})
+		}
+	}
+	byCanon := map[string][]string{} //표준 타입 -> 요청/응답 쌍 접미사를 제거한 이름
+	for _, name := range sortedMapKeys(phases) {
+		for _, suf := range []string{"Request", "Response", "Req", "Res"} {
+			if base := strings.TrimSuffix(name, suf); base != name && base != "" {
+				byCanon[phases[name]+"\x00"+base] = append(byCanon[phases[name]+"\x00"+base], name)
+				break
+			}
+		}
+	}
+	for _, key := range sortedMapKeys(byCanon) {
+		if names := byCanon[key]; len(names) > 1 {
+			issues = append(issues, SynonymIssue{Severity: LintInfo, Rule: "request-response-collapse", Kind: "phase", Names: names,
+				Message: fmt.Sprintf("request and response collapse to %s; direction is lost", strings.SplitN(key, "\x00", 2)[0])})
+		}
+	}
+	return issues
+} //사전에서 의심스러운 매핑(정규화 충돌, 단수/복수 불일치, 다른 필드 key 포함 등) 검사
+
+func containsToken(name, key string) bool {
+	return strings.HasPrefix(name, key+"_") || strings.HasSuffix(name, "_"+key) || strings.Contains(name, "_"+key+"_")
+} //snake_case name이 key를 단어 단위로 포함하는지 확인
+
+func sortedMapKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+} //map의 key 정렬 목록
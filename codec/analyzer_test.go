@@ -0,0 +1,51 @@
+package codec
+
+import (
+	"strings"
+	"testing"
+
+	"codec/abstraction"
+)
+
+func TestEmptySynonymLoses(t *testing.T) {
+	cases := []struct {
+		format Format
+		in     string
+	}{
+		{FormatJSON, `{"type":"Commit","block_hash":"","digest":"0xab","height":null}`},
+		{FormatGeneric, `Commit(block_hash=,digest=0xab,height=)`},
+	}
+	for _, tc := range cases {
+		am, err := Parse([]byte(tc.in), ParseOptions{Format: tc.format})
+		if err != nil {
+			t.Fatalf("%s: %v", tc.format, err)
+		}
+		if am.BlockHash != "0xab" || am.OriginalFieldNames["BlockHash"] != "digest" {
+			t.Errorf("%s: BlockHash = %q from %q, want 0xab from digest", tc.format, am.BlockHash, am.OriginalFieldNames["BlockHash"])
+		}
+		if am.Height != nil {
+			t.Errorf("%s: Height = %v, want nil", tc.format, am.Height)
+		}
+		for _, k := range []string{"block_hash", "height"} { //빈 값 key는 밀린 유의어로 Extras에 보존
+			if _, ok := am.Extras[k]; !ok {
+				t.Errorf("%s: Extras[%s] missing: %v", tc.format, k, am.Extras)
+			}
+		}
+		if tc.format == FormatJSON && am.Extras["height"].Kind != abstraction.ExtraNull {
+			t.Errorf("json: Extras[height] = %+v, want null", am.Extras["height"])
+		}
+		out, err := Serialize(am, SerializeOptions{Format: tc.format, PreserveOriginalNames: true, ForceReencode: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, k := range []string{"block_hash", "digest", "height"} {
+			if !strings.Contains(string(out), k) {
+				t.Errorf("%s: PreserveOriginalNames output %s lost key %s", tc.format, out, k)
+			}
+		}
+	}
+	collisions, err := AnalyzeKeys([]string{"block_hash", "digest"}, ParseOptions{})
+	if err != nil || len(collisions) != 1 || collisions[0].Winner != "block_hash" {
+		t.Fatalf("AnalyzeKeys = %+v, %v (values unknown: usual ranking)", collisions, err)
+	}
+} //null/"" 값의 표준 필드 유의어는 다른 유의어에 밀려 Extras에 남고 원본 key 유지
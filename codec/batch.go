@@ -0,0 +1,155 @@
+package codec
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"codec/abstraction"
+)
+
+type BatchResult struct {
+	Index   int                          //입력 순서(0부터)
+	Message *abstraction.AbstractMessage //parsing 결과(실패 시 nil)
+	Err     error                        //항목별 에러(다른 항목의 parsing은 계속)
+} //일괄 parsing의 항목 하나
+
+type PipelineOptions struct {
+	Workers int  //동시에 parsing하는 goroutine 수(0 이하일 시 GOMAXPROCS)
+	Ordered bool //입력 순서대로 출력(false일 시 완료 순서, 순서 대기 없이 더 빠름)
+} //ParsePipeline 설정
+
+func batchWorkers(workers, n int) int {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if n >= 0 && workers > n {
+		workers = n
+	}
+	return max(workers, 1)
+} //worker 수(입력 수 n이 정해진 경우 n 이하)
+
+func ParseBatch(ctx context.Context, inputs [][]byte, opts ParseOptions, workers int) ([]BatchResult, error) {
+	results := make([]BatchResult, len(inputs))
+	for i := range results {
+		results[i].Index = i
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range batchWorkers(workers, len(inputs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next { //항목마다 다른 results 원소에 기록
+				results[i].Message, results[i].Err = ParseContext(ctx, inputs[i], opts)
+			}
+		}()
+	}
+	sent := 0
+dispatch:
+	for ; sent < len(inputs); sent++ {
+		select {
+		case next <- sent:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(next)
+	wg.Wait()
+	if sent < len(inputs) { //취소로 시작하지 못한 항목
+		err := ctx.Err()
+		for i := sent; i < len(inputs); i++ {
+			results[i].Err = err
+		}
+		return results, err
+	}
+	return results, nil
+} //inputs를 workers개의 goroutine으로 parsing, 결과는 입력 순서(취소 시 시작하지 못한 항목은 ctx.Err(), parsing 중이던 항목은 이를 감싼 ParseError)
+
+func ParsePipeline(ctx context.Context, in <-chan []byte, opts ParseOptions, popts PipelineOptions) <-chan BatchResult {
+	workers := batchWorkers(popts.Workers, -1)
+	out := make(chan BatchResult, workers)
+	type job struct {
+		index int
+		data  []byte
+		slot  chan BatchResult //Ordered일 시 결과 자리
+	}
+	jobs := make(chan job)
+	var slots chan chan BatchResult //Ordered일 시 입력 순서의 결과 자리(버퍼 크기로 앞서 parsing할 항목 수 제한)
+	if popts.Ordered {
+		slots = make(chan chan BatchResult, workers)
+	}
+	go func() {
+		defer close(jobs)
+		if slots != nil {
+			defer close(slots)
+		}
+		for i := 0; ; i++ {
+			var j job
+			select {
+			case data, ok := <-in:
+				if !ok {
+					return
+				}
+				j = job{index: i, data: data}
+			case <-ctx.Done():
+				return
+			}
+			if slots != nil {
+				j.slot = make(chan BatchResult, 1)
+				select {
+				case slots <- j.slot:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case jobs <- j:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}() //입력 channel을 읽어 번호를 붙여 worker에 분배
+	emit := func(r BatchResult) bool {
+		select {
+		case out <- r:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				am, err := ParseContext(ctx, j.data, opts)
+				r := BatchResult{Index: j.index, Message: am, Err: err}
+				if j.slot != nil {
+					j.slot <- r //버퍼 1, 막히지 않음
+				} else if !emit(r) {
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		defer close(out)
+		if slots == nil {
+			wg.Wait()
+			return
+		}
+		for slot := range slots { //입력 순서대로 결과 대기
+			select {
+			case r := <-slot:
+				if !emit(r) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+} //in에서 받은 메시지를 동시에 parsing해 결과를 channel로 출력(in이 닫히고 결과를 모두 보내거나 ctx 취소 시 출력 channel을 닫음, 취소 전 출력을 읽지 않으면 goroutine이 대기)
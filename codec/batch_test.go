@@ -0,0 +1,118 @@
+package codec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func batchInputs(n int) [][]byte {
+	inputs := make([][]byte, n)
+	for i := range inputs {
+		if i%5 == 3 {
+			inputs[i] = []byte(`{"type":"Commit","height":`) //잘린 JSON
+			continue
+		}
+		inputs[i] = []byte(fmt.Sprintf(`{"type":"Commit","height":%d}`, i))
+	}
+	return inputs
+} //height가 입력 번호인 JSON 메시지(5개 중 하나는 잘못된 입력)
+
+func checkBatchResult(t *testing.T, r BatchResult) {
+	t.Helper()
+	if r.Index%5 == 3 {
+		var pe *ParseError
+		if r.Message != nil || !errors.As(r.Err, &pe) {
+			t.Errorf("item %d: want *ParseError, got %v, %v", r.Index, r.Message, r.Err)
+		}
+		return
+	}
+	if r.Err != nil {
+		t.Fatalf("item %d: %v", r.Index, r.Err)
+	}
+	if r.Message.Height == nil || r.Message.Height.Int64() != int64(r.Index) {
+		t.Errorf("item %d: height %v", r.Index, r.Message.Height)
+	}
+} //항목 번호와 결과가 맞는지 확인
+
+func TestParseBatch(t *testing.T) {
+	inputs := batchInputs(200)
+	for _, workers := range []int{0, 1, 7} {
+		results, err := ParseBatch(context.Background(), inputs, ParseOptions{Format: FormatJSON}, workers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != len(inputs) {
+			t.Fatalf("workers=%d: %d results for %d inputs", workers, len(results), len(inputs))
+		}
+		for i, r := range results {
+			if r.Index != i {
+				t.Fatalf("workers=%d: results[%d].Index = %d", workers, i, r.Index)
+			}
+			checkBatchResult(t, r)
+		}
+	}
+} //입력 순서 유지, 항목별 에러 수집
+
+func TestParseBatchCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := ParseBatch(ctx, batchInputs(50), ParseOptions{Format: FormatJSON}, 4)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	skipped := 0
+	for _, r := range results {
+		if errors.Is(r.Err, context.Canceled) {
+			skipped++
+		}
+	}
+	if skipped == 0 {
+		t.Error("no item reports context.Canceled")
+	}
+} //취소 시 시작하지 못한 항목에 ctx.Err()
+
+func TestParsePipeline(t *testing.T) {
+	inputs := batchInputs(300)
+	for _, ordered := range []bool{true, false} {
+		in := make(chan []byte)
+		go func() {
+			defer close(in)
+			for _, b := range inputs {
+				in <- b
+			}
+		}()
+		seen := make([]bool, len(inputs))
+		next := 0
+		for r := range ParsePipeline(context.Background(), in, ParseOptions{Format: FormatJSON}, PipelineOptions{Workers: 6, Ordered: ordered}) {
+			if ordered && r.Index != next {
+				t.Fatalf("ordered: got index %d, want %d", r.Index, next)
+			}
+			if seen[r.Index] {
+				t.Fatalf("index %d emitted twice", r.Index)
+			}
+			seen[r.Index] = true
+			next++
+			checkBatchResult(t, r)
+		}
+		if next != len(inputs) {
+			t.Fatalf("ordered=%v: %d results for %d inputs", ordered, next, len(inputs))
+		}
+	}
+} //순서 유지/완료 순 출력에서 모든 항목이 한 번씩
+
+func TestParsePipelineCanceled(t *testing.T) {
+	for _, ordered := range []bool{true, false} {
+		ctx, cancel := context.WithCancel(context.Background())
+		in := make(chan []byte) //닫히지 않는 입력
+		out := ParsePipeline(ctx, in, ParseOptions{Format: FormatJSON}, PipelineOptions{Workers: 2, Ordered: ordered})
+		in <- []byte(`{"type":"Commit","height":1}`)
+		if r := <-out; r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		cancel()
+		for range out { //취소 후 출력 channel이 닫혀야 함
+		}
+	}
+} //취소 시 입력이 남아 있어도 출력 channel을 닫음
@@ -1,35 +1,51 @@
-package codec
-
-import (
-	"fmt"
-
-	"codec/abstraction"
-
-	bcs "github.com/fardream/go-bcs/bcs"
-)
-
-type bcsCodec struct{} //bcs 포맷 parsing/serializing
-
-func (bcsCodec) Parse(data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error) {
-	var raw []byte
-	if _, err := bcs.Unmarshal(data, &raw); err == nil {
-		return (jsonCodec{}).Parse(raw, ParseOptions{Format: FormatJSON, OverrideMsgType: opts.OverrideMsgType})
-	}
-	var decoded map[string]interface{}
-	if _, err := bcs.Unmarshal(data, &decoded); err != nil {
-		return nil, fmt.Errorf("bcs decode: %w", err)
-	}
-	js, err := jsonFromInterface(decoded)
-	if err != nil {
-		return nil, err
-	}
-	return (jsonCodec{}).Parse(js, ParseOptions{Format: FormatJSON, OverrideMsgType: opts.OverrideMsgType})
-} //bcs 바이트를 AbstractMessage로 변환
-
-func (bcsCodec) Serialize(am *abstraction.AbstractMessage, _ SerializeOptions) ([]byte, error) {
-	js, err := (jsonCodec{}).Serialize(am, SerializeOptions{Format: FormatJSON}) //JSON 바이트로 변환
-	if err != nil {
-		return nil, err
-	}
-	return bcs.Marshal(js) //bcs payload를 []byte(JSON) 형태로 serializing
-} //AbstractMessage를 BCS 바이트로 변환
+package codec
+
+import (
+	"codec/abstraction"
+
+	bcs "github.com/fardream/go-bcs/bcs"
+)
+
+type bcsCodec struct{} //bcs 포맷 parsing/serializing
+
+func (bcsCodec) Parse(data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error) {
+	if err := checkBCSLimits(data); err != nil { //선언된 길이로 할당하기 전 구조 확인
+		return nil, err
+	}
+	var raw []byte
+	if _, err := bcs.Unmarshal(data, &raw); err == nil {
+		am, err := (jsonCodec{}).Parse(raw, jsonParseOptions(opts))
+		if err != nil {
+			return nil, decodeError(FormatBCS, data, err) //내부 JSON payload 에러
+		}
+		am.OriginalFormat = string(FormatBCS) //원본 포맷 기록
+		return am, nil
+	}
+	var decoded map[string]interface{}
+	if _, err := bcs.Unmarshal(data, &decoded); err != nil {
+		return nil, decodeError(FormatBCS, data, err)
+	}
+	opts.Format = FormatBCS
+	am, err := messageFromMap(decoded, opts)
+	if err != nil {
+		return nil, decodeError(FormatBCS, data, err)
+	}
+	return am, nil
+} //bcs 바이트를 AbstractMessage로 변환
+
+func (bcsCodec) Serialize(am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error) {
+	out, err := messageToMap(am, jsonOptions(opts), extraInterface)
+	if err != nil {
+		return nil, err
+	}
+	return encodeJSON(out, bcsLengthPrefix) //bcs payload를 []byte(JSON) 형태로 serializing
+} //AbstractMessage를 BCS 바이트로 변환
+
+func bcsLengthPrefix(dst []byte, n int) []byte {
+	for x := uint64(n); ; x >>= 7 {
+		if x < 0x80 {
+			return append(dst, byte(x))
+		}
+		dst = append(dst, byte(x)|0x80)
+	}
+} //bcs.Marshal이 []byte 앞에 쓰는 ULEB128 길이
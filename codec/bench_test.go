@@ -0,0 +1,112 @@
+package codec
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"codec/abstraction"
+)
+
+var benchFormats = []Format{FormatGeneric, FormatJSON, FormatProtobuf, FormatRLP, FormatMsgPack, FormatBCS}
+
+var benchSizes = []struct {
+	name                   string
+	seals, changes, extras int
+}{
+	{"small", 0, 0, 0},
+	{"medium", 4, 1, 4},
+	{"large", 100, 32, 64},
+} //CommitSeals/ViewChanges/Extras 개수로 나눈 크기 등급
+
+func benchMessage(seals, changes, extras int) *abstraction.AbstractMessage {
+	am := &abstraction.AbstractMessage{
+		Type:      abstraction.MsgTypeCommit,
+		Height:    big.NewInt(5302117),
+		Round:     big.NewInt(2),
+		View:      big.NewInt(1),
+		Timestamp: time.Date(2024, 3, 14, 9, 26, 53, 0, time.UTC),
+		BlockHash: "0x8a1f2c4e6b7d9f0e1a3c5b7d9e0f2a4c6e8b0d1f3a5c7e9b1d3f5a7c9e0b2d4f",
+		PrevHash:  "0x1d3f5a7c9e0b2d4f6a8c1e3b5d7f9a2c4e6b8d0f1a3c5e7b9d2f4a6c8e0b1d3f",
+		Proposer:  "0xa3f1c95e7b2d48e0c6d9f1b3e5a7c9d0b2e4f6a8",
+		Validator: "0xa3f1c95e7b2d48e0c6d9f1b3e5a7c9d0b2e4f6a8",
+		Signature: "0x4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c",
+	}
+	for i := 0; i < seals; i++ {
+		am.CommitSeals = append(am.CommitSeals, fmt.Sprintf("0x%064x", i+1))
+	}
+	for i := 0; i < changes; i++ {
+		am.ViewChanges = append(am.ViewChanges, abstraction.ViewChangeEntry{
+			View: big.NewInt(int64(i)), Height: big.NewInt(5302117), Validator: fmt.Sprintf("node-%d", i), Signature: fmt.Sprintf("0x%040x", i),
+		})
+	}
+	if extras > 0 {
+		am.Extras = make(map[string]abstraction.ExtraValue, extras)
+	}
+	for i := 0; i < extras; i++ {
+		var v abstraction.ExtraValue
+		switch i % 4 {
+		case 0:
+			v = abstraction.StringExtra(fmt.Sprintf("value-%d", i))
+		case 1:
+			v = abstraction.IntExtra(big.NewInt(int64(i) * 1000))
+		case 2:
+			v = abstraction.BoolExtra(i%3 == 0)
+		default:
+			v = abstraction.ListExtra(abstraction.StringExtra("a"), abstraction.IntExtra(big.NewInt(int64(i))))
+		}
+		am.Extras[fmt.Sprintf("extra_%02d", i)] = v
+	}
+	return am
+} //크기 등급별 벤치마크 메시지
+
+func benchOptions(format Format) (ParseOptions, SerializeOptions) {
+	return ParseOptions{Format: format, ProtoMessageFullName: fuzzProtoMessage},
+		SerializeOptions{Format: format, ProtoMessageFullName: fuzzProtoMessage, ProtoDiscardUnknown: true, ForceReencode: true}
+} //포맷별 벤치마크 옵션(passthrough 없이 codec을 거침)
+
+func BenchmarkParse(b *testing.B) {
+	fuzzSetup(b)
+	for _, format := range benchFormats {
+		popts, sopts := benchOptions(format)
+		for _, size := range benchSizes {
+			data, err := Serialize(benchMessage(size.seals, size.changes, size.extras), sopts)
+			if err != nil {
+				b.Fatalf("%s/%s: %v", format, size.name, err)
+			}
+			b.Run(fmt.Sprintf("%s/%s", format, size.name), func(b *testing.B) {
+				b.SetBytes(int64(len(data)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := Parse(data, popts); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+} //포맷/크기별 parsing 처리량
+
+func BenchmarkSerialize(b *testing.B) {
+	fuzzSetup(b)
+	for _, format := range benchFormats {
+		_, sopts := benchOptions(format)
+		for _, size := range benchSizes {
+			am := benchMessage(size.seals, size.changes, size.extras)
+			data, err := Serialize(am, sopts)
+			if err != nil {
+				b.Fatalf("%s/%s: %v", format, size.name, err)
+			}
+			b.Run(fmt.Sprintf("%s/%s", format, size.name), func(b *testing.B) {
+				b.SetBytes(int64(len(data)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := Serialize(am, sopts); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+} //포맷/크기별 직렬화 처리량
@@ -1,108 +1,263 @@
-package codec
-
-import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"unicode/utf8"
-
-	"codec/abstraction"
-)
-
-type Format string
-
-const (
-	FormatAuto     Format = "auto"     //자동 감지
-	FormatGeneric  Format = "generic"  //Phase(k=v,...) 형태의 문자열 포맷
-	FormatJSON     Format = "json"     //JSON
-	FormatProtobuf Format = "protobuf" //Protocol Buffers(binary)
-	FormatRLP      Format = "rlp"      //Ethereum RLP
-	FormatMsgPack  Format = "msgpack"  //MessagePack
-	FormatBCS      Format = "bcs"      //BCS(Binary Canonical Serialization)
-)
-
-type ParseOptions struct {
-	Format               Format                  //명시된 포맷
-	OverrideMsgType      string                  //메시지 타입명 덮어씀
-	ProtoMessageFullName string                  //protobuf 메시지 full name
-	DescriptorProvider   ProtoDescriptorProvider //protobuf 동적 parsing에 필요한 descriptor
-	ProtoDiscardUnknown  bool                    //protobuf → JSON 변환 시 지원되지 않는 필드 무시
-}
-
-type SerializeOptions struct {
-	Format               Format                  //출력 포맷
-	ProtoMessageFullName string                  //protobuf로 직렬화할 때 대상 메시지 full name
-	DescriptorProvider   ProtoDescriptorProvider //protobuf 메시지 동적 생성에 필요한 descriptor
-	ProtoDiscardUnknown  bool                    //JSON→protobuf 역매핑 시 지원되지 않는 필드 무시
-}
-
-type Codec interface {
-	Parse(data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error)       //바이트 → AbstractMessage
-	Serialize(am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error) //AbstractMessage → 바이트
-}
-
-func DetectFormat(data []byte) Format {
-	trim := bytes.TrimSpace(data) // 앞뒤 공백 제거
-	if len(trim) == 0 {           //비어 있을 시
-		return FormatGeneric //human-readable generic으로 간주
-	}
-	if (trim[0] == '{' || trim[0] == '[') && utf8.Valid(trim) { //시작 문자가 '{' 또는 '['이고 UTF-8 유효할 시
-		var js json.RawMessage
-		if json.Unmarshal(trim, &js) == nil { //parsing 시도하여 성공 시
-			return FormatJSON //JSON으로 간주
-		}
-	}
-	if len(trim) > 0 && (trim[0] >= 0xc0 || trim[0] <= 0xbf) {
-		return FormatRLP
-	} //RLP: 0xc0~0xff 범위 prefix
-	if len(trim) > 0 && ((trim[0] >= 0x80 && trim[0] <= 0x9f) || (trim[0] >= 0xa0 && trim[0] <= 0xbf)) {
-		return FormatMsgPack
-	} //MsgPack: 0x80~0x9f, 0xa0~0xbf 범위
-	return FormatProtobuf //그 외 protobuf(binary)로 간주
-} //입력 바이트 검사하여 포맷 추정
-
-func Parse(data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error) {
-	format := opts.Format                     //옵션에 명시된 포맷 확인
-	if format == "" || format == FormatAuto { // 빈 값 또는 auto일 시
-		format = DetectFormat(data) //입력으로 포맷 추정
-	}
-	switch format {
-	case FormatGeneric:
-		return (genericCodec{}).Parse(data, opts)
-	case FormatJSON:
-		return (jsonCodec{}).Parse(data, opts)
-	case FormatProtobuf:
-		return (protoCodec{}).Parse(data, opts)
-	case FormatRLP:
-		return (rlpCodec{}).Parse(data, opts)
-	case FormatMsgPack:
-		return (msgpackCodec{}).Parse(data, opts)
-	case FormatBCS:
-		return (bcsCodec{}).Parse(data, opts)
-	default:
-		return nil, fmt.Errorf("unsupported format: %s", format) //지원되지 않는 포맷
-	}
-} //포맷에 맞는 codec으로 parsing
-
-func Serialize(am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error) {
-	format := opts.Format                     //출력 포맷 확인
-	if format == "" || format == FormatAuto { //지정 안 되어있을 시
-		format = FormatGeneric //human-readable generic 사용
-	}
-	switch format {
-	case FormatGeneric:
-		return (genericCodec{}).Serialize(am, opts)
-	case FormatJSON:
-		return (jsonCodec{}).Serialize(am, opts)
-	case FormatProtobuf:
-		return (protoCodec{}).Serialize(am, opts)
-	case FormatRLP:
-		return (rlpCodec{}).Serialize(am, opts)
-	case FormatMsgPack:
-		return (msgpackCodec{}).Serialize(am, opts)
-	case FormatBCS:
-		return (bcsCodec{}).Serialize(am, opts)
-	default:
-		return nil, fmt.Errorf("unsupported format: %s", format) //지원되지 않는 포맷
-	}
-} //포맷에 맞는 codec으로 직렬화
+package codec
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"codec/abstraction"
+)
+
+type Format string
+
+const (
+	FormatAuto     Format = "auto"     //자동 감지
+	FormatGeneric  Format = "generic"  //Phase(k=v,...) 형태의 문자열 포맷
+	FormatJSON     Format = "json"     //JSON
+	FormatProtobuf Format = "protobuf" //Protocol Buffers(binary)
+	FormatRLP      Format = "rlp"      //Ethereum RLP
+	FormatMsgPack  Format = "msgpack"  //MessagePack
+	FormatBCS      Format = "bcs"      //BCS(Binary Canonical Serialization)
+)
+
+type ParseOptions struct {
+	Format               Format                       //명시된 포맷
+	OverrideMsgType      string                       //메시지 타입명 덮어씀
+	ProtoMessageFullName string                       //protobuf 메시지 full name
+	DescriptorProvider   ProtoDescriptorProvider      //protobuf 동적 parsing에 필요한 descriptor
+	ProtoDiscardUnknown  bool                         //protobuf → JSON 변환 시 지원되지 않는 필드 무시
+	Synonyms             *SynonymSet                  //유의어 사전(nil일 시 DefaultSynonyms)
+	ExactSynonyms        bool                         //대소문자/구분자 정규화 없이 정확히 일치하는 이름만 매핑
+	StrictSynonyms       bool                         //정규화 결과가 모호한 이름을 에러로 처리(false일 시 Extras로 보존)
+	OnAmbiguousSynonym   func(*AmbiguousSynonymError) //StrictSynonyms가 아닐 때 Extras로 보존한 모호한 이름을 받는 callback
+	HashEncoding         HashEncoding                 //해시/서명 필드의 표준 텍스트 형태(빈 값일 시 HashHex)
+	DetectBase64         bool                         //짧은/URL-safe/패딩 없는 해시와 정수 문자열도 base64로 검사(24자 이상 표준 base64 해시는 항상)
+	Compression          Compression                  //payload 압축(빈 값일 시 frame magic으로 감지)
+	MaxDecompressedSize  int                          //압축 해제 결과 상한(0 이하일 시 Limits.MaxBytes, 그것도 없을 시 DefaultMaxDecompressedSize)
+	Limits               Limits                       //입력 크기/깊이/원소 수 상한(0인 항목은 무제한, 신뢰할 수 없는 입력에는 DefaultLimits)
+	TrackChanges         bool                         //parsing 직후 값을 기록(MarkClean)하여 같은 포맷으로 Serialize 시 원본 바이트 재사용/부분 재인코딩
+	RLPFields            []RLPField                   //RLP 리스트 원소의 위치별 필드(지정 시 JSON payload 대신 리스트로 parsing)
+}
+
+type SerializeOptions struct {
+	Format                Format                  //출력 포맷
+	ProtoMessageFullName  string                  //protobuf로 직렬화할 때 대상 메시지 full name
+	DescriptorProvider    ProtoDescriptorProvider //protobuf 메시지 동적 생성에 필요한 descriptor
+	ProtoDiscardUnknown   bool                    //JSON→protobuf 역매핑 시 지원되지 않는 필드 무시
+	PreserveOriginalNames bool                    //parsing 시 기록된 원본 메시지명/필드명으로 출력(다시 parsing해도 같은 타입/필드가 되는 이름만)
+	Synonyms              *SynonymSet             //PreserveOriginalNames일 때 원본 이름을 확인할 사전(nil일 시 내장 사전)
+	Vocabulary            string                  //대상 구현체 어휘 프로파일 이름(예: tendermint, fabric, ibft)
+	TimestampFormat       TimestampFormat         //timestamp 출력 형태(빈 값일 시 codec 기본값)
+	ForceReencode         bool                    //변경이 없어도 RawPayload를 재사용하지 않고 필드에서 다시 인코딩
+	RequiredFields        []string                //Convert 시 손실되면 안 되는 필드(FidelityFields 이름)
+	FidelityPolicy        FidelityPolicy          //필수 필드 손실 시 처리(빈 값일 시 FidelityRefuse)
+	OnFidelityLoss        func(*FidelityError)    //FidelityWarn일 때 손실된 필수 필드를 받는 callback(nil일 시 알리지 않음)
+	Compression           Compression             //직렬화 결과 압축(빈 값일 시 압축 안 함)
+}
+
+type Codec interface {
+	Parse(data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error)       //바이트 → AbstractMessage
+	Serialize(am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error) //AbstractMessage → 바이트
+}
+
+type ContextCodec interface {
+	ParseContext(ctx context.Context, data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error)
+	SerializeContext(ctx context.Context, am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error)
+} //취소/기한을 받는 Codec(Codec과 함께 구현하거나 WithoutContext로 감싸 RegisterCodec에 등록)
+
+type contextAdapter struct{ c Codec } //Codec -> ContextCodec
+
+func (a contextAdapter) ParseContext(ctx context.Context, data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.c.Parse(data, opts)
+} //시작 전에만 취소 확인
+
+func (a contextAdapter) SerializeContext(ctx context.Context, am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.c.Serialize(am, opts)
+} //시작 전에만 취소 확인
+
+type codecAdapter struct{ c ContextCodec } //ContextCodec -> Codec
+
+func (a codecAdapter) Parse(data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error) {
+	return a.c.ParseContext(context.Background(), data, opts)
+} //취소 없는 context로 호출
+
+func (a codecAdapter) Serialize(am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error) {
+	return a.c.SerializeContext(context.Background(), am, opts)
+} //취소 없는 context로 호출
+
+func WithContext(c Codec) ContextCodec {
+	switch t := c.(type) {
+	case ContextCodec: //직접 구현한 codec
+		return t
+	case codecAdapter:
+		return t.c
+	}
+	return contextAdapter{c}
+} //Codec을 ContextCodec으로(ContextCodec을 구현하지 않을 시 시작 전에만 취소 확인)
+
+func WithoutContext(c ContextCodec) Codec {
+	switch t := c.(type) {
+	case Codec:
+		return t
+	case contextAdapter:
+		return t.c
+	}
+	return codecAdapter{c}
+} //ContextCodec을 Codec으로(context.Background()로 호출)
+
+func DetectFormat(data []byte) Format {
+	trim := bytes.TrimSpace(data) // 앞뒤 공백 제거
+	if len(trim) == 0 {           //비어 있을 시
+		return FormatGeneric //human-readable generic으로 간주
+	}
+	if n, k := binary.Uvarint(data); k > 0 && n > 0 && n == uint64(len(data)-k) && data[k] == '{' {
+		return FormatBCS
+	} //BCS: uleb128 길이 + JSON 바이트열(길이 바이트가 공백 문자일 수 있어 JSON보다 먼저 확인)
+	if (trim[0] == '{' || trim[0] == '[') && utf8.Valid(trim) { //시작 문자가 '{' 또는 '['이고 UTF-8 유효할 시
+		var js json.RawMessage
+		if json.Unmarshal(trim, &js) == nil { //parsing 시도하여 성공 시
+			return FormatJSON //JSON으로 간주
+		}
+	}
+	if isGenericText(trim) { //Phase(...) 형태 텍스트
+		return FormatGeneric
+	}
+	if _, size, ok := rlpHeader(data); ok && data[0] >= 0x80 && size == uint64(len(data)) {
+		return FormatRLP
+	} //RLP: header의 길이가 입력 전체와 일치(0x00~0x7f 단일 바이트는 header 없는 값이라 메시지가 아님)
+	if b := data[0]; (b >= 0x80 && b <= 0x8f) || b == 0xde || b == 0xdf {
+		return FormatMsgPack
+	} //MsgPack: map(fixmap 0x80~0x8f, map16/map32)
+	return FormatProtobuf //그 외 protobuf(binary)로 간주
+} //입력 바이트 검사하여 포맷 추정
+
+func isGenericText(trim []byte) bool {
+	if !utf8.Valid(trim) || trim[len(trim)-1] != ')' || bytes.IndexByte(trim, '(') < 0 {
+		return false
+	}
+	for _, c := range trim { //protobuf tag/길이 바이트 등 제어 문자가 있으면 텍스트가 아님
+		if (c < 0x20 && c != '\t' && c != '\n' && c != '\r') || c == 0x7f {
+			return false
+		}
+	}
+	if _, n, ok := genericVersionPrefix(trim); ok { //"@N " version 표시
+		trim = bytes.TrimLeft(trim[n:], " \t")
+	}
+	b := trim[0]
+	return b == '"' || b == '_' || isLetter(b)
+} //제어 문자 없이 (version 표시 뒤) 메시지명으로 시작해 ')'로 끝나는 텍스트인지 확인
+
+func Parse(data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error) {
+	return ParseContext(context.Background(), data, opts)
+} //등록된 codec으로 parsing
+
+func ParseContext(ctx context.Context, data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &ParseError{Format: FormatAuto, Offset: -1, Err: err}
+	}
+	lim := opts.Limits
+	if err := lim.check("MaxBytes", lim.MaxBytes, len(data), ""); err != nil {
+		return nil, &ParseError{Format: FormatAuto, Offset: int64(lim.MaxBytes), Err: err}
+	}
+	maxOut := opts.MaxDecompressedSize
+	if maxOut <= 0 {
+		maxOut = lim.MaxBytes
+	}
+	data, err := decompress(ctx, data, opts.Compression, maxOut) //압축 해제(포맷 감지 전, 읽는 중 취소 확인)
+	if err != nil {
+		return nil, err
+	}
+	format := opts.Format                     //옵션에 명시된 포맷 확인
+	if format == "" || format == FormatAuto { // 빈 값 또는 auto일 시
+		format = DetectFormat(data) //입력으로 포맷 추정
+	}
+	c, ok := lookupContextCodec(format)
+	if !ok {
+		return nil, &ParseError{Format: format, Offset: -1, Err: ErrUnsupportedFormat} //지원되지 않는 포맷
+	}
+	am, err := c.ParseContext(ctx, data, opts)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) && !errors.As(err, new(*ParseError)) {
+			err = &ParseError{Format: format, Offset: -1, Err: err} //취소도 ParseError로
+		}
+		return nil, err
+	}
+	if err := checkMessageLimits(am, lim); err != nil {
+		return nil, limitError(format, data, -1, err)
+	}
+	am.RawPayload = append([]byte(nil), data...) //압축 해제된 원본 바이트(변경 없을 시 Serialize가 그대로 출력)
+	if format == FormatProtobuf {
+		am.OriginalSchema = opts.ProtoMessageFullName
+	}
+	if opts.TrackChanges {
+		am.MarkClean() //이후 변경된 필드 추적(필드 값 깊은 복사)
+	}
+	return am, nil
+} //등록된 codec으로 parsing(ctx는 시작 전, 압축 해제 중, ContextCodec 안에서 확인하며 취소 시 ctx.Err()를 감싼 ParseError)
+
+func Serialize(am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error) {
+	return SerializeContext(context.Background(), am, opts)
+} //포맷에 맞는 codec으로 직렬화 후 opts.Compression으로 압축
+
+func SerializeContext(ctx context.Context, am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	out, err := serialize(ctx, am, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return compress(out, opts.Compression)
+} //Serialize의 취소 가능 버전(취소 시 ctx.Err())
+
+func serialize(ctx context.Context, am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error) {
+	format := opts.Format                     //출력 포맷 확인
+	if format == "" || format == FormatAuto { //지정 안 되어있을 시
+		format = FormatGeneric //human-readable generic 사용
+	}
+	if b, ok := passthrough(am, format, opts); ok { //원본 포맷 그대로, 변경 없음 또는 일부 변경
+		return b, nil
+	}
+	c, ok := lookupContextCodec(format)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format) //지원되지 않는 포맷
+	}
+	return c.SerializeContext(ctx, am, opts)
+} //등록된 codec으로 직렬화(압축 전)
+
+func Convert(data []byte, popts ParseOptions, sopts SerializeOptions) ([]byte, error) {
+	return ConvertContext(context.Background(), data, popts, sopts)
+} //입력 포맷/구현체 메시지를 parsing 후 대상 포맷/어휘로 직렬화(RequiredFields 손실 시 FidelityPolicy에 따라 경고/거부)
+
+func ConvertContext(ctx context.Context, data []byte, popts ParseOptions, sopts SerializeOptions) ([]byte, error) {
+	if err := checkRequiredNames(sopts.RequiredFields); err != nil {
+		return nil, err
+	}
+	am, err := ParseContext(ctx, data, popts)
+	if err != nil {
+		return nil, err
+	}
+	out, err := SerializeContext(ctx, am, sopts)
+	if err != nil {
+		return nil, err
+	}
+	if len(sopts.RequiredFields) > 0 {
+		if err := checkRequired(ctx, am, out, sopts); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+} //Convert의 취소 가능 버전
@@ -0,0 +1,52 @@
+package codec
+
+import "testing"
+
+func TestDetectFormat(t *testing.T) {
+	fuzzSetup(t)
+	cases := []struct {
+		name string
+		in   []byte
+		want Format
+	}{
+		{"empty", nil, FormatGeneric},
+		{"spaces", []byte(" \n\t"), FormatGeneric},
+		{"generic", []byte("Proposal(height=1,proposer=n1)"), FormatGeneric},
+		{"generic versioned", []byte("@1 Commit()"), FormatGeneric},
+		{"generic quoted name", []byte(`"Pre Commit"(height=1)`), FormatGeneric},
+		{"json object", []byte(` {"type":"Commit"} `), FormatJSON},
+		{"json array", []byte(`[1,2]`), FormatJSON},
+		{"rlp empty list", []byte{0xc0}, FormatRLP},
+		{"rlp short string", []byte{0x82, 'h', 'i'}, FormatRLP},
+		{"rlp wrong length", []byte{0xc3, 0x01}, FormatProtobuf},
+		{"single byte x", []byte("x"), FormatProtobuf}, //단일 바이트 RLP 값은 메시지가 아님
+		{"single byte 0x01", []byte{0x01}, FormatProtobuf},
+		{"bcs", []byte("\x02{}"), FormatBCS},
+		{"bcs length is a newline", []byte("\x0a{\"a\":1234}"), FormatBCS}, //TrimSpace가 길이 바이트를 지우기 전에 확인
+		{"bcs wrong length", []byte("\x03{}"), FormatProtobuf},
+		{"rlp long list", append([]byte{0xf8, 0x38}, make([]byte, 0x38)...), FormatRLP},
+		{"msgpack array", []byte{0x91, 0x01}, FormatProtobuf}, //msgpack은 map만(RLP로도 길이 불일치)
+		{"msgpack fixmap", []byte{0x81, 0xa1, 'a', 0x01}, FormatMsgPack},
+		{"msgpack map16", []byte{0xde, 0x00, 0x00}, FormatMsgPack},
+		{"protobuf", []byte{0x08, 0x96, 0x01}, FormatProtobuf},
+		{"protobuf like text", []byte("R\x040xabJ\av,1=(x)"), FormatProtobuf}, //문자로 시작하고 ')'로 끝나는 protobuf
+		{"generic multiline", []byte("Commit(\n\theight=1\r\n)"), FormatGeneric},
+		{"broken json", []byte(`{"type":`), FormatProtobuf},
+	}
+	for _, tc := range cases {
+		if got := DetectFormat(tc.in); got != tc.want {
+			t.Errorf("%s: DetectFormat(%q) = %s, want %s", tc.name, tc.in, got, tc.want)
+		}
+	}
+	for _, format := range []Format{FormatGeneric, FormatJSON, FormatProtobuf, FormatRLP, FormatMsgPack, FormatBCS} {
+		for i, am := range fuzzFixtures() {
+			b, err := Serialize(am, fuzzSerializeOptions(format))
+			if err != nil {
+				continue //대상 포맷에 담을 수 없는 fixture(예: int64 범위 밖 height)
+			}
+			if got := DetectFormat(b); got != format {
+				t.Errorf("%s fixture %d: DetectFormat = %s", format, i, got)
+			}
+		}
+	}
+} //포맷별 입력과 경계 사례의 포맷 감지
@@ -0,0 +1,174 @@
+package codectest
+
+import (
+	"bytes"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	"codec/abstraction"
+	"codec/codec"
+	"codec/codec/codectest/fixtures"
+)
+
+type Config struct {
+	Format           codec.Format                   //검사할 포맷(codec.RegisterCodec로 등록된 것)
+	ParseOptions     codec.ParseOptions             //Format이 비어 있을 시 Config.Format
+	SerializeOptions codec.SerializeOptions         //Format이 비어 있을 시 Config.Format
+	Messages         []*abstraction.AbstractMessage //비어 있을 시 fixtures.Messages()
+	AllowLost        []string                       //손실을 허용할 RequiredFields(fidelity 보고와 무관하게 명시해야 검사 제외)
+} //conformance suite 설정
+
+const injectedExtra = "injected_by_codectest" //mutation 검사에서 추가하는 Extras key
+
+var RequiredFields = []string{"Type", "Height", "Round", "View", "BlockHash", "Validator", "Signature"} //codec이 손실을 보고해도 항상 검사하는 표준 필드
+
+var compareOptions = abstraction.EqualOptions{IgnoreRawPayload: true, TimestampPrecision: time.Second, NilEqualsZero: true}
+
+func (cfg Config) options() (codec.ParseOptions, codec.SerializeOptions) {
+	popts, sopts := cfg.ParseOptions, cfg.SerializeOptions
+	if popts.Format == "" {
+		popts.Format = cfg.Format
+	}
+	if sopts.Format == "" {
+		sopts.Format = cfg.Format
+	}
+	return popts, sopts
+} //Format을 채운 parsing/직렬화 옵션
+
+func (cfg Config) messages() []*abstraction.AbstractMessage {
+	if len(cfg.Messages) > 0 {
+		return cfg.Messages
+	}
+	return fixtures.Messages()
+} //검사할 메시지
+
+func (cfg Config) lossAllowed(field string, report *codec.FidelityReport) bool {
+	if slices.Contains(cfg.AllowLost, field) {
+		return true
+	}
+	return !slices.Contains(RequiredFields, field) && report.Fields[field] == codec.FidelityLost
+} //field 차이를 무시할지(필수 필드는 AllowLost에 있을 때만)
+
+func Run(t *testing.T, cfg Config) {
+	if _, ok := codec.LookupCodec(cfg.Format); !ok {
+		t.Fatalf("format %q is not registered", cfg.Format)
+	}
+	t.Run("RoundTrip", func(t *testing.T) { testRoundTrip(t, cfg) })
+	t.Run("Mutation", func(t *testing.T) { testMutation(t, cfg) })
+	t.Run("Synonyms", func(t *testing.T) { testSynonyms(t, cfg) })
+} //등록된 codec 하나에 round-trip, mutation, 유의어 검사 실행
+
+func roundTrip(t *testing.T, am *abstraction.AbstractMessage, popts codec.ParseOptions, sopts codec.SerializeOptions) ([]byte, *abstraction.AbstractMessage) {
+	t.Helper()
+	data, err := codec.Serialize(am, sopts)
+	if err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	parsed, err := codec.Parse(data, popts)
+	if err != nil {
+		t.Fatalf("Parse: %v\n%q", err, data)
+	}
+	return data, parsed
+} //직렬화 후 다시 parsing(실패 시 중단)
+
+func fidelity(t *testing.T, popts codec.ParseOptions, sopts codec.SerializeOptions) *codec.FidelityReport {
+	t.Helper()
+	report, err := codec.ConversionFidelity(popts, sopts)
+	if err != nil {
+		t.Fatalf("ConversionFidelity: %v", err)
+	}
+	return report
+} //포맷이 유지하는 필드 보고
+
+func fidelityDiff(cfg Config, want, got *abstraction.AbstractMessage, report *codec.FidelityReport) abstraction.Diff {
+	var kept abstraction.Diff
+	for _, c := range want.Diff(got, compareOptions) {
+		field := c.Field()
+		if _, ok := abstraction.ExtraKey(field); ok {
+			field = "Extras"
+		}
+		if !cfg.lossAllowed(field, report) {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+} //손실이 허용된 필드(포맷이 손실을 보고한 비필수 필드, AllowLost)를 제외한 차이
+
+func testRoundTrip(t *testing.T, cfg Config) {
+	popts, sopts := cfg.options()
+	report := fidelity(t, popts, sopts)
+	for i, am := range cfg.messages() {
+		data, parsed := roundTrip(t, am, popts, sopts)
+		if d := fidelityDiff(cfg, am, parsed, report); len(d) > 0 {
+			t.Errorf("message %d: fields changed by round trip:\n%s", i, d)
+		}
+		if parsed.OriginalFormat != string(cfg.Format) {
+			t.Errorf("message %d: OriginalFormat = %q, want %q", i, parsed.OriginalFormat, cfg.Format)
+		}
+		if !bytes.Equal(parsed.RawPayload, data) {
+			t.Errorf("message %d: RawPayload does not hold the parsed bytes", i)
+		}
+		_, again := roundTrip(t, parsed, popts, sopts) //변경 없이 다시 직렬화
+		if d := parsed.Diff(again, abstraction.EqualOptions{IgnoreRawPayload: true}); len(d) > 0 {
+			t.Errorf("message %d: re-serializing an unchanged message is not stable:\n%s", i, d)
+		}
+	}
+} //직렬화 → parsing 후 포맷이 유지하는 필드가 그대로인지 확인
+
+func testMutation(t *testing.T, cfg Config) {
+	popts, sopts := cfg.options()
+	report := fidelity(t, popts, sopts)
+	for i, am := range cfg.messages() {
+		_, parsed := roundTrip(t, am, popts, sopts)
+		orig := parsed.Clone()
+		parsed.Signature = "CORRUPTED_SIG"
+		if parsed.Extras == nil {
+			parsed.Extras = map[string]abstraction.ExtraValue{}
+		}
+		parsed.Extras[injectedExtra] = abstraction.IntExtra(big.NewInt(1))
+		_, mutated := roundTrip(t, parsed, popts, sopts)
+		if !cfg.lossAllowed("Signature", report) && mutated.Signature != "CORRUPTED_SIG" {
+			t.Errorf("message %d: Signature = %q after corruption", i, mutated.Signature)
+		}
+		if !cfg.lossAllowed("Extras", report) {
+			v, ok := mutated.Extras[injectedExtra]
+			switch {
+			case !ok:
+				t.Errorf("message %d: injected Extras entry missing", i)
+			case !v.Equal(abstraction.IntExtra(big.NewInt(1))) && v.Text() != "1":
+				t.Errorf("message %d: injected Extras entry = %s %q", i, v.Kind, v.Text())
+			}
+		}
+		for _, c := range fidelityDiff(cfg, orig, mutated, report) { //바꾼 필드 외에는 그대로
+			if c.Path != "Signature" && c.Path != abstraction.ExtraField(injectedExtra) {
+				t.Errorf("message %d: unexpected change %s: %v -> %v", i, c.Path, c.Old, c.New)
+			}
+		}
+	}
+} //parsing한 메시지를 변경해 직렬화하면 변경만 반영되는지 확인
+
+func testSynonyms(t *testing.T, cfg Config) {
+	popts, sopts := cfg.options()
+	am := cfg.messages()[0].Clone()
+	am.OriginalMsgName = "PrePrepare"
+	am.OriginalFieldNames = map[string]string{"Height": "seq_num", "Signature": "sig", "BlockHash": "block_digest"}
+	preserve := sopts
+	preserve.PreserveOriginalNames = true //유의어 key로 출력
+	_, parsed := roundTrip(t, am, popts, preserve)
+	if d := fidelityDiff(cfg, am, parsed, fidelity(t, popts, preserve)); len(d) > 0 {
+		t.Errorf("synonym keys not normalized:\n%s", d)
+	}
+	for _, name := range codec.VocabularyNames() {
+		vs := sopts
+		vs.Vocabulary = name
+		report := fidelity(t, popts, vs)
+		for i, m := range cfg.messages() {
+			_, parsed := roundTrip(t, m, popts, vs)
+			if d := fidelityDiff(cfg, m, parsed, report); len(d) > 0 {
+				t.Errorf("vocabulary %s, message %d: fields changed:\n%s", name, i, d)
+			}
+		}
+	}
+} //원본 필드명/구현체 어휘로 직렬화한 메시지가 표준 필드로 정규화되는지 확인
@@ -0,0 +1,23 @@
+package codectest
+
+import (
+	"testing"
+
+	"codec/codec"
+	"codec/codec/codectest/fixtures"
+)
+
+func TestRequiredFieldsAsserted(t *testing.T) {
+	want := fixtures.Messages()[0]
+	got := want.Clone()
+	got.Signature = ""
+	got.PrevHash = ""
+	report := &codec.FidelityReport{Fields: map[string]codec.FieldFidelity{"Signature": codec.FidelityLost, "PrevHash": codec.FidelityLost}}
+	d := fidelityDiff(Config{}, want, got, report)
+	if len(d) != 1 || d[0].Field() != "Signature" {
+		t.Fatalf("diff = %v, want only Signature (required even when reported lost)", d)
+	}
+	if d := fidelityDiff(Config{AllowLost: []string{"Signature"}}, want, got, report); len(d) > 0 {
+		t.Fatalf("diff with AllowLost = %v, want none", d)
+	}
+} //codec이 손실을 보고한 필수 필드도 AllowLost로 제외하지 않으면 검사
@@ -0,0 +1,25 @@
+package codectest_test
+
+import (
+	"testing"
+
+	"codec/codec"
+	"codec/codec/codectest"
+)
+
+const protoMessage = "pbft.AbstractMessage" //proto/abstraction.proto의 메시지
+
+func TestBuiltinCodecs(t *testing.T) {
+	if err := codec.RegisterDescriptorSetFile("../../proto/abstraction.protoset"); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range codec.CodecFormats() {
+		cfg := codectest.Config{Format: f}
+		if f == codec.FormatProtobuf {
+			cfg.ParseOptions.ProtoMessageFullName = protoMessage
+			cfg.SerializeOptions.ProtoMessageFullName = protoMessage
+			cfg.SerializeOptions.ProtoDiscardUnknown = true
+		}
+		t.Run(string(f), func(t *testing.T) { codectest.Run(t, cfg) })
+	}
+} //등록된 모든 codec에 conformance suite 실행
@@ -0,0 +1,60 @@
+package fixtures
+
+import (
+	"math/big"
+	"time"
+
+	"codec/abstraction"
+)
+
+func Messages() []*abstraction.AbstractMessage {
+	return []*abstraction.AbstractMessage{
+		{
+			Type:        abstraction.MsgTypeProposal,
+			Height:      big.NewInt(1000),
+			Round:       big.NewInt(2),
+			View:        big.NewInt(0),
+			Timestamp:   time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+			BlockHash:   "0xdeadbeef",
+			PrevHash:    "0xfeedbead",
+			Proposer:    "node 1",
+			Validator:   "node 1",
+			Signature:   "SIG_ORIG",
+			CommitSeals: []string{"seal A", "seal B"},
+			ViewChanges: []abstraction.ViewChangeEntry{
+				{View: big.NewInt(1), Height: big.NewInt(1000), Validator: "node 2", Signature: "vc_sig"},
+			},
+			Extras: map[string]abstraction.ExtraValue{
+				"payload": abstraction.StringExtra("hello"),
+				"nonce":   abstraction.IntExtra(big.NewInt(7)),
+			},
+		}, //모든 표준 필드
+		{
+			Type:      abstraction.MsgTypeCommit,
+			Height:    big.NewInt(1),
+			Validator: "v,1=(x)", //구분자가 든 값
+			Signature: "0xab",
+			Extras: map[string]abstraction.ExtraValue{
+				"flags": {Kind: abstraction.ExtraList, List: []abstraction.ExtraValue{abstraction.BoolExtra(true), abstraction.StringExtra("b")}},
+			},
+		}, //일부 필드, 중첩 Extras
+		{Type: abstraction.MsgTypeViewChange, View: big.NewInt(3)}, //필드 대부분 생략
+	}
+} //모든 내장 포맷이 손실 없이 담을 수 있는 메시지(호출마다 새로 생성)
+
+func Edge() []*abstraction.AbstractMessage {
+	return []*abstraction.AbstractMessage{
+		{
+			Type:   abstraction.MsgTypeCommit,
+			Height: new(big.Int).Lsh(big.NewInt(1), 70), //고정 폭 정수 범위 밖
+			Extras: map[string]abstraction.ExtraValue{
+				"nested": {Kind: abstraction.ExtraMap, Map: map[string]abstraction.ExtraValue{
+					"list": {Kind: abstraction.ExtraList, List: []abstraction.ExtraValue{abstraction.BoolExtra(true), abstraction.NullExtra()}},
+				}},
+				"quoted": abstraction.StringExtra(`a,b=(c) "d"`),
+				"raw":    abstraction.BytesExtra([]byte{0, 1, 0xff}),
+				"ratio":  abstraction.FloatExtra(0.25),
+			},
+		}, //중첩 Extras, 구분자가 든 문자열
+	}
+} //일부 포맷이 담지 못하는 값(직렬화 실패나 손실을 허용하는 검사용)
@@ -0,0 +1,67 @@
+package codectest_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"codec/abstraction"
+	"codec/codec"
+	"codec/codec/codectest"
+)
+
+const formatHexJSON codec.Format = "hexjson" //JSON을 hex 텍스트로 감싼 외부 포맷
+
+type hexJSONCodec struct{} //외부 codec 예시
+
+func (hexJSONCodec) Parse(data []byte, opts codec.ParseOptions) (*abstraction.AbstractMessage, error) {
+	js, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, &codec.ParseError{Format: formatHexJSON, Offset: -1, Err: err}
+	}
+	opts.Format = codec.FormatJSON
+	am, err := codec.Parse(js, opts)
+	if err != nil {
+		return nil, err
+	}
+	am.OriginalFormat = string(formatHexJSON)
+	return am, nil
+} //hex 해제 후 JSON으로 parsing
+
+func (hexJSONCodec) Serialize(am *abstraction.AbstractMessage, opts codec.SerializeOptions) ([]byte, error) {
+	opts.Format = codec.FormatJSON
+	js, err := codec.Serialize(am, opts)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(hex.EncodeToString(js)), nil
+} //JSON으로 직렬화 후 hex 인코딩
+
+func TestRegisteredCodec(t *testing.T) {
+	codec.RegisterCodec(formatHexJSON, hexJSONCodec{})
+	codectest.Run(t, codectest.Config{Format: formatHexJSON})
+} //RegisterCodec로 등록한 외부 codec도 같은 suite 실행
+
+const formatNoSig codec.Format = "nosigjson" //Signature를 싣지 않는 외부 포맷
+
+type noSigCodec struct{ hexJSONCodec } //Signature를 버리는 codec
+
+func (c noSigCodec) Parse(data []byte, opts codec.ParseOptions) (*abstraction.AbstractMessage, error) {
+	am, err := c.hexJSONCodec.Parse(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	am.OriginalFormat = string(formatNoSig)
+	return am, nil
+} //hexjson으로 parsing
+
+func (c noSigCodec) Serialize(am *abstraction.AbstractMessage, opts codec.SerializeOptions) ([]byte, error) {
+	cp := am.Clone()
+	cp.Signature = ""
+	return c.hexJSONCodec.Serialize(cp, opts)
+} //Signature를 비우고 직렬화
+
+func TestRegisteredCodecAllowLost(t *testing.T) {
+	codec.RegisterCodec(formatNoSig, noSigCodec{})
+	codectest.Run(t, codectest.Config{Format: formatNoSig, AllowLost: []string{"Signature"}})
+} //필수 필드를 싣지 않는 codec은 AllowLost로 명시해야 suite 통과
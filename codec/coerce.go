@@ -0,0 +1,342 @@
+package codec
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type HashEncoding string
+
+const (
+	HashHex      HashEncoding = "hex"      //소문자 0x-hex(기본값)
+	HashBase64   HashEncoding = "base64"   //표준 base64(패딩 포함)
+	HashPreserve HashEncoding = "preserve" //문자열은 원문 그대로, 바이트만 0x-hex로
+)
+
+type coercer struct {
+	enc    HashEncoding //해시/서명 필드의 표준 텍스트 형태
+	base64 bool         //짧은 문자열과 정수 값도 base64 여부 추정(DetectBase64)
+} //포맷별 원시 값(JSON 수/문자열, msgpack 정수/bin, protobuf bytes 등) -> 표준 필드 값 변환
+
+func newCoercer(opts ParseOptions) coercer {
+	enc := opts.HashEncoding
+	if enc == "" {
+		enc = HashHex
+	}
+	return coercer{enc: enc, base64: opts.DetectBase64}
+} //ParseOptions의 해시 표현/추정 설정으로 변환기 생성
+
+func (c coercer) bigInt(v interface{}) *big.Int {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case float64: //msgpack/YAML 실수
+		return floatInt(t)
+	case float32:
+		return floatInt(float64(t))
+	case json.Number: //UseNumber 사용 시
+		return c.bigInt(t.String())
+	case int:
+		return big.NewInt(int64(t))
+	case int8:
+		return big.NewInt(int64(t))
+	case int16:
+		return big.NewInt(int64(t))
+	case int32:
+		return big.NewInt(int64(t))
+	case int64:
+		return big.NewInt(t)
+	case uint:
+		return new(big.Int).SetUint64(uint64(t))
+	case uint8:
+		return new(big.Int).SetUint64(uint64(t))
+	case uint16:
+		return new(big.Int).SetUint64(uint64(t))
+	case uint32:
+		return new(big.Int).SetUint64(uint64(t))
+	case uint64:
+		return new(big.Int).SetUint64(t)
+	case []byte: //big-endian 부호 없는 정수(RLP 등)
+		return new(big.Int).SetBytes(t)
+	case string:
+		return c.parseInt(t)
+	}
+	return nil //변환 실패 시 nil
+} //정수 표현(10진수, 0x-hex, base64 big-endian, 바이트)을 *big.Int로 변환
+
+func (c coercer) parseInt(s string) *big.Int {
+	if x, ok := smallDecimal(s); ok { //int64에 들어가는 10진수는 big.Int.SetString 없이
+		return big.NewInt(x)
+	}
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	body := strings.TrimPrefix(s, "-")
+	if h, ok := cutHexPrefix(body); ok { //"0x3e8"
+		x, ok := new(big.Int).SetString(h, 16)
+		if !ok {
+			return nil
+		}
+		if neg {
+			x.Neg(x)
+		}
+		return x
+	}
+	if x, ok := new(big.Int).SetString(s, 10); ok { //"1000"
+		return x
+	}
+	if isDecimalNotation(s) {
+		if r, ok := new(big.Rat).SetString(s); ok && r.IsInt() { //"1e3", "1000.0" 등 정수값의 지수/소수 표기
+			return new(big.Int).Set(r.Num())
+		}
+	}
+	if b, ok := c.decodeBase64(s); ok { //"A+g=" (DetectBase64일 때만)
+		return new(big.Int).SetBytes(b)
+	}
+	return nil
+} //정수 문자열 parsing(0x/0X 접두사는 16진수, 그 외 10진수, base64는 옵션)
+
+func (c coercer) hash(v interface{}) string {
+	switch t := v.(type) {
+	case []byte: //msgpack bin, protobuf bytes 등 원시 바이트
+		return c.encode(t)
+	case string:
+		return c.hashText(t)
+	}
+	return c.text(v)
+} //해시/서명 값을 표준 텍스트 형태로 정규화
+
+func (c coercer) hashText(s string) string {
+	if c.enc == HashPreserve || (c.enc != HashBase64 && isCanonicalHex(s)) { //이미 표준 형태일 시 decode/encode 생략
+		return s
+	}
+	if b, ok := c.decodeHash(s); ok {
+		return c.encode(b)
+	}
+	return s //해시 형태가 아닌 문자열은 그대로
+} //해시 문자열 정규화(interface{}로 감싸지 않음)
+
+func (c coercer) decodeHash(s string) ([]byte, bool) {
+	if h, ok := cutHexPrefix(s); ok { //0x 접두사 hex
+		b, err := hex.DecodeString(h)
+		return b, err == nil && len(b) > 0
+	}
+	if len(s) >= 16 && isHex(s) { //접두사 없는 hex(Tendermint 대문자 해시 등), 짧은 값은 식별자로 간주
+		b, err := hex.DecodeString(s)
+		return b, err == nil
+	}
+	if c.base64 {
+		return c.decodeBase64(s)
+	}
+	if len(s) < minBase64Hash || len(s)%4 != 0 { //hex로 읽히는 값은 위에서 처리됨
+		return nil, false
+	}
+	b, err := base64.StdEncoding.DecodeString(s) //protojson bytes, CometBFT JSON 서명 등
+	return b, err == nil
+} //해시 문자열 표현(0x-hex, hex, base64)을 바이트로 변환
+
+const minBase64Hash = 24 //DetectBase64 없이 base64로 읽는 해시 문자열의 최소 길이(16바이트 이상, 짧은 값은 식별자로 간주)
+
+func (c coercer) decodeBase64(s string) ([]byte, bool) {
+	if !c.base64 || len(s) < 4 {
+		return nil, false
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil && len(b) > 0 {
+			return b, true
+		}
+	}
+	return nil, false
+} //DetectBase64일 때 표준/URL-safe, 패딩 유무의 base64 시도
+
+func (c coercer) encode(b []byte) string {
+	if c.enc == HashBase64 {
+		return base64.StdEncoding.EncodeToString(b)
+	}
+	var out strings.Builder //"0x"와 hex를 한 번에 할당(String()은 복사 없음)
+	out.Grow(2 + hex.EncodedLen(len(b)))
+	out.WriteString("0x")
+	for _, x := range b {
+		out.WriteByte(hexDigits[x>>4])
+		out.WriteByte(hexDigits[x&0x0f])
+	}
+	return out.String()
+} //바이트를 표준 텍스트 형태로 인코딩
+
+const hexDigits = "0123456789abcdef"
+
+type textBlock struct {
+	b strings.Builder
+} //여러 문자열 값을 한 번에 할당(값마다 string 변환의 할당 대신, 이미 쓴 부분은 바뀌지 않음)
+
+func newTextBlock(n int) *textBlock {
+	t := new(textBlock)
+	t.b.Grow(n)
+	return t
+} //n바이트 분량의 블록
+
+func (t *textBlock) text(raw []byte) string {
+	start := t.b.Len()
+	t.b.Write(raw)
+	return t.b.String()[start:]
+} //string(raw)와 같은 값
+
+func (c coercer) hashBytes(t *textBlock, raw []byte) string {
+	if c.enc == HashPreserve || (c.enc != HashBase64 && isCanonicalHex(raw)) {
+		return t.text(raw)
+	}
+	return c.hashText(string(raw))
+} //c.hashText(string(raw))와 같은 값(표준 형태는 블록에서)
+
+func (c coercer) encodeIn(t *textBlock, raw []byte) string {
+	if c.enc == HashBase64 {
+		return c.encode(raw)
+	}
+	start := t.b.Len()
+	t.b.WriteString("0x")
+	for _, x := range raw {
+		t.b.WriteByte(hexDigits[x>>4])
+		t.b.WriteByte(hexDigits[x&0x0f])
+	}
+	return t.b.String()[start:]
+} //c.encode(raw)와 같은 값(hex는 블록에서)
+
+func (c coercer) text(v interface{}) string {
+	switch t := v.(type) {
+	case nil: //nil일 시 빈 문자열
+		return ""
+	case string:
+		return t
+	case []byte: //바이트에는 텍스트 형태가 없으므로 해시와 같은 인코딩
+		return c.encode(t)
+	case json.Number:
+		return t.String()
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	}
+	if x := c.bigInt(v); x != nil { //msgpack 등의 정수 타입
+		return x.String()
+	}
+	b, _ := json.Marshal(v)
+	return string(b) //JSON으로 직렬화한 바이트를 문자열로 반환
+} //scalar 값을 문자열로 변환
+
+func (c coercer) hashes(v interface{}) []string {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case []interface{}: //["a","b",...] 형태
+		out := make([]string, 0, len(t))
+		for _, e := range t {
+			out = append(out, c.hash(e)) //각 원소를 정규화
+		}
+		return out
+	case []string:
+		out := make([]string, 0, len(t))
+		for _, e := range t {
+			out = append(out, c.hash(e))
+		}
+		return out
+	}
+	return []string{c.hash(v)}
+} //해시/서명 목록(commit seal 등)을 정규화된 []string으로 변환
+
+func (c coercer) time(v interface{}) time.Time {
+	switch t := v.(type) {
+	case time.Time: //protobuf Timestamp, msgpack timestamp ext
+		return t.UTC()
+	case map[string]interface{}: //{seconds, nanos}
+		if tm, ok := secondsNanos(t); ok {
+			return tm
+		}
+		return time.Time{}
+	case string:
+		if tm, err := time.Parse(time.RFC3339Nano, t); err == nil { //RFC3339(소수점 이하 초 포함)
+			return tm
+		}
+	}
+	if r := epochRat(v); r != nil { //epoch s/ms/µs/ns(크기로 단위 판별)
+		return epochTime(r)
+	}
+	return time.Time{} // 변환 실패 시 zero time
+} //interface{} 값을 time.Time으로 변환
+
+func smallDecimal(s string) (int64, bool) {
+	neg := len(s) > 0 && s[0] == '-'
+	digits := s
+	if neg {
+		digits = s[1:]
+	}
+	if len(digits) == 0 || len(digits) > 18 { //18자리까지는 int64 범위
+		return 0, false
+	}
+	var x int64
+	for i := 0; i < len(digits); i++ {
+		d := digits[i] - '0'
+		if d > 9 {
+			return 0, false
+		}
+		x = x*10 + int64(d)
+	}
+	if neg {
+		x = -x
+	}
+	return x, true
+} //부호와 18자리 이하 숫자로만 된 10진수
+
+func isDecimalNotation(s string) bool {
+	if strings.ContainsRune(s, '/') { //big.Rat의 분수 표기는 제외
+		return false
+	}
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		return err == nil && e >= -maxDecimalExponent && e <= maxDecimalExponent //거대한 지수로 인한 메모리 폭증 방지
+	}
+	return strings.ContainsRune(s, '.')
+} //지수/소수 표기 수인지 확인
+
+const maxDecimalExponent = 400 //지수 표기 정수의 최대 지수(uint256 이상을 충분히 포함)
+
+func floatInt(f float64) *big.Int {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil
+	}
+	x, _ := new(big.Float).SetFloat64(f).Int(nil) //2^53 이상도 float 값 그대로(int64 변환 없이)
+	return x
+} //실수의 정수 부분을 *big.Int로 변환
+
+func cutHexPrefix(s string) (string, bool) {
+	if len(s) > 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') && isHex(s[2:]) {
+		return s[2:], true
+	}
+	return "", false
+} //"0x.." 형태일 시 접두사를 뗀 hex 숫자 반환
+
+func isCanonicalHex[T string | []byte](s T) bool {
+	if len(s) < 4 || len(s)%2 != 0 || s[0] != '0' || s[1] != 'x' {
+		return false
+	}
+	for i := 2; i < len(s); i++ {
+		if c := s[i]; !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+} //소문자 "0x" + 짝수 길이 소문자 hex(HashHex의 표준 형태)
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return s != ""
+} //s가 16진수 숫자로만 구성되는지 확인
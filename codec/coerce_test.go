@@ -0,0 +1,41 @@
+package codec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCoerceHash(t *testing.T) {
+	fb := "0x" + strings.Repeat("fb", 18)
+	cases := []struct {
+		in, want, withDetect string //기본값, DetectBase64일 때 결과
+	}{
+		{"0xABCD", "0xabcd", "0xabcd"},
+		{"ABCDEF0123456789", "0xabcdef0123456789", "0xabcdef0123456789"}, //16자 이상 hex
+		{"ABCDEF01", "ABCDEF01", "0x001083105d35"},                       //짧은 hex는 식별자(옵션일 때는 base64로 읽힘)
+		{"AAECAwQFBgcICQoLDA0ODw==", "0x000102030405060708090a0b0c0d0e0f", "0x000102030405060708090a0b0c0d0e0f"},
+		{"+/v7+/v7+/v7+/v7+/v7+/v7", fb, fb},
+		{"-_v7-_v7-_v7-_v7-_v7-_v7", "-_v7-_v7-_v7-_v7-_v7-_v7", fb},                               //URL-safe는 옵션일 때만
+		{"AQID", "AQID", "0x010203"},                                                               //짧은 base64는 옵션일 때만
+		{"AAECAwQFBgcICQoLDA0ODw", "AAECAwQFBgcICQoLDA0ODw", "0x000102030405060708090a0b0c0d0e0f"}, //패딩 없음
+		{"validator-signature-01", "validator-signature-01", "0xbda96275ab68afeb228276adbab7bed3"}, //옵션일 때는 URL-safe base64로 읽힘
+		{"SIG", "SIG", "SIG"},
+	}
+	for _, tc := range cases {
+		if got := newCoercer(ParseOptions{}).hash(tc.in); got != tc.want {
+			t.Errorf("hash(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+		if got := newCoercer(ParseOptions{DetectBase64: true}).hash(tc.in); got != tc.withDetect {
+			t.Errorf("hash(%q) with DetectBase64 = %q, want %q", tc.in, got, tc.withDetect)
+		}
+	}
+	if got := newCoercer(ParseOptions{HashEncoding: HashBase64}).hash("0x000102030405060708090a0b0c0d0e0f"); got != "AAECAwQFBgcICQoLDA0ODw==" {
+		t.Errorf("HashBase64 output = %q", got)
+	}
+	if got := newCoercer(ParseOptions{}).parseInt("A+g="); got != nil {
+		t.Errorf("parseInt(A+g=) without DetectBase64 = %v, want nil", got)
+	}
+	if got := newCoercer(ParseOptions{DetectBase64: true}).parseInt("A+g="); got == nil || got.Int64() != 1000 {
+		t.Errorf("parseInt(A+g=) with DetectBase64 = %v, want 1000", got)
+	}
+} //해시 문자열 정규화: 24자 이상 표준 base64는 기본 감지, 짧은 값/URL-safe/패딩 없음/정수는 DetectBase64일 때만
@@ -0,0 +1,147 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+type Compression string
+
+const (
+	CompressionAuto        Compression = ""             //Parse: frame magic으로 감지(snappy frame, zstd), Serialize: 압축 안 함
+	CompressionNone        Compression = "none"         //압축 안 함(감지도 안 함)
+	CompressionSnappy      Compression = "snappy"       //snappy frame 포맷(libp2p gossipsub 등)
+	CompressionSnappyBlock Compression = "snappy-block" //snappy block 포맷(devp2p 등, magic 없음)
+	CompressionZstd        Compression = "zstd"         //zstd frame
+)
+
+const DefaultMaxDecompressedSize = 16 << 20 //압축 해제 결과 기본 상한(16 MiB)
+
+var (
+	snappyMagic = []byte("\xff\x06\x00\x00sNaPpY") //snappy frame 포맷의 stream identifier chunk
+	zstdMagic   = []byte{0x28, 0xb5, 0x2f, 0xfd}   //zstd frame magic(little-endian 0xFD2FB528)
+)
+
+var (
+	zstdEncOnce sync.Once
+	zstdEnc     *zstd.Encoder //EncodeAll은 동시 호출 가능
+	zstdEncErr  error
+)
+
+func DetectCompression(data []byte) Compression {
+	switch {
+	case bytes.HasPrefix(data, snappyMagic):
+		return CompressionSnappy
+	case bytes.HasPrefix(data, zstdMagic):
+		return CompressionZstd
+	}
+	return CompressionNone //snappy block은 magic이 없어 명시해야 함
+} //frame magic으로 압축 포맷 감지
+
+func decompress(ctx context.Context, data []byte, c Compression, limit int) ([]byte, error) {
+	if c == CompressionAuto {
+		c = DetectCompression(data)
+	}
+	if limit <= 0 {
+		limit = DefaultMaxDecompressedSize
+	}
+	var out []byte
+	var err error
+	switch c {
+	case CompressionNone:
+		return data, nil
+	case CompressionSnappyBlock:
+		var n int
+		if n, err = snappy.DecodedLen(data); err == nil { //header의 길이로 미리 확인
+			if n > limit {
+				return nil, &ParseError{Format: Format(c), Offset: -1, Err: fmt.Errorf("%w: decompressed size %d (limit %d)", ErrMessageTooLarge, n, limit)}
+			}
+			out, err = snappy.Decode(nil, data)
+		}
+	case CompressionSnappy:
+		out, err = readLimited(ctxReader{ctx, snappy.NewReader(bytes.NewReader(data))}, limit)
+	case CompressionZstd:
+		var dec *zstd.Decoder
+		dec, err = zstd.NewReader(bytes.NewReader(data), zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(uint64(limit)))
+		if err == nil {
+			out, err = readLimited(ctxReader{ctx, dec}, limit)
+			dec.Close()
+		}
+	default:
+		return nil, &ParseError{Format: Format(c), Offset: -1, Err: fmt.Errorf("%w: compression %q", ErrUnsupportedFormat, string(c))}
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) { //읽는 중 취소
+			return nil, &ParseError{Format: Format(c), Offset: -1, Err: err}
+		}
+		if errors.Is(err, ErrMessageTooLarge) {
+			return nil, &ParseError{Format: Format(c), Offset: -1, Err: err}
+		}
+		if errors.Is(err, zstd.ErrDecoderSizeExceeded) || errors.Is(err, zstd.ErrWindowSizeExceeded) {
+			return nil, &ParseError{Format: Format(c), Offset: -1, Err: fmt.Errorf("%w: %w (limit %d)", ErrMessageTooLarge, err, limit)}
+		}
+		if isTruncation(err) {
+			err = truncated(err)
+		}
+		return nil, &ParseError{Format: Format(c), Offset: -1, Err: fmt.Errorf("%w: %w", ErrDecompress, err)}
+	}
+	return out, nil
+} //압축 해제(결과가 limit 초과 시 ErrMessageTooLarge)
+
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+} //Read마다 취소 확인
+
+func (c ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+} //취소 시 ctx.Err()
+
+func readLimited(r io.Reader, limit int) ([]byte, error) {
+	out, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > limit { //한 바이트라도 더 나오면 초과
+		return nil, fmt.Errorf("%w: decompressed size exceeds limit %d", ErrMessageTooLarge, limit)
+	}
+	return out, nil
+} //limit 바이트까지만 읽음(decompression bomb 방지)
+
+func compress(data []byte, c Compression) ([]byte, error) {
+	switch c {
+	case CompressionAuto, CompressionNone:
+		return data, nil
+	case CompressionSnappyBlock:
+		return snappy.Encode(nil, data), nil
+	case CompressionSnappy:
+		var buf bytes.Buffer
+		w := snappy.NewBufferedWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case CompressionZstd:
+		zstdEncOnce.Do(func() {
+			zstdEnc, zstdEncErr = zstd.NewWriter(nil)
+		})
+		if zstdEncErr != nil {
+			return nil, zstdEncErr
+		}
+		return zstdEnc.EncodeAll(data, nil), nil
+	}
+	return nil, fmt.Errorf("%w: compression %q", ErrUnsupportedFormat, string(c))
+} //직렬화 결과 압축
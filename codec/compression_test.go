@@ -0,0 +1,213 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+
+	"codec/abstraction"
+)
+
+var testCompressions = []Compression{CompressionSnappy, CompressionSnappyBlock, CompressionZstd}
+
+func compressed(t *testing.T, data []byte, c Compression) []byte {
+	t.Helper()
+	out, err := compress(data, c)
+	if err != nil {
+		t.Fatalf("compress %s: %v", c, err)
+	}
+	return out
+} //테스트 입력 압축
+
+func TestCompressionRoundTrip(t *testing.T) {
+	fuzzSetup(t)
+	for _, c := range testCompressions {
+		for _, format := range []Format{FormatJSON, FormatProtobuf, FormatMsgPack, FormatRLP} {
+			for i, am := range fuzzFixtures() {
+				sopts := fuzzSerializeOptions(format)
+				plain, err := Serialize(am, sopts)
+				if err != nil {
+					continue //대상 포맷에 담을 수 없는 fixture
+				}
+				sopts.Compression = c
+				data, err := Serialize(am, sopts)
+				if err != nil {
+					t.Fatalf("%s/%s fixture %d: %v", c, format, i, err)
+				}
+				if bytes.Equal(data, plain) {
+					t.Errorf("%s/%s fixture %d: Serialize did not compress", c, format, i)
+				}
+				popts := fuzzParseOptions(format)
+				popts.Compression = c
+				got, err := Parse(data, popts)
+				if err != nil {
+					t.Fatalf("%s/%s fixture %d: %v", c, format, i, err)
+				}
+				want, err := Parse(plain, fuzzParseOptions(format))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := got.Diff(want, abstraction.EqualOptions{IgnoreRawPayload: true}); len(diff) > 0 {
+					t.Errorf("%s/%s fixture %d: %s", c, format, i, diff)
+				}
+				if c == CompressionSnappyBlock {
+					continue //magic이 없어 자동 감지 안 됨
+				}
+				got, err = Parse(data, fuzzParseOptions(format)) //CompressionAuto
+				if err != nil {
+					t.Fatalf("%s/%s fixture %d with auto detection: %v", c, format, i, err)
+				}
+				if diff := got.Diff(want, abstraction.EqualOptions{IgnoreRawPayload: true}); len(diff) > 0 {
+					t.Errorf("%s/%s fixture %d with auto detection: %s", c, format, i, diff)
+				}
+			}
+		}
+	}
+} //압축 포맷별로 Serialize→Parse 결과가 압축 안 한 것과 같음
+
+func TestDetectCompression(t *testing.T) {
+	msg := []byte(`{"type":"Commit","height":1}`)
+	cases := []struct {
+		name string
+		in   []byte
+		want Compression
+	}{
+		{"empty", nil, CompressionNone},
+		{"plain json", msg, CompressionNone},
+		{"snappy frame", compressed(t, msg, CompressionSnappy), CompressionSnappy},
+		{"zstd", compressed(t, msg, CompressionZstd), CompressionZstd},
+		{"snappy block", compressed(t, msg, CompressionSnappyBlock), CompressionNone}, //magic 없음
+		{"snappy block of a snappy-looking payload", snappy.Encode(nil, compressed(t, msg, CompressionSnappy)), CompressionNone},
+		{"partial snappy magic", []byte("\xff\x06\x00\x00sNa"), CompressionNone},
+		{"partial zstd magic", []byte{0x28, 0xb5, 0x2f}, CompressionNone},
+	}
+	for _, tc := range cases {
+		if got := DetectCompression(tc.in); got != tc.want {
+			t.Errorf("%s: DetectCompression = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+	block := compressed(t, msg, CompressionSnappyBlock)
+	if am, err := Parse(block, ParseOptions{Format: FormatJSON}); err == nil {
+		t.Errorf("snappy block without CompressionSnappyBlock parsed as %+v", am)
+	}
+	if _, err := Parse(msg, ParseOptions{Format: FormatJSON, Compression: CompressionNone}); err != nil {
+		t.Errorf("CompressionNone: %v", err)
+	}
+} //frame magic 감지(snappy block은 명시해야 함)
+
+func TestDecompressLimit(t *testing.T) {
+	data := bytes.Repeat([]byte("a"), 1<<16) //압축률이 높은 입력(작은 bomb)
+	for _, c := range testCompressions {
+		z := compressed(t, data, c)
+		if out, err := decompress(context.Background(), z, c, len(data)); err != nil || !bytes.Equal(out, data) {
+			t.Errorf("%s: output of exactly the limit = %d bytes, %v", c, len(out), err)
+		}
+		_, err := decompress(context.Background(), z, c, len(data)-1)
+		var pe *ParseError
+		if !errors.Is(err, ErrMessageTooLarge) || !errors.As(err, &pe) {
+			t.Errorf("%s: output one byte over the limit = %v, want ParseError wrapping ErrMessageTooLarge", c, err)
+		}
+		_, err = Parse(z, ParseOptions{Format: FormatJSON, Compression: c, MaxDecompressedSize: 1 << 10})
+		if !errors.Is(err, ErrMessageTooLarge) {
+			t.Errorf("%s: Parse over MaxDecompressedSize = %v", c, err)
+		}
+		_, err = Parse(z, ParseOptions{Format: FormatJSON, Compression: c, Limits: Limits{MaxBytes: 1 << 10}})
+		if !errors.Is(err, ErrMessageTooLarge) {
+			t.Errorf("%s: Parse over Limits.MaxBytes (no MaxDecompressedSize) = %v", c, err)
+		}
+	}
+	claim := append(snappyUvarint(1<<30), 0x00) //header만 1 GiB, 본문은 1바이트
+	if _, err := decompress(context.Background(), claim, CompressionSnappyBlock, 1<<20); !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("snappy block claiming 1 GiB = %v, want ErrMessageTooLarge from the DecodedLen check", err)
+	}
+	var stream bytes.Buffer //frame header에 크기가 없는 zstd stream
+	w, err := zstd.NewWriter(&stream, zstd.WithWindowSize(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 16 {
+		w.Write(data)
+	}
+	w.Close()
+	if _, err := decompress(context.Background(), stream.Bytes(), CompressionZstd, 1<<18); !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("zstd stream of 1 MiB with limit 256 KiB = %v, want ErrMessageTooLarge", err)
+	}
+	if _, err := decompress(context.Background(), data, CompressionNone, 1); err != nil {
+		t.Errorf("CompressionNone is not limited here (Limits.MaxBytes checks the input): %v", err)
+	}
+} //압축 포맷별 결과 크기 상한(snappy block은 header 길이로 미리, zstd는 window 메모리 상한도)
+
+func snappyUvarint(n uint64) []byte {
+	var b []byte
+	for ; n >= 0x80; n >>= 7 {
+		b = append(b, byte(n)|0x80)
+	}
+	return append(b, byte(n))
+} //snappy block header(해제 후 길이)
+
+func TestDecompressCorrupt(t *testing.T) {
+	data := bytes.Repeat([]byte(`{"type":"Commit","height":1}`), 64)
+	for _, c := range testCompressions {
+		z := compressed(t, data, c)
+		for _, cut := range []int{len(z) - 1, len(z) / 2, len(z) - len(z)/4} {
+			_, err := decompress(context.Background(), z[:cut], c, 0)
+			var pe *ParseError
+			if !errors.Is(err, ErrDecompress) || !errors.As(err, &pe) || pe.Offset != -1 {
+				t.Errorf("%s cut to %d of %d bytes = %v, want ParseError wrapping ErrDecompress", c, cut, len(z), err)
+			}
+		}
+	}
+	corrupt := map[Compression][]byte{
+		CompressionSnappy:      append(compressed(t, data, CompressionSnappy)[:len(snappyMagic)], 0x01, 0x05, 0x00, 0x00, 0, 0, 0, 0, 'x'), //CRC 불일치 chunk
+		CompressionSnappyBlock: {0x05, 0xff, 0xff, 0xff},
+		CompressionZstd:        append(append([]byte(nil), zstdMagic...), 0xff, 0xff, 0xff, 0xff),
+	}
+	for c, z := range corrupt {
+		if _, err := decompress(context.Background(), z, c, 0); !errors.Is(err, ErrDecompress) {
+			t.Errorf("corrupt %s = %v, want ErrDecompress", c, err)
+		}
+	}
+	z := compressed(t, data, CompressionZstd)
+	if _, err := decompress(context.Background(), z[:len(z)/2], CompressionAuto, 0); !errors.Is(err, ErrTruncatedInput) {
+		t.Errorf("zstd cut in half = %v, want ErrTruncatedInput as well (snappy reports truncation as corrupt input)", err)
+	}
+	if _, err := Parse(data, ParseOptions{Compression: "gzip"}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Parse with an unknown compression = %v, want ErrUnsupportedFormat", err)
+	}
+	if _, err := Serialize(&abstraction.AbstractMessage{}, SerializeOptions{Compression: "gzip"}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Serialize with an unknown compression = %v, want ErrUnsupportedFormat", err)
+	}
+} //잘리거나 손상된 압축 입력은 ErrDecompress, 모르는 압축 이름은 ErrUnsupportedFormat
+
+type cancelAfter struct {
+	context.Context
+	n int //취소 전까지 Err()가 nil을 반환하는 횟수
+} //decompress 도중 취소되는 context
+
+func (c *cancelAfter) Err() error {
+	if c.n <= 0 {
+		return context.Canceled
+	}
+	c.n--
+	return nil
+} //n번째 확인 후 취소
+
+func TestDecompressCancel(t *testing.T) {
+	data := bytes.Repeat([]byte("consensus "), 1<<14)
+	for _, c := range []Compression{CompressionSnappy, CompressionZstd} {
+		z := compressed(t, data, c)
+		_, err := decompress(&cancelAfter{Context: context.Background(), n: 2}, z, c, 0)
+		var pe *ParseError
+		if !errors.Is(err, context.Canceled) || !errors.As(err, &pe) || errors.Is(err, ErrDecompress) {
+			t.Errorf("%s cancelled while reading = %v, want ParseError wrapping context.Canceled", c, err)
+		}
+		_, err = ParseContext(&cancelAfter{Context: context.Background(), n: 3}, z, ParseOptions{Format: FormatJSON, Compression: c})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: ParseContext cancelled during decompression = %v", c, err)
+		}
+	}
+} //읽는 중 취소되면 ErrDecompress가 아닌 ctx.Err()
@@ -0,0 +1,118 @@
+package codec
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"codec/abstraction"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+type blockingProvider struct{ ProtoDescriptorProvider } //ctx가 끝날 때까지 응답하지 않는 원격 registry 흉내
+
+func (blockingProvider) FindMessageByNameContext(ctx context.Context, fn protoreflect.FullName) (protoreflect.MessageDescriptor, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+} //ctx 종료 시 ctx.Err()
+
+type ctxKey struct{}
+
+type ctxCodec struct{ seen chan interface{} } //받은 ctx의 값을 기록하는 ContextCodec
+
+func (c ctxCodec) ParseContext(ctx context.Context, data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error) {
+	c.seen <- ctx.Value(ctxKey{})
+	return &abstraction.AbstractMessage{Type: abstraction.MsgTypeCommit}, nil
+} //ctx 값 기록
+
+func (c ctxCodec) SerializeContext(ctx context.Context, am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error) {
+	c.seen <- ctx.Value(ctxKey{})
+	return []byte("ok"), nil
+} //ctx 값 기록
+
+func TestParseContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := ParseContext(ctx, []byte(`{"type":"Commit"}`), ParseOptions{Format: FormatJSON})
+	var pe *ParseError
+	if !errors.Is(err, context.Canceled) || !errors.As(err, &pe) {
+		t.Fatalf("err = %v, want ParseError wrapping context.Canceled", err)
+	}
+	if _, err := SerializeContext(ctx, &abstraction.AbstractMessage{Type: abstraction.MsgTypeCommit}, SerializeOptions{Format: FormatJSON}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("SerializeContext err = %v, want context.Canceled", err)
+	}
+} //시작 전 취소
+
+func TestProtoDescriptorLookupDeadline(t *testing.T) {
+	fuzzSetup(t)
+	data, err := Serialize(benchMessage(1, 1, 1), fuzzSerializeOptions(FormatProtobuf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := blockingProvider{DefaultDescriptorRegistry}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = ParseContext(ctx, data, ParseOptions{Format: FormatProtobuf, ProtoMessageFullName: fuzzProtoMessage, DescriptorProvider: provider})
+	var pe *ParseError
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.As(err, &pe) || errors.Is(err, ErrDescriptorNotFound) {
+		t.Fatalf("ParseContext err = %v, want ParseError wrapping context.DeadlineExceeded", err)
+	}
+	_, err = SerializeContext(ctx, benchMessage(0, 0, 0), SerializeOptions{Format: FormatProtobuf, ProtoMessageFullName: fuzzProtoMessage, DescriptorProvider: provider})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("SerializeContext err = %v, want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("lookup was not cancelled (%v)", d)
+	}
+	if _, err := Parse(data, ParseOptions{Format: FormatProtobuf, ProtoMessageFullName: fuzzProtoMessage}); err != nil { //기존 API는 그대로
+		t.Fatal(err)
+	}
+} //ContextDescriptorProvider 조회에 ctx 전달
+
+func TestContextCodecAdapters(t *testing.T) {
+	c := ctxCodec{seen: make(chan interface{}, 2)}
+	if got := WithContext(WithoutContext(c)); got != ContextCodec(c) {
+		t.Errorf("WithContext(WithoutContext(c)) = %#v, want c", got)
+	}
+	jc, _ := LookupCodec(FormatJSON)
+	if got := WithoutContext(WithContext(jc)); got != jc {
+		t.Errorf("WithoutContext(WithContext(json)) = %#v, want json codec", got)
+	}
+	const format Format = "ctxtest"
+	RegisterCodec(format, WithoutContext(c))
+	defer func() {
+		codecMu.Lock()
+		delete(codecs, format)
+		codecMu.Unlock()
+	}()
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request-1")
+	if _, err := ParseContext(ctx, []byte("x"), ParseOptions{Format: format}); err != nil {
+		t.Fatal(err)
+	}
+	if v := <-c.seen; v != "request-1" {
+		t.Errorf("ParseContext passed ctx value %v", v)
+	}
+	if _, err := SerializeContext(ctx, &abstraction.AbstractMessage{}, SerializeOptions{Format: format, ForceReencode: true}); err != nil {
+		t.Fatal(err)
+	}
+	if v := <-c.seen; v != "request-1" {
+		t.Errorf("SerializeContext passed ctx value %v", v)
+	}
+	if _, err := ConvertContext(ctx, []byte(`{"type":"Commit"}`), ParseOptions{Format: FormatJSON}, SerializeOptions{Format: format, RequiredFields: []string{"Type"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range []string{"serialize", "fidelity parse"} {
+		if v := <-c.seen; v != "request-1" {
+			t.Errorf("ConvertContext %s passed ctx value %v", step, v)
+		}
+	}
+	if _, err := Parse([]byte("x"), ParseOptions{Format: format}); err != nil { //Codec 경로는 Background
+		t.Fatal(err)
+	}
+	if v := <-c.seen; v != nil {
+		t.Errorf("Parse passed ctx value %v", v)
+	}
+} //등록된 ContextCodec에 ctx 전달, adapter 왕복
@@ -0,0 +1,152 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"codec/framing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	ErrUnsupportedFormat  = errors.New("unsupported format")               //지원되지 않는 포맷
+	ErrUnsupportedGrammar = errors.New("unsupported grammar version")      //generic 포맷의 "@N" version 표시가 지원 범위 밖
+	ErrDescriptorNotFound = errors.New("protobuf descriptor not found")    //protobuf descriptor 없음
+	ErrTruncatedInput     = errors.New("truncated input")                  //입력이 중간에 끊김
+	ErrAmbiguousSynonym   = errors.New("ambiguous synonym")                //정규화 후 여러 표준 이름과 일치
+	ErrOverflow           = errors.New("integer overflow")                 //대상 포맷의 고정 폭 정수에 담을 수 없는 값
+	ErrFidelityLoss       = errors.New("conversion loses required fields") //Convert 대상 포맷에서 필수 필드 손실
+	ErrMessageTooLarge    = framing.ErrTooLarge                            //스트림 메시지 또는 압축 해제 결과가 최대 크기 초과
+	ErrLimitExceeded      = errors.New("limit exceeded")                   //ParseOptions.Limits 초과
+	ErrDecompress         = errors.New("decompression failed")             //압축 payload 손상
+) //errors.Is로 판별 가능한 sentinel 에러
+
+type ParseError struct {
+	Format Format //parsing 중이던 포맷
+	Field  string //문제가 된 필드명(알 수 없을 시 빈 문자열)
+	Offset int64  //입력 내 바이트 위치(알 수 없을 시 -1)
+	Line   int    //텍스트 포맷의 줄 번호(1부터, 알 수 없을 시 0)
+	Column int    //텍스트 포맷의 열 번호(1부터, 알 수 없을 시 0)
+	Err    error  //원인 에러
+} //위치 정보를 포함한 parsing 에러, errors.As로 추출
+
+func (e *ParseError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(string(e.Format))
+	sb.WriteString(" parse error")
+	if e.Field != "" {
+		fmt.Fprintf(&sb, " in field %q", e.Field)
+	}
+	if e.Line > 0 { //텍스트 포맷은 줄/열 표기
+		fmt.Fprintf(&sb, " at line %d, column %d", e.Line, e.Column)
+	} else if e.Offset >= 0 { //바이너리 포맷은 offset만 표기
+		fmt.Fprintf(&sb, " at offset %d", e.Offset)
+	}
+	if e.Err != nil {
+		sb.WriteString(": ")
+		sb.WriteString(e.Err.Error())
+	}
+	return sb.String()
+} //"json parse error in field "height" at line 1, column 12: ..." 형태
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+} //원인 에러 반환(errors.Is/As 체인)
+
+type OverflowError struct {
+	Format Format   //직렬화 대상 포맷
+	Field  string   //대상 필드 경로(예: view_changes[0].height)
+	Type   string   //대상 정수 타입(int64, uint32 등)
+	Value  *big.Int //담을 수 없는 값
+} //직렬화 시 값이 대상 필드의 정수 범위를 벗어남
+
+func (e *OverflowError) Error() string {
+	return fmt.Sprintf("%s: value %s of field %q overflows %s", e.Format, e.Value, e.Field, e.Type)
+} //"protobuf: value 9223372036854775808 of field "height" overflows int64" 형태
+
+func (e *OverflowError) Is(target error) bool {
+	return target == ErrOverflow
+} //errors.Is(err, ErrOverflow) 지원
+
+type FidelityError struct {
+	From   Format   //원본 포맷
+	To     Format   //대상 포맷
+	Fields []string //손실되는 필수 필드
+} //Convert 시 필수로 지정한 필드가 대상 포맷에서 손실됨
+
+func (e *FidelityError) Error() string {
+	return fmt.Sprintf("%s -> %s: %v: %s", e.From, e.To, ErrFidelityLoss, strings.Join(e.Fields, ", "))
+} //"json -> protobuf: conversion loses required fields: View, BlockHash" 형태
+
+func (e *FidelityError) Is(target error) bool {
+	return target == ErrFidelityLoss
+} //errors.Is(err, ErrFidelityLoss) 지원
+
+func newParseError(format Format, data []byte, offset int64, field string, err error) *ParseError {
+	pe := &ParseError{Format: format, Field: field, Offset: offset, Err: err}
+	if offset >= 0 && isTextFormat(format) { //텍스트 포맷일 시 줄/열 계산
+		pe.Line, pe.Column = lineColumn(data, offset)
+	}
+	return pe
+} //ParseError 생성, offset이 유효하면 줄/열 계산
+
+func isTextFormat(f Format) bool {
+	return f == FormatJSON || f == FormatGeneric
+} //줄/열 정보가 의미 있는 포맷인지 확인
+
+func lineColumn(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	prefix := data[:offset]
+	line := bytes.Count(prefix, []byte{'\n'}) + 1 //줄 번호
+	col := int(offset) + 1                        //열 번호
+	if i := bytes.LastIndexByte(prefix, '\n'); i >= 0 {
+		col = int(offset) - i
+	}
+	return line, col
+} //바이트 offset을 1부터 시작하는 줄/열로 변환
+
+func truncated(err error) error {
+	return fmt.Errorf("%w: %w", ErrTruncatedInput, err)
+} //원인 에러를 ErrTruncatedInput으로 감쌈
+
+func isTruncation(err error) bool {
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, rlp.ErrValueTooLarge)
+} //디코더가 입력 부족으로 실패했는지 확인
+
+func decodeError(format Format, data []byte, err error) *ParseError {
+	var pe *ParseError
+	if errors.As(err, &pe) { //이미 ParseError일 시
+		if pe.Format == format { //같은 포맷이면 위치 정보 그대로 사용
+			return pe
+		}
+		return newParseError(format, data, -1, pe.Field, err) //내부 payload 에러를 바깥 포맷으로 감쌈
+	}
+	var syn *json.SyntaxError
+	if errors.As(err, &syn) {
+		if strings.Contains(syn.Error(), "unexpected end of JSON input") {
+			return newParseError(format, data, syn.Offset, "", truncated(err))
+		}
+		return newParseError(format, data, max(syn.Offset-1, 0), "", err) //SyntaxError.Offset은 잘못된 바이트 다음 위치
+	}
+	var trail *trailingDataError
+	if errors.As(err, &trail) {
+		return newParseError(format, data, trail.Offset, "", err)
+	}
+	var typ *json.UnmarshalTypeError
+	if errors.As(err, &typ) {
+		return newParseError(format, data, typ.Offset, typ.Field, err)
+	}
+	if isTruncation(err) {
+		return newParseError(format, data, int64(len(data)), "", truncated(err))
+	}
+	return newParseError(format, data, -1, "", err)
+} //디코더 에러를 포맷별 ParseError로 변환
@@ -0,0 +1,76 @@
+package codec
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLineColumn(t *testing.T) {
+	data := []byte("ab\ncd\r\nef\n")
+	cases := []struct {
+		offset    int64
+		line, col int
+	}{
+		{0, 1, 1},
+		{2, 1, 3}, //'\n'은 그 줄의 끝
+		{3, 2, 1},
+		{5, 2, 3}, //'\r'도 그 줄의 끝
+		{6, 2, 4},
+		{7, 3, 1},
+		{10, 4, 1},  //입력 끝
+		{100, 4, 1}, //범위 밖은 입력 끝으로
+	}
+	for _, tc := range cases {
+		if line, col := lineColumn(data, tc.offset); line != tc.line || col != tc.col {
+			t.Errorf("lineColumn(%d) = %d:%d, want %d:%d", tc.offset, line, col, tc.line, tc.col)
+		}
+	}
+} //바이트 offset을 1부터 시작하는 줄/열로(CRLF의 '\r'은 앞 줄에 속함)
+
+func TestParseErrorPosition(t *testing.T) {
+	cases := []struct {
+		format    Format
+		in        string
+		offset    int64
+		line, col int
+		field     string
+		truncated bool
+	}{
+		{FormatJSON, "{\n  \"type\": \"Commit\",\n  \"height\": x\n}", 34, 3, 13, "", false},
+		{FormatJSON, "{\r\n  \"type\": \"Commit\",\r\n  \"height\": x\r\n}", 36, 3, 13, "", false},
+		{FormatJSON, "{\r\n \"type\": \"Commit\"\r\n \"height\": 1}", 23, 3, 2, "", false}, //빠진 ','
+		{FormatJSON, "{\"type\":\"Commit\"}\n\n  {}", 21, 3, 3, "", false},                 //값 뒤 데이터는 공백 다음 위치
+		{FormatJSON, "{\r\n  \"type\": \"Commit\",\r\n  \"height\": 1\r\n", 39, 4, 1, "", true},
+		{FormatGeneric, "Commit(\n  height=1\n  round=2)", 26, 3, 8, "", false},
+		{FormatGeneric, "Commit(\r\n  height=1\r\n  round=2)", 28, 3, 8, "", false},
+		{FormatGeneric, "Commit(\n  height=1)\n x", 21, 3, 2, "", false},
+		{FormatGeneric, "Commit(\r\n  seals=[a, b\r\n)", 24, 3, 1, "seals", false},
+		{FormatGeneric, "Commit(\r\n  height=1,\r\n  round=2\r\n", 33, 4, 1, "", true},
+		{FormatGeneric, "\r\n\r\nCommit\r\n", 12, 4, 1, "", true},
+		{FormatGeneric, "\n@2 Commit()", 1, 2, 1, "", false}, //version 표시 위치
+	}
+	for _, tc := range cases {
+		_, err := Parse([]byte(tc.in), ParseOptions{Format: tc.format})
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("%s %q: err = %v, want ParseError", tc.format, tc.in, err)
+			continue
+		}
+		if pe.Format != tc.format || pe.Offset != tc.offset || pe.Line != tc.line || pe.Column != tc.col || pe.Field != tc.field {
+			t.Errorf("%s %q: ParseError at offset %d (%d:%d) field %q, want offset %d (%d:%d) field %q: %v",
+				tc.format, tc.in, pe.Offset, pe.Line, pe.Column, pe.Field, tc.offset, tc.line, tc.col, tc.field, err)
+		}
+		if errors.Is(err, ErrTruncatedInput) != tc.truncated {
+			t.Errorf("%s %q: errors.Is(ErrTruncatedInput) = %v, want %v", tc.format, tc.in, !tc.truncated, tc.truncated)
+		}
+	}
+	_, err := Parse([]byte("{\n  \"type\": \"Commit\",\n  \"height\": x\n}"), ParseOptions{Format: FormatJSON})
+	if want := "json parse error at line 3, column 13: invalid character 'x' looking for beginning of value"; err == nil || err.Error() != want {
+		t.Errorf("Error() = %v, want %s", err, want)
+	}
+	_, err = Parse([]byte{0xf8}, ParseOptions{Format: FormatRLP})
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.Line != 0 || pe.Column != 0 {
+		t.Errorf("binary format ParseError = %+v, want no line/column", pe)
+	}
+} //JSON/generic 입력의 에러 위치(LF/CRLF 여러 줄)와 잘린 입력, 바이너리 포맷은 줄/열 없음
@@ -0,0 +1,324 @@
+package codec
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"codec/abstraction"
+)
+
+func extraValue(v interface{}, format Format) abstraction.ExtraValue {
+	var e abstraction.ExtraValue
+	switch t := v.(type) {
+	case nil:
+		e = abstraction.NullExtra()
+	case string:
+		e = abstraction.StringExtra(t)
+	case bool:
+		e = abstraction.BoolExtra(t)
+	case []byte: //msgpack bin, protobuf bytes
+		e = abstraction.BytesExtra(t)
+	case time.Time: //msgpack timestamp ext, protobuf Timestamp
+		e = abstraction.StringExtra(t.UTC().Format(time.RFC3339Nano))
+	case json.Number:
+		if x, ok := new(big.Int).SetString(t.String(), 10); ok {
+			e = abstraction.ExtraValue{Kind: abstraction.ExtraInt, Int: x} //새로 만든 값이라 IntExtra의 복사 생략
+		} else if f, err := t.Float64(); err == nil {
+			e = abstraction.FloatExtra(f)
+		} else {
+			e = abstraction.StringExtra(t.String()) //float64 범위를 넘는 지수 표기는 원문 유지
+		}
+	case float64:
+		e = abstraction.ExtraFromJSON(t) //정수값이면 int
+	case float32:
+		e = abstraction.ExtraFromJSON(float64(t))
+	case []interface{}:
+		items := make([]abstraction.ExtraValue, 0, len(t))
+		for _, item := range t {
+			items = append(items, extraValue(item, format))
+		}
+		e = abstraction.ListExtra(items...)
+	case map[string]interface{}:
+		m := make(map[string]abstraction.ExtraValue, len(t))
+		for k, item := range t {
+			m[k] = extraValue(item, format)
+		}
+		e = abstraction.MapExtra(m)
+	default:
+		if x := (coercer{}).bigInt(v); x != nil { //msgpack/protobuf 정수 타입
+			e = abstraction.IntExtra(x)
+		} else {
+			b, _ := json.Marshal(v)
+			e = abstraction.StringExtra(string(b))
+		}
+	}
+	e.Encoding = string(format)
+	return e
+} //디코딩된 값(JSON, msgpack, protobuf)을 ExtraValue로 변환
+
+func jsonExtra(b []byte, format Format) (abstraction.ExtraValue, bool) {
+	return jsonExtraIn(nil, b, format)
+} //JSON 값을 decoder와 트리 없이 extraValue(unmarshalJSON 결과)와 같은 값으로(실수, escape 문자열, 중복 key 등은 false)
+
+func jsonExtraIn(blk *extraBlock, b []byte, format Format) (abstraction.ExtraValue, bool) {
+	e, rest, ok := readJSONExtra(b, 0, format, blk)
+	return e, ok && len(rest) == 0
+} //jsonExtra와 같은 값(정수와 문자열은 blk에서)
+
+type extraBlock struct {
+	ints *intBlock
+	text *textBlock
+} //여러 Extras 값의 정수와 문자열을 한 번에 할당
+
+func newExtraBlock(ints, text int) *extraBlock {
+	return &extraBlock{newIntBlock(ints), newTextBlock(text)}
+} //정수 ints개, 문자열 text바이트 분량의 블록
+
+func (blk *extraBlock) int(x int64) *big.Int {
+	if blk == nil {
+		return big.NewInt(x)
+	}
+	return blk.ints.new(x)
+} //big.NewInt(x)와 같은 값
+
+func (blk *extraBlock) str(b []byte) string {
+	if blk == nil {
+		return string(b)
+	}
+	return blk.text.text(b)
+} //string(b)와 같은 값
+
+const maxJSONExtraDepth = 64 //더 깊은 값은 decoder로
+
+func readJSONExtra(b []byte, depth int, format Format, blk *extraBlock) (abstraction.ExtraValue, []byte, bool) {
+	var e abstraction.ExtraValue
+	if b = trimJSONSpace(b); len(b) == 0 || depth > maxJSONExtraDepth {
+		return e, nil, false
+	}
+	switch b[0] {
+	case '[':
+		items := []abstraction.ExtraValue{}
+		if b = trimJSONSpace(b[1:]); len(b) > 0 && b[0] == ']' {
+			b = b[1:]
+		} else {
+			for {
+				item, rest, ok := readJSONExtra(b, depth+1, format, blk)
+				if !ok || len(rest) == 0 || rest[0] != ',' && rest[0] != ']' {
+					return e, nil, false
+				}
+				items = append(items, item)
+				if b = rest[1:]; rest[0] == ']' {
+					break
+				}
+			}
+		}
+		e = abstraction.ListExtra(items...)
+	case '{':
+		m := map[string]abstraction.ExtraValue{}
+		if b = trimJSONSpace(b[1:]); len(b) > 0 && b[0] == '}' {
+			b = b[1:]
+		} else {
+			for {
+				b = trimJSONSpace(b)
+				n := jsonStringEnd(b)
+				if n < 0 {
+					return e, nil, false
+				}
+				k, ok := plainJSONString(b[:n])
+				if _, dup := m[string(k)]; !ok || dup { //escape된 key와 중복 key는 decoder 규칙으로
+					return e, nil, false
+				}
+				if b = trimJSONSpace(b[n:]); len(b) == 0 || b[0] != ':' {
+					return e, nil, false
+				}
+				item, rest, ok := readJSONExtra(b[1:], depth+1, format, blk)
+				if !ok || len(rest) == 0 || rest[0] != ',' && rest[0] != '}' {
+					return e, nil, false
+				}
+				m[blk.str(k)] = item
+				if b = rest[1:]; rest[0] == '}' {
+					break
+				}
+			}
+		}
+		e = abstraction.MapExtra(m)
+	case '"':
+		n := jsonStringEnd(b)
+		if n < 0 {
+			return e, nil, false
+		}
+		s, ok := plainJSONString(b[:n])
+		if !ok {
+			return e, nil, false
+		}
+		e, b = abstraction.StringExtra(blk.str(s)), b[n:]
+	default:
+		n := 0
+		for n < len(b) && b[n] != ',' && b[n] != ']' && b[n] != '}' && !isJSONSpace(b[n]) {
+			n++
+		}
+		switch tok := b[:n]; string(tok) {
+		case "null":
+			e = abstraction.NullExtra()
+		case "true", "false":
+			e = abstraction.BoolExtra(tok[0] == 't')
+		default:
+			if !jsonIntToken(tok) {
+				return e, nil, false //실수 등은 decoder로
+			}
+			e = abstraction.ExtraValue{Kind: abstraction.ExtraInt, Int: tokenInt(tok, blk)}
+		}
+		b = b[n:]
+	}
+	e.Encoding = string(format)
+	return e, trimJSONSpace(b), true
+} //b 앞의 JSON 값 하나와 (공백을 건너뛴) 나머지 바이트
+
+func tokenInt(tok []byte, blk *extraBlock) *big.Int {
+	neg := tok[0] == '-'
+	digits := tok
+	if neg {
+		digits = tok[1:]
+	}
+	if len(digits) > 18 { //int64 범위를 넘을 수 있는 값
+		x, _ := new(big.Int).SetString(string(tok), 10)
+		return x
+	}
+	var x int64
+	for _, d := range digits {
+		x = x*10 + int64(d-'0')
+	}
+	if neg {
+		x = -x
+	}
+	return blk.int(x)
+} //jsonIntToken을 통과한 정수 표기를 *big.Int로
+
+func extraInterface(e abstraction.ExtraValue) interface{} {
+	switch e.Kind {
+	case abstraction.ExtraString:
+		return e.Str
+	case abstraction.ExtraInt:
+		return json.Number(e.Int.String())
+	case abstraction.ExtraFloat:
+		if math.IsInf(e.Float, 0) || math.IsNaN(e.Float) {
+			return e.Text()
+		}
+		return json.Number(strconv.FormatFloat(e.Float, 'g', -1, 64))
+	case abstraction.ExtraBool:
+		return e.Bool
+	case abstraction.ExtraBytes:
+		return e.Text() //base64
+	case abstraction.ExtraList:
+		out := make([]interface{}, 0, len(e.List))
+		for _, item := range e.List {
+			out = append(out, extraInterface(item))
+		}
+		return out
+	case abstraction.ExtraMap:
+		out := make(map[string]interface{}, len(e.Map))
+		for k, item := range e.Map {
+			out[k] = extraInterface(item)
+		}
+		return out
+	}
+	return nil
+} //ExtraValue를 JSON 직렬화용 값으로 변환(정수는 json.Number로 정밀도 유지)
+
+func genericExtraValue(v genericValue) abstraction.ExtraValue {
+	var e abstraction.ExtraValue
+	switch v.kind {
+	case genericList:
+		items := make([]abstraction.ExtraValue, 0, len(v.items))
+		for _, item := range v.items {
+			items = append(items, genericExtraValue(item))
+		}
+		e = abstraction.ListExtra(items...)
+	case genericRecord:
+		m := make(map[string]abstraction.ExtraValue, len(v.fields))
+		for _, f := range v.fields {
+			m[f.key] = genericExtraValue(f.value)
+		}
+		e = abstraction.MapExtra(m)
+	default:
+		if v.quoted { //따옴표 값은 항상 문자열
+			e = abstraction.StringExtra(v.text)
+		} else {
+			e = inferGenericScalar(v.text)
+		}
+	}
+	e.Encoding = string(FormatGeneric)
+	return e
+} //generic 값을 ExtraValue로 변환(bare scalar는 null/bool/정수/실수 추론)
+
+func inferGenericScalar(s string) abstraction.ExtraValue {
+	switch s {
+	case "null":
+		return abstraction.NullExtra()
+	case "true", "false":
+		return abstraction.BoolExtra(s == "true")
+	}
+	if !isNumberText(s) {
+		return abstraction.StringExtra(s)
+	}
+	if x, ok := new(big.Int).SetString(s, 10); ok {
+		return abstraction.IntExtra(x)
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return abstraction.FloatExtra(f)
+	}
+	return abstraction.StringExtra(s)
+} //bare scalar 타입 추론
+
+func isNumberText(s string) bool {
+	digits := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case '0' <= c && c <= '9':
+			digits = true
+		case c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E':
+		default:
+			return false
+		}
+	}
+	return digits
+} //10진수/실수 표기 문자로만 구성되는지 확인("Inf", "0x10" 등은 문자열)
+
+func genericExtraText(e abstraction.ExtraValue) string {
+	switch e.Kind {
+	case abstraction.ExtraString:
+		if inferGenericScalar(e.Str).Kind != abstraction.ExtraString { //"1", "true" 등은 따옴표로 문자열 유지
+			return quotedGeneric(e.Str)
+		}
+		return quoteGeneric(e.Str)
+	case abstraction.ExtraBytes:
+		return quoteGeneric(e.Text())
+	case abstraction.ExtraList:
+		items := make([]string, 0, len(e.List))
+		for _, item := range e.List {
+			items = append(items, genericExtraText(item))
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	case abstraction.ExtraMap:
+		keys := make([]string, 0, len(e.Map))
+		for k := range e.Map {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fs := make([]string, 0, len(keys))
+		for _, k := range keys {
+			fs = append(fs, quoteGeneric(k)+"="+genericExtraText(e.Map[k]))
+		}
+		return "{" + strings.Join(fs, ",") + "}"
+	case abstraction.ExtraFloat:
+		if math.IsInf(e.Float, 0) || math.IsNaN(e.Float) {
+			return quoteGeneric(e.Text())
+		}
+	}
+	return e.Text() //int, float, bool, null
+} //ExtraValue를 generic 값 표기로 변환
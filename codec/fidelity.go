@@ -0,0 +1,284 @@
+package codec
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"codec/abstraction"
+)
+
+type FieldFidelity string
+
+const (
+	FidelityPreserved   FieldFidelity = "preserved"   //같은 값 그대로 유지
+	FidelityTransformed FieldFidelity = "transformed" //의미는 유지되나 표현이 바뀜(해시 인코딩, Extras 타입, timestamp 정밀도 등)
+	FidelityLost        FieldFidelity = "lost"        //값이 없어지거나 달라짐
+)
+
+var FidelityFields = []string{
+	"Type", "Height", "Round", "View", "Timestamp", "BlockHash", "PrevHash",
+	"Proposer", "Validator", "Signature", "CommitSeals", "ViewChanges", "Extras",
+} //Fidelity가 보고하는 AbstractMessage 필드(표시 순서)
+
+type FidelityReport struct {
+	From   Format                   //원본 포맷
+	To     Format                   //대상 포맷
+	Fields map[string]FieldFidelity //필드명 -> 유지 정도
+} //포맷 변환 시 필드별 유지 정도
+
+func (r *FidelityReport) Lost() []string {
+	return r.with(FidelityLost)
+} //손실되는 필드 목록
+
+func (r *FidelityReport) Transformed() []string {
+	return r.with(FidelityTransformed)
+} //표현이 바뀌는 필드 목록
+
+func (r *FidelityReport) with(f FieldFidelity) []string {
+	var out []string
+	for _, field := range FidelityFields {
+		if r.Fields[field] == f {
+			out = append(out, field)
+		}
+	}
+	return out
+} //유지 정도가 f인 필드 목록(FidelityFields 순서)
+
+func (r *FidelityReport) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s -> %s:", r.From, r.To)
+	for _, field := range FidelityFields {
+		fmt.Fprintf(&sb, " %s=%s", field, r.Fields[field])
+	}
+	return sb.String()
+} //"json -> protobuf: Type=preserved Height=preserved ..." 형태
+
+func Fidelity(from, to Format) (*FidelityReport, error) {
+	return ConversionFidelity(ParseOptions{Format: from}, SerializeOptions{Format: to})
+} //from 포맷 메시지를 to 포맷으로 변환할 때 필드별 유지 정도(protobuf는 ConversionFidelity로 스키마 지정)
+
+func ConversionFidelity(popts ParseOptions, sopts SerializeOptions) (*FidelityReport, error) {
+	probe := fidelityProbe()
+	src, err := Serialize(probe, SerializeOptions{
+		Format:               popts.Format,
+		ProtoMessageFullName: popts.ProtoMessageFullName,
+		DescriptorProvider:   popts.DescriptorProvider,
+		ProtoDiscardUnknown:  true, //원본 스키마에 없는 필드는 원본 포맷에서 이미 손실
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fidelity probe (%s): %w", popts.Format, err)
+	}
+	am, err := Parse(src, popts)
+	if err != nil {
+		return nil, fmt.Errorf("fidelity probe (%s): %w", popts.Format, err)
+	}
+	out, err := Serialize(am, sopts)
+	if err != nil {
+		return nil, fmt.Errorf("fidelity probe (%s -> %s): %w", popts.Format, sopts.Format, err)
+	}
+	back, err := Parse(out, targetParseOptions(sopts))
+	if err != nil {
+		return nil, fmt.Errorf("fidelity probe (%s -> %s): %w", popts.Format, sopts.Format, err)
+	}
+	return &FidelityReport{From: popts.Format, To: sopts.Format, Fields: CompareFields(probe, back)}, nil
+} //모든 필드를 채운 probe 메시지를 원본 포맷 → 대상 포맷으로 변환 후 다시 읽어 비교
+
+func targetParseOptions(sopts SerializeOptions) ParseOptions {
+	return ParseOptions{
+		Format:               sopts.Format,
+		ProtoMessageFullName: sopts.ProtoMessageFullName,
+		DescriptorProvider:   sopts.DescriptorProvider,
+		ProtoDiscardUnknown:  sopts.ProtoDiscardUnknown,
+		Compression:          sopts.Compression,
+		MaxDecompressedSize:  math.MaxInt32, //자신이 압축한 결과
+	}
+} //직렬화 결과를 다시 읽을 때의 ParseOptions
+
+func fidelityProbe() *abstraction.AbstractMessage {
+	return &abstraction.AbstractMessage{
+		Type:        abstraction.MsgTypeCommit,
+		Height:      big.NewInt(1234567),
+		Round:       big.NewInt(3),
+		View:        big.NewInt(2),
+		Timestamp:   time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC),
+		BlockHash:   "0x" + strings.Repeat("ab", 32),
+		PrevHash:    "0x" + strings.Repeat("cd", 32),
+		Proposer:    "validator-1",
+		Validator:   "validator-2",
+		Signature:   "0x" + strings.Repeat("ef", 65),
+		CommitSeals: []string{"0x" + strings.Repeat("01", 65), "0x" + strings.Repeat("02", 65)},
+		ViewChanges: []abstraction.ViewChangeEntry{
+			{View: big.NewInt(2), Height: big.NewInt(1234567), Validator: "validator-3", Signature: "0x" + strings.Repeat("03", 65)},
+		},
+		Extras: map[string]abstraction.ExtraValue{
+			"probe_string": abstraction.StringExtra("text"),
+			"probe_int":    abstraction.IntExtra(big.NewInt(42)),
+			"probe_float":  abstraction.FloatExtra(1.5),
+			"probe_bool":   abstraction.BoolExtra(true),
+			"probe_bytes":  abstraction.BytesExtra([]byte{0xde, 0xad, 0xbe, 0xef}),
+			"probe_list":   abstraction.ListExtra(abstraction.StringExtra("a"), abstraction.IntExtra(big.NewInt(1))),
+		},
+	}
+} //모든 필드에 0이 아닌 값을 채운 비교용 메시지
+
+func CompareFields(want, got *abstraction.AbstractMessage) map[string]FieldFidelity {
+	out := make(map[string]FieldFidelity, len(FidelityFields))
+	out["Type"] = fidelityOf(want.Type == got.Type, false)
+	out["Height"] = fidelityOf(bigEqual(want.Height, got.Height), false)
+	out["Round"] = fidelityOf(bigEqual(want.Round, got.Round), false)
+	out["View"] = fidelityOf(bigEqual(want.View, got.View), false)
+	out["Timestamp"] = fidelityOf(want.Timestamp.Equal(got.Timestamp),
+		want.Timestamp.Truncate(time.Second).Equal(got.Timestamp.Truncate(time.Second)))
+	out["BlockHash"] = hashFidelity(want.BlockHash, got.BlockHash)
+	out["PrevHash"] = hashFidelity(want.PrevHash, got.PrevHash)
+	out["Proposer"] = hashFidelity(want.Proposer, got.Proposer)
+	out["Validator"] = hashFidelity(want.Validator, got.Validator)
+	out["Signature"] = hashFidelity(want.Signature, got.Signature)
+	out["CommitSeals"] = sealsFidelity(want.CommitSeals, got.CommitSeals)
+	out["ViewChanges"] = viewChangesFidelity(want.ViewChanges, got.ViewChanges)
+	out["Extras"] = extrasFidelity(want.Extras, got.Extras)
+	return out
+} //want의 각 필드가 got에 얼마나 유지되었는지 비교(got에만 있는 Extras는 무시)
+
+func fidelityOf(same, equivalent bool) FieldFidelity {
+	switch {
+	case same:
+		return FidelityPreserved
+	case equivalent:
+		return FidelityTransformed
+	}
+	return FidelityLost
+} //비교 결과를 FieldFidelity로 변환
+
+func bigEqual(a, b *big.Int) bool {
+	if a == nil {
+		a = new(big.Int)
+	}
+	if b == nil {
+		b = new(big.Int)
+	}
+	return a.Cmp(b) == 0
+} //*big.Int 비교(nil은 0, protobuf 등은 0과 생략을 구분하지 않음)
+
+func hashFidelity(want, got string) FieldFidelity {
+	c := coercer{enc: HashHex, base64: true}
+	return fidelityOf(want == got, c.hash(want) == c.hash(got))
+} //해시/서명 비교(같은 바이트의 다른 인코딩은 transformed)
+
+func sealsFidelity(want, got []string) FieldFidelity {
+	if len(want) != len(got) {
+		return FidelityLost
+	}
+	out := FidelityPreserved
+	for i := range want {
+		out = worseFidelity(out, hashFidelity(want[i], got[i]))
+	}
+	return out
+} //CommitSeals 원소별 비교
+
+func viewChangesFidelity(want, got []abstraction.ViewChangeEntry) FieldFidelity {
+	if len(want) != len(got) {
+		return FidelityLost
+	}
+	out := FidelityPreserved
+	for i, w := range want {
+		g := got[i]
+		if !bigEqual(w.View, g.View) || !bigEqual(w.Height, g.Height) {
+			return FidelityLost
+		}
+		out = worseFidelity(out, hashFidelity(w.Validator, g.Validator))
+		out = worseFidelity(out, hashFidelity(w.Signature, g.Signature))
+	}
+	return out
+} //ViewChanges 원소별 비교
+
+func extrasFidelity(want, got map[string]abstraction.ExtraValue) FieldFidelity {
+	out := FidelityPreserved
+	for k, w := range want {
+		g, ok := got[k]
+		switch {
+		case !ok || !w.Equal(g):
+			return FidelityLost
+		case !sameExtra(w, g):
+			out = FidelityTransformed
+		}
+	}
+	return out
+} //Extras key별 비교(Kind가 바뀌었으나 Equal이면 transformed)
+
+func sameExtra(a, b abstraction.ExtraValue) bool {
+	if a.Kind != b.Kind {
+		return false
+	}
+	switch a.Kind {
+	case abstraction.ExtraList:
+		for i := range a.List {
+			if !sameExtra(a.List[i], b.List[i]) {
+				return false
+			}
+		}
+	case abstraction.ExtraMap:
+		for k, v := range a.Map {
+			if !sameExtra(v, b.Map[k]) {
+				return false
+			}
+		}
+	}
+	return true
+} //Equal인 두 값의 Kind가 중첩 값까지 같은지 확인
+
+func worseFidelity(a, b FieldFidelity) FieldFidelity {
+	if a == FidelityLost || b == FidelityLost {
+		return FidelityLost
+	}
+	if a == FidelityTransformed || b == FidelityTransformed {
+		return FidelityTransformed
+	}
+	return FidelityPreserved
+} //두 결과 중 더 나쁜 쪽
+
+type FidelityPolicy string
+
+const (
+	FidelityRefuse FidelityPolicy = ""     //필수 필드가 손실되면 FidelityError 반환(기본값)
+	FidelityWarn   FidelityPolicy = "warn" //OnFidelityLoss(설정 시)로 알린 후 변환 결과 반환
+)
+
+func checkRequired(ctx context.Context, am *abstraction.AbstractMessage, out []byte, sopts SerializeOptions) error {
+	back, err := ParseContext(ctx, out, targetParseOptions(sopts))
+	if err != nil {
+		return fmt.Errorf("fidelity check (%s): %w", sopts.Format, err)
+	}
+	got := CompareFields(am, back)
+	var lost []string
+	for _, field := range sopts.RequiredFields {
+		if f, ok := got[field]; !ok || f == FidelityLost {
+			lost = append(lost, field)
+		}
+	}
+	if len(lost) == 0 {
+		return nil
+	}
+	fe := &FidelityError{From: Format(am.OriginalFormat), To: sopts.Format, Fields: lost}
+	if sopts.FidelityPolicy == FidelityWarn {
+		if sopts.OnFidelityLoss != nil {
+			sopts.OnFidelityLoss(fe)
+		}
+		return nil
+	}
+	return fe
+} //변환 결과를 다시 읽어 필수 필드가 손실되었는지 확인(정책에 따라 callback 또는 에러)
+
+func checkRequiredNames(fields []string) error {
+	for _, field := range fields {
+		if !slices.Contains(FidelityFields, field) {
+			return fmt.Errorf("required field %q is not one of FidelityFields", field)
+		}
+	}
+	return nil
+} //RequiredFields가 모두 FidelityFields 이름인지 확인(변환 전 설정 오류)
@@ -0,0 +1,61 @@
+package codec
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"codec/abstraction"
+)
+
+type lossyCodec struct{ Codec } //Signature를 버리는 JSON codec
+
+func (c lossyCodec) Serialize(am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error) {
+	cp := am.Clone()
+	cp.Signature = ""
+	opts.Format = FormatJSON
+	return c.Codec.Serialize(cp, opts)
+} //Signature를 비우고 JSON으로 직렬화
+
+func TestConvertRequiredFields(t *testing.T) {
+	const format Format = "lossytest"
+	jc, _ := LookupCodec(FormatJSON)
+	RegisterCodec(format, lossyCodec{jc})
+	defer func() {
+		codecMu.Lock()
+		delete(codecs, format)
+		codecMu.Unlock()
+	}()
+	src, err := Serialize(fidelityProbe(), SerializeOptions{Format: FormatJSON})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sopts := SerializeOptions{Format: format, RequiredFields: []string{"Height", "Signature"}}
+	_, err = Convert(src, ParseOptions{Format: FormatJSON}, sopts)
+	var fe *FidelityError
+	if !errors.Is(err, ErrFidelityLoss) || !errors.As(err, &fe) || !slices.Equal(fe.Fields, []string{"Signature"}) {
+		t.Fatalf("Convert err = %v, want FidelityError for Signature", err)
+	}
+
+	var reported *FidelityError
+	sopts.FidelityPolicy = FidelityWarn
+	sopts.OnFidelityLoss = func(e *FidelityError) { reported = e }
+	out, err := Convert(src, ParseOptions{Format: FormatJSON}, sopts)
+	if err != nil || len(out) == 0 {
+		t.Fatalf("Convert(warn) = %q, %v", out, err)
+	}
+	if reported == nil || !slices.Equal(reported.Fields, []string{"Signature"}) || reported.To != format {
+		t.Fatalf("OnFidelityLoss got %v, want Signature lost", reported)
+	}
+	sopts.OnFidelityLoss = nil
+	if out, err := Convert(src, ParseOptions{Format: FormatJSON}, sopts); err != nil || len(out) == 0 {
+		t.Fatalf("Convert(warn, no callback) = %q, %v", out, err)
+	}
+
+	sopts = SerializeOptions{Format: FormatJSON, RequiredFields: []string{"Height", "height"}}
+	_, err = Convert([]byte("not parsed"), ParseOptions{Format: FormatJSON}, sopts)
+	if err == nil || errors.Is(err, ErrFidelityLoss) || !strings.Contains(err.Error(), `"height"`) {
+		t.Fatalf("Convert with an unknown required field = %v, want a configuration error", err)
+	}
+} //필수 필드 손실 시 거부, FidelityWarn이면 callback으로 손실 필드 전달, FidelityFields에 없는 이름은 변환 전 에러
@@ -0,0 +1,178 @@
+package codec
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+
+	"codec/abstraction"
+	"codec/codec/codectest/fixtures"
+)
+
+const fuzzProtoMessage = "pbft.AbstractMessage" //proto/abstraction.proto의 메시지
+
+var fuzzProtoOnce sync.Once
+
+func fuzzSetup(tb testing.TB) {
+	fuzzProtoOnce.Do(func() {
+		if err := RegisterDescriptorSetFile("../proto/abstraction.protoset"); err != nil {
+			tb.Fatalf("register descriptor set: %v", err)
+		}
+	})
+} //protobuf descriptor 등록(한 번만)
+
+func fuzzFixtures() []*abstraction.AbstractMessage {
+	return append(fixtures.Messages(), fixtures.Edge()...)
+} //seed corpus를 만들 메시지
+
+var fuzzTextSeeds = []string{
+	`{"type":"PREPARE","blockNumber":"0x10","round":1,"from":"v1","signature":"0xab"}`,
+	`{"@type":"/tendermint.Vote","height":"5","round":0,"block_id":{"hash":"AB"}}`,
+	`Commit(height=1,commit_seals=[a,"b,c"],view_changes=[{view=1,height=2}])`,
+	`Prepare(height=,"odd key"="xé")`,
+	`[]`,
+	``,
+} //포맷 감지/동의어/경로 규칙을 거치는 텍스트 입력
+
+func identicalMessage(a, b *abstraction.AbstractMessage) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	x, y := *a, *b
+	x.Extras, y.Extras = nil, nil
+	if !reflect.DeepEqual(x, y) || (a.Extras == nil) != (b.Extras == nil) || len(a.Extras) != len(b.Extras) {
+		return false
+	}
+	for k, e := range a.Extras {
+		if o, ok := b.Extras[k]; !ok || !identicalExtra(e, o) {
+			return false
+		}
+	}
+	return true
+} //reflect.DeepEqual과 같되 NaN Extras끼리는 같은 값으로(직접 경로와 트리 경로 비교용)
+
+func identicalExtra(a, b abstraction.ExtraValue) bool {
+	if math.IsNaN(a.Float) && math.IsNaN(b.Float) {
+		a.Float, b.Float = 0, 0
+	}
+	al, bl, am, bm := a.List, b.List, a.Map, b.Map
+	a.List, b.List, a.Map, b.Map = nil, nil, nil, nil
+	if !reflect.DeepEqual(a, b) || (al == nil) != (bl == nil) || len(al) != len(bl) || (am == nil) != (bm == nil) || len(am) != len(bm) {
+		return false
+	}
+	for i := range al {
+		if !identicalExtra(al[i], bl[i]) {
+			return false
+		}
+	}
+	for k, x := range am {
+		if y, ok := bm[k]; !ok || !identicalExtra(x, y) {
+			return false
+		}
+	}
+	return true
+} //ExtraValue 비교(NaN끼리 같음)
+
+func fuzzParseOptions(format Format) ParseOptions {
+	return ParseOptions{Format: format, ProtoMessageFullName: fuzzProtoMessage}
+} //fuzz 대상 포맷의 ParseOptions
+
+func fuzzSerializeOptions(format Format) SerializeOptions {
+	return SerializeOptions{Format: format, ProtoMessageFullName: fuzzProtoMessage, ProtoDiscardUnknown: true, ForceReencode: true}
+} //codec을 거치도록 passthrough를 끈 SerializeOptions
+
+func fuzzSeed(f *testing.F, format Format) {
+	fuzzSetup(f)
+	for _, am := range fuzzFixtures() {
+		b, err := Serialize(am, fuzzSerializeOptions(format))
+		if err != nil {
+			continue //대상 포맷에 담을 수 없는 fixture
+		}
+		f.Add(b)
+	}
+	for _, s := range fuzzTextSeeds {
+		f.Add([]byte(s))
+	}
+} //fixture를 format으로 직렬화한 seed 추가
+
+func fuzzParse(f *testing.F, format Format) {
+	fuzzSeed(f, format)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		am, err := Parse(data, fuzzParseOptions(format))
+		if err != nil {
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error is not a *ParseError: %T %v", err, err)
+			}
+			return
+		}
+		if am == nil {
+			t.Fatal("nil message without error")
+		}
+	})
+} //임의 입력에 panic 없이 메시지 또는 ParseError 반환
+
+func fuzzRoundTrip(f *testing.F, format Format) {
+	fuzzSeed(f, format)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		am, err := Parse(data, fuzzParseOptions(format))
+		if err != nil {
+			return
+		}
+		first, err := Serialize(am, fuzzSerializeOptions(format))
+		if err != nil {
+			return //대상 포맷에 담을 수 없는 값(overflow 등)
+		}
+		am1, err := Parse(first, fuzzParseOptions(format))
+		if err != nil {
+			t.Fatalf("serialized output does not parse: %v\n%q", err, first)
+		}
+		second, err := Serialize(am1, fuzzSerializeOptions(format))
+		if err != nil {
+			t.Fatalf("re-serialize: %v", err)
+		}
+		am2, err := Parse(second, fuzzParseOptions(format))
+		if err != nil {
+			t.Fatalf("second output does not parse: %v\n%q", err, second)
+		}
+		if d := am1.Diff(am2, abstraction.EqualOptions{IgnoreRawPayload: true}); len(d) > 0 {
+			t.Fatalf("parse→serialize→parse not stable:\n%s\nfirst:  %q\nsecond: %q", d, first, second)
+		}
+	})
+} //한 번 정규화된 메시지는 같은 포맷으로 다시 직렬화/parsing해도 그대로
+
+func FuzzDetectFormat(f *testing.F) {
+	fuzzSetup(f)
+	for _, format := range []Format{FormatGeneric, FormatJSON, FormatProtobuf, FormatRLP, FormatMsgPack, FormatBCS} {
+		for _, am := range fuzzFixtures() {
+			if b, err := Serialize(am, fuzzSerializeOptions(format)); err == nil {
+				f.Add(b)
+			}
+		}
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		switch got := DetectFormat(data); got {
+		case FormatGeneric, FormatJSON, FormatProtobuf, FormatRLP, FormatMsgPack, FormatBCS:
+		default:
+			t.Fatalf("DetectFormat returned %q", got)
+		}
+		DetectCompression(data)
+	})
+} //임의 입력에 panic 없이 알려진 포맷 반환
+
+func FuzzParseGeneric(f *testing.F)  { fuzzParse(f, FormatGeneric) }
+func FuzzParseJSON(f *testing.F)     { fuzzParse(f, FormatJSON) }
+func FuzzParseProtobuf(f *testing.F) { fuzzParse(f, FormatProtobuf) }
+func FuzzParseRLP(f *testing.F)      { fuzzParse(f, FormatRLP) }
+func FuzzParseMsgPack(f *testing.F)  { fuzzParse(f, FormatMsgPack) }
+func FuzzParseBCS(f *testing.F)      { fuzzParse(f, FormatBCS) }
+func FuzzParseAuto(f *testing.F)     { fuzzParse(f, FormatAuto) }
+
+func FuzzRoundTripGeneric(f *testing.F)  { fuzzRoundTrip(f, FormatGeneric) }
+func FuzzRoundTripJSON(f *testing.F)     { fuzzRoundTrip(f, FormatJSON) }
+func FuzzRoundTripProtobuf(f *testing.F) { fuzzRoundTrip(f, FormatProtobuf) }
+func FuzzRoundTripRLP(f *testing.F)      { fuzzRoundTrip(f, FormatRLP) }
+func FuzzRoundTripMsgPack(f *testing.F)  { fuzzRoundTrip(f, FormatMsgPack) }
+func FuzzRoundTripBCS(f *testing.F)      { fuzzRoundTrip(f, FormatBCS) }
@@ -0,0 +1,434 @@
+package codec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// generic 포맷 문법(GenericGrammarVersion = 1)
+//
+//	document = [ "@" version space ] message
+//	version  = digit { digit }
+//	message = name "(" [ field { "," field } ] ")"
+//	field   = key "=" value
+//	value   = list | record | quoted | bare
+//	list    = "[" [ value { "," value } ] "]"
+//	record  = "{" [ field { "," field } ] "}"
+//	quoted  = '"' { char | escape } '"'
+//	escape  = "\" ( '"' | "\" | "/" | "n" | "r" | "t" | "u" hex hex hex hex )
+//	bare    = ( ) [ ] { } , = " 를 제외한 문자열, 앞뒤 공백은 제거
+//	name    = bare | quoted
+//	key     = bare | quoted
+//
+// 구분자나 앞뒤 공백을 포함하는 값은 quoted로 써야 하며,
+// commit_seals는 list, view_changes는 record의 list로 표현.
+// Extras로 가는 bare 값은 null, true/false, 10진수 정수/실수일 시 해당 타입으로 읽으며
+// 같은 모양의 문자열은 quoted로 써서 구분.
+// serializing 시 항상 "@1 "을 앞에 쓰며, version 표시가 없는 입력은 version 1로 읽고
+// 지원하지 않는 version은 ErrUnsupportedGrammar로 거부
+const GenericGrammarVersion = 1
+
+type genericTokenKind int
+
+const (
+	tokEOF    genericTokenKind = iota
+	tokPunct                   //( ) [ ] { } , =
+	tokBare                    //따옴표 없는 값
+	tokQuoted                  //따옴표로 감싼 값(escape 해제됨)
+)
+
+type genericToken struct {
+	kind   genericTokenKind
+	text   string //punct일 시 해당 문자, 값일 시 내용
+	offset int    //입력 내 시작 위치
+} //generic 포맷의 token
+
+const genericSpecial = "()[]{},=\""
+
+type genericLexer struct {
+	data   []byte
+	pos    int
+	peek   *genericToken
+	limits Limits //깊이/원소 수/값 길이 상한
+	depth  int    //현재 중첩 깊이(메시지 자체가 1)
+} //generic 포맷 tokenizer
+
+func (lx *genericLexer) errorf(offset int, format string, args ...interface{}) *ParseError {
+	return newParseError(FormatGeneric, lx.data, int64(offset), "", fmt.Errorf(format, args...))
+} //현재 입력 기준 위치 정보를 담은 ParseError 생성
+
+func (lx *genericLexer) limit(name string, max, got, offset int) error {
+	if err := lx.limits.check(name, max, got, ""); err != nil {
+		return limitError(FormatGeneric, lx.data, int64(offset), err)
+	}
+	return nil
+} //상한 초과 시 현재 입력 기준 위치 정보를 담은 ParseError
+
+func (lx *genericLexer) skipSpace() {
+	for lx.pos < len(lx.data) {
+		switch lx.data[lx.pos] {
+		case ' ', '\t', '\r', '\n':
+			lx.pos++
+		default:
+			return
+		}
+	}
+} //공백 건너뜀
+
+func (lx *genericLexer) next() (genericToken, error) {
+	if lx.peek != nil { //미리 읽은 token이 있을 시
+		t := *lx.peek
+		lx.peek = nil
+		return t, nil
+	}
+	lx.skipSpace()
+	if lx.pos >= len(lx.data) {
+		return genericToken{kind: tokEOF, offset: lx.pos}, nil
+	}
+	start := lx.pos
+	c := lx.data[lx.pos]
+	switch {
+	case c == '"':
+		s, err := lx.readQuoted()
+		if err != nil {
+			return genericToken{}, err
+		}
+		return genericToken{kind: tokQuoted, text: s, offset: start}, nil
+	case strings.IndexByte(genericSpecial, c) >= 0:
+		lx.pos++
+		return genericToken{kind: tokPunct, text: string(c), offset: start}, nil
+	}
+	for lx.pos < len(lx.data) && strings.IndexByte(genericSpecial, lx.data[lx.pos]) < 0 {
+		lx.pos++
+	}
+	text := strings.TrimRight(string(lx.data[start:lx.pos]), " \t\r\n") //내부 공백은 유지, 끝 공백 제거
+	return genericToken{kind: tokBare, text: text, offset: start}, nil
+} //다음 token 반환
+
+func (lx *genericLexer) unread(t genericToken) {
+	lx.peek = &t
+} //token 하나를 되돌림
+
+func (lx *genericLexer) readQuoted() (string, error) {
+	start := lx.pos
+	lx.pos++ //여는 따옴표
+	var sb strings.Builder
+	for lx.pos < len(lx.data) {
+		c := lx.data[lx.pos]
+		switch {
+		case c == '"':
+			lx.pos++
+			return sb.String(), nil
+		case c == '\\':
+			if lx.pos+1 >= len(lx.data) {
+				return "", lx.errorf(lx.pos, "%w: unterminated escape", ErrTruncatedInput)
+			}
+			esc := lx.data[lx.pos+1]
+			switch esc {
+			case '"', '\\', '/':
+				sb.WriteByte(esc)
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'u':
+				if lx.pos+6 > len(lx.data) {
+					return "", lx.errorf(lx.pos, "%w: short unicode escape", ErrTruncatedInput)
+				}
+				r, err := strconv.ParseUint(string(lx.data[lx.pos+2:lx.pos+6]), 16, 32)
+				if err != nil {
+					return "", lx.errorf(lx.pos, "invalid unicode escape %q", lx.data[lx.pos:lx.pos+6])
+				}
+				sb.WriteRune(rune(r))
+				lx.pos += 4
+			default:
+				return "", lx.errorf(lx.pos, "invalid escape \\%c", esc)
+			}
+			lx.pos += 2
+		default:
+			sb.WriteByte(c)
+			lx.pos++
+		}
+	}
+	return "", lx.errorf(start, "%w: unterminated quoted string", ErrTruncatedInput)
+} //따옴표 문자열을 읽고 escape 해제
+
+type genericValueKind int
+
+const (
+	genericScalar genericValueKind = iota //bare 또는 quoted 값
+	genericList                           //[...]
+	genericRecord                         //{...}
+)
+
+type genericValue struct {
+	kind   genericValueKind
+	text   string         //scalar 값
+	quoted bool           //scalar가 따옴표로 감싸져 있었는지
+	items  []genericValue //list 원소
+	fields []genericField //record 필드
+	raw    string         //입력 원문 구간
+	offset int            //입력 내 시작 위치
+} //generic 포맷의 값
+
+type genericField struct {
+	key    string
+	value  genericValue
+	offset int
+} //key=value 한 쌍
+
+func parseGenericMessage(data []byte, lim Limits) (string, []genericField, error) {
+	lx := &genericLexer{data: data, limits: lim, depth: 1}
+	if err := lx.readVersion(); err != nil {
+		return "", nil, err
+	}
+	name, err := lx.next()
+	if err != nil {
+		return "", nil, err
+	}
+	if name.kind == tokEOF {
+		return "", nil, lx.errorf(name.offset, "%w: empty message", ErrTruncatedInput)
+	}
+	if (name.kind != tokBare && name.kind != tokQuoted) || name.text == "" {
+		return "", nil, lx.errorf(name.offset, "expected message name, got %q", name.text)
+	}
+	open, err := lx.next()
+	if err != nil {
+		return "", nil, err
+	}
+	if open.kind == tokEOF {
+		return "", nil, lx.errorf(open.offset, "%w: no '(' after message name", ErrTruncatedInput)
+	}
+	if open.kind != tokPunct || open.text != "(" {
+		return "", nil, lx.errorf(open.offset, "expected '(', got %q", open.text)
+	}
+	fields, err := lx.parseFields(")")
+	if err != nil {
+		return "", nil, err
+	}
+	if t, err := lx.next(); err != nil {
+		return "", nil, err
+	} else if t.kind != tokEOF {
+		return "", nil, lx.errorf(t.offset, "unexpected trailing input %q", t.text)
+	}
+	return name.text, fields, nil
+} //Name(k=v,...) 문자열을 메시지명과 필드 목록으로 parsing
+
+func (lx *genericLexer) readVersion() error {
+	lx.skipSpace()
+	v, n, ok := genericVersionPrefix(lx.data[lx.pos:])
+	if !ok { //표시 없음: version 1
+		return nil
+	}
+	if v != GenericGrammarVersion {
+		return lx.errorf(lx.pos, "%w: generic grammar version %d (supported: %d)", ErrUnsupportedGrammar, v, GenericGrammarVersion)
+	}
+	lx.pos += n
+	return nil
+} //"@N " version 표시를 읽고 지원하지 않는 version이면 에러
+
+func genericVersionPrefix(b []byte) (version, n int, ok bool) {
+	if len(b) == 0 || b[0] != '@' {
+		return 0, 0, false
+	}
+	i := 1
+	for i < len(b) && b[i] >= '0' && b[i] <= '9' {
+		i++
+	}
+	if i == 1 || i >= len(b) || (b[i] != ' ' && b[i] != '\t') {
+		return 0, 0, false
+	}
+	version, err := strconv.Atoi(string(b[1:i]))
+	if err != nil { //int 범위를 넘는 version
+		version = -1
+	}
+	return version, i + 1, true
+} //b 앞의 "@N " 표시에서 version과 길이 추출
+
+func (lx *genericLexer) parseFields(closer string) ([]genericField, error) {
+	var fields []genericField
+	t, err := lx.next()
+	if err != nil {
+		return nil, err
+	}
+	if t.kind == tokPunct && t.text == closer { //빈 목록
+		return fields, nil
+	}
+	lx.unread(t)
+	for {
+		key, err := lx.next()
+		if err != nil {
+			return nil, err
+		}
+		if key.kind == tokEOF {
+			return nil, lx.errorf(key.offset, "%w: expected field, missing %q", ErrTruncatedInput, closer)
+		}
+		if (key.kind != tokBare && key.kind != tokQuoted) || key.text == "" {
+			return nil, lx.errorf(key.offset, "expected field name, got %q", key.text)
+		}
+		if err := lx.limit("MaxListLen", lx.limits.MaxListLen, len(fields)+1, key.offset); err != nil {
+			return nil, err
+		}
+		if err := lx.limit("MaxStringLen", lx.limits.MaxStringLen, len(key.text), key.offset); err != nil {
+			return nil, err
+		}
+		eq, err := lx.next()
+		if err != nil {
+			return nil, err
+		}
+		if eq.kind == tokEOF {
+			return nil, lx.errorf(eq.offset, "%w: expected '=' after %q", ErrTruncatedInput, key.text)
+		}
+		if eq.kind != tokPunct || eq.text != "=" {
+			return nil, lx.errorf(eq.offset, "expected '=' after %q, got %q", key.text, eq.text)
+		}
+		val, err := lx.parseValue()
+		if err != nil {
+			if pe, ok := err.(*ParseError); ok && pe.Field == "" {
+				pe.Field = key.text //값 parsing 실패 시 필드명 기록
+			}
+			return nil, err
+		}
+		fields = append(fields, genericField{key: key.text, value: val, offset: key.offset})
+		sep, err := lx.next()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case sep.kind == tokPunct && sep.text == closer:
+			return fields, nil
+		case sep.kind == tokPunct && sep.text == ",":
+		case sep.kind == tokEOF:
+			return nil, lx.errorf(sep.offset, "%w: missing %q", ErrTruncatedInput, closer)
+		default:
+			return nil, lx.errorf(sep.offset, "expected ',' or %q, got %q", closer, sep.text)
+		}
+	}
+} //closer가 나올 때까지 key=value 목록 parsing
+
+func (lx *genericLexer) parseValue() (genericValue, error) {
+	t, err := lx.next()
+	if err != nil {
+		return genericValue{}, err
+	}
+	start := t.offset
+	if t.kind == tokBare || t.kind == tokQuoted {
+		if err := lx.limit("MaxStringLen", lx.limits.MaxStringLen, len(t.text), start); err != nil {
+			return genericValue{}, err
+		}
+	}
+	if t.kind == tokPunct && (t.text == "[" || t.text == "{") {
+		lx.depth++
+		defer func() { lx.depth-- }()
+		if err := lx.limit("MaxDepth", lx.limits.MaxDepth, lx.depth, start); err != nil {
+			return genericValue{}, err
+		}
+	}
+	switch {
+	case t.kind == tokBare:
+		return genericValue{kind: genericScalar, text: t.text, raw: t.text, offset: start}, nil
+	case t.kind == tokQuoted:
+		return genericValue{kind: genericScalar, text: t.text, quoted: true, raw: string(lx.data[start:lx.pos]), offset: start}, nil
+	case t.kind == tokPunct && t.text == "[":
+		v := genericValue{kind: genericList, offset: start}
+		n, err := lx.next()
+		if err != nil {
+			return genericValue{}, err
+		}
+		if n.kind == tokPunct && n.text == "]" {
+			v.raw = string(lx.data[start:lx.pos])
+			return v, nil
+		}
+		lx.unread(n)
+		for {
+			if err := lx.limit("MaxListLen", lx.limits.MaxListLen, len(v.items)+1, lx.pos); err != nil {
+				return genericValue{}, err
+			}
+			item, err := lx.parseValue()
+			if err != nil {
+				return genericValue{}, err
+			}
+			v.items = append(v.items, item)
+			sep, err := lx.next()
+			if err != nil {
+				return genericValue{}, err
+			}
+			switch {
+			case sep.kind == tokPunct && sep.text == "]":
+				v.raw = string(lx.data[start:lx.pos])
+				return v, nil
+			case sep.kind == tokPunct && sep.text == ",":
+			case sep.kind == tokEOF:
+				return genericValue{}, lx.errorf(sep.offset, "%w: missing ']'", ErrTruncatedInput)
+			default:
+				return genericValue{}, lx.errorf(sep.offset, "expected ',' or ']', got %q", sep.text)
+			}
+		}
+	case t.kind == tokPunct && t.text == "{":
+		fields, err := lx.parseFields("}")
+		if err != nil {
+			return genericValue{}, err
+		}
+		return genericValue{kind: genericRecord, fields: fields, raw: string(lx.data[start:lx.pos]), offset: start}, nil
+	case t.kind == tokPunct && (t.text == "," || t.text == ")" || t.text == "]" || t.text == "}"):
+		lx.unread(t) //빈 bare 값(k=)
+		return genericValue{kind: genericScalar, offset: start}, nil
+	case t.kind == tokEOF:
+		return genericValue{}, lx.errorf(t.offset, "%w: expected value", ErrTruncatedInput)
+	}
+	return genericValue{}, lx.errorf(t.offset, "unexpected %q in value", t.text)
+} //list, record, scalar 값 하나 parsing
+
+func quoteGeneric(s string) string {
+	if s != "" && s == strings.TrimSpace(s) && !strings.ContainsAny(s, genericSpecial+"\\") && !hasControl(s) {
+		return s //bare로 표현 가능
+	}
+	return quotedGeneric(s)
+} //필요할 때만 따옴표와 escape를 적용해 scalar 표기
+
+func quotedGeneric(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 { //잘못된 UTF-8 바이트는 그대로 보존
+			sb.WriteByte(s[i])
+			i++
+			continue
+		}
+		i += size
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&sb, `\u%04x`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+} //항상 따옴표와 escape를 적용해 scalar 표기
+
+func hasControl(s string) bool {
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return true
+		}
+	}
+	return false
+} //제어 문자 포함 여부
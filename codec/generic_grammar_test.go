@@ -0,0 +1,52 @@
+package codec
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"codec/abstraction"
+)
+
+func TestGenericGrammarVersion(t *testing.T) {
+	out, err := SerializeGeneric(&abstraction.AbstractMessage{Type: abstraction.MsgTypeCommit, Proposer: "node 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "@1 Commit(") {
+		t.Fatalf("SerializeGeneric = %q, want @1 version mark", out)
+	}
+	for _, in := range []string{out, "Commit(proposer=n1)", "  @1\tCommit(proposer=n1)"} {
+		if f := DetectFormat([]byte(in)); f != FormatGeneric {
+			t.Errorf("DetectFormat(%q) = %s", in, f)
+		}
+		if _, err := Parse([]byte(in), ParseOptions{Format: FormatGeneric}); err != nil {
+			t.Errorf("Parse(%q): %v", in, err)
+		}
+	}
+	for _, in := range []string{"@2 Commit(proposer=n1)", "@99999999999999999999 Commit()"} {
+		_, err := Parse([]byte(in), ParseOptions{Format: FormatGeneric})
+		var pe *ParseError
+		if !errors.Is(err, ErrUnsupportedGrammar) || !errors.As(err, &pe) || pe.Offset != 0 {
+			t.Errorf("Parse(%q) err = %v, want ErrUnsupportedGrammar at offset 0", in, err)
+		}
+	}
+} //출력에 문법 version 표시, 표시 없는 입력은 version 1, 모르는 version은 거부
+
+func TestGenericNestedPaths(t *testing.T) {
+	am, err := Parse([]byte(`Prevote(height=5, block_id={hash=0xab, parts={total=1}}, commit={seq=9, signature={signer=3}})`), ParseOptions{Format: FormatGeneric})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if am.BlockHash != "0xab" || am.Validator != "3" {
+		t.Errorf("BlockHash = %q, Validator = %q, want 0xab and 3 from nested records", am.BlockHash, am.Validator)
+	}
+	if am.Height.Int64() != 5 { //최상위 key가 경로 규칙(commit.seq)보다 우선
+		t.Errorf("Height = %v, want 5", am.Height)
+	}
+	for _, k := range []string{"block_id.parts.total", "commit.seq"} { //규칙에 쓰이지 않은 나머지 값
+		if _, ok := am.Extras[k]; !ok {
+			t.Errorf("Extras[%s] missing: %v", k, am.Extras)
+		}
+	}
+} //generic record 값에도 경로 규칙을 적용하고 남은 값은 경로 key로 Extras에 유지
@@ -1,226 +1,335 @@
-package codec
-
-import (
-	"encoding/json"
-	"fmt"
-	"math/big"
-	"time"
-
-	"codec/abstraction"
-)
-
-type jsonCodec struct{} //JSON parsing/serializing
-
-func (jsonCodec) Parse(data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error) {
-	var m map[string]interface{}
-	if err := json.Unmarshal(data, &m); err != nil {
-		return nil, fmt.Errorf("json unmarshal: %w", err)
-	}
-	am := &abstraction.AbstractMessage{
-		Extras:     map[string][]byte{},          //표준화되지 않은 필드
-		RawPayload: append([]byte(nil), data...), //원본 JSON
-	} //AbstractMessage 초기화
-	if opts.OverrideMsgType != "" { //타입 지정 시
-		am.Type = abstraction.MsgType(opts.OverrideMsgType)
-	} else if v, ok := m["type"]; ok { //JSON에 type 키 있을 시
-		if s, ok2 := v.(string); ok2 { //문자열일 때만 처리
-			if mapped, ok3 := PhaseSynonyms[s]; ok3 { //유의어 정규화
-				am.Type = abstraction.MsgType(mapped)
-			} else {
-				am.Type = abstraction.MsgType(s) //그대로 사용
-			}
-		}
-	}
-	for kRaw, v := range m { //kRaw는 원본 키
-		key := kRaw
-		if mapped, ok := FieldSynonyms[key]; ok { //유의어 정규화
-			key = mapped
-		}
-		switch key {
-		case "Height":
-			am.Height = toBigIntPtr(v)
-		case "Round":
-			am.Round = toBigIntPtr(v)
-		case "View":
-			am.View = toBigIntPtr(v)
-		case "BlockHash":
-			am.BlockHash = toString(v)
-		case "PrevHash":
-			am.PrevHash = toString(v)
-		case "Timestamp":
-			am.Timestamp = toTime(v)
-		case "Proposer":
-			am.Proposer = toString(v)
-		case "Validator":
-			am.Validator = toString(v)
-		case "Signature":
-			am.Signature = toString(v)
-		case "CommitSeals":
-			am.CommitSeals = toStringSlice(v)
-		case "ViewChanges": //원소가 객체인 JSON array
-			if arr, ok := v.([]interface{}); ok {
-				am.ViewChanges = make([]abstraction.ViewChangeEntry, 0, len(arr))
-				for _, iv := range arr {
-					if obj, ok := iv.(map[string]interface{}); ok {
-						am.ViewChanges = append(am.ViewChanges, abstraction.ViewChangeEntry{
-							View:      toBigIntPtr(obj["view"]),
-							Height:    toBigIntPtr(obj["height"]),
-							Validator: toString(obj["validator"]),
-							Signature: toString(obj["signature"]),
-						})
-					}
-				}
-			}
-		case "type":
-		default:
-			b, _ := json.Marshal(v)
-			am.Extras[kRaw] = b //표준 필드가 아닐 시 Extras
-		}
-	}
-	return am, nil //parsing 결과 반환
-} //JSON 바이트를 AbstractMessage로 변환
-
-func (jsonCodec) Serialize(am *abstraction.AbstractMessage, _ SerializeOptions) ([]byte, error) {
-	out := map[string]interface{}{
-		"type": string(am.Type),
-	}
-	if am.Height != nil { //필드가 존재할 시
-		out["height"] = am.Height.String() // big.Int는 문자열로 출력
-	}
-	if am.Round != nil {
-		out["round"] = am.Round.String()
-	}
-	if am.View != nil {
-		out["view"] = am.View.String()
-	}
-	if am.BlockHash != "" {
-		out["block_hash"] = am.BlockHash
-	}
-	if am.PrevHash != "" {
-		out["prev_hash"] = am.PrevHash
-	}
-	if !am.Timestamp.IsZero() {
-		out["timestamp"] = am.Timestamp.UTC().Format(time.RFC3339) // RFC3339로 표준화
-	}
-	if am.Proposer != "" {
-		out["proposer"] = am.Proposer
-	}
-	if am.Validator != "" {
-		out["validator"] = am.Validator
-	}
-	if am.Signature != "" {
-		out["signature"] = am.Signature
-	}
-	if len(am.CommitSeals) > 0 {
-		out["commit_seals"] = am.CommitSeals
-	}
-	if len(am.ViewChanges) > 0 {
-		vc := make([]map[string]interface{}, 0, len(am.ViewChanges))
-		for _, e := range am.ViewChanges {
-			item := map[string]interface{}{
-				"view":      strOrNil(e.View),   //nil일 시 null, 아닐 시 문자열
-				"height":    strOrNil(e.Height), //nil일 시 null, 아닐 시 문자열
-				"validator": e.Validator,
-				"signature": e.Signature,
-			}
-			vc = append(vc, item)
-		}
-		out["view_changes"] = vc
-	}
-	// Extras 병합
-	for k, v := range am.Extras {
-		if _, exists := out[k]; exists {
-			continue
-		}
-		var any interface{}
-		if err := json.Unmarshal(v, &any); err == nil {
-			out[k] = any
-		} else {
-			out[k] = string(v)
-		}
-	} //동일 key 가진 필드 중 표준 필드 우선
-	return json.Marshal(out) //JSON 바이트 반환
-} //AbstractMessage를 JSON 바이트로 변환
-
-func toString(v interface{}) string {
-	if v == nil { //nil일 시 빈 문자열
-		return ""
-	}
-	switch t := v.(type) {
-	case string:
-		return t //문자열
-	case []byte:
-		return string(t) // 바이트 → 문자열
-	default:
-		b, _ := json.Marshal(t)
-		return string(b) //JSON으로 직렬화한 바이트를 문자열로 반환
-	}
-} //interface{} 값을 문자열로 변환
-
-func toBigIntPtr(v interface{}) *big.Int {
-	if v == nil {
-		return nil
-	}
-	switch t := v.(type) {
-	case float64: //일반 JSON 숫자는 float64
-		return big.NewInt(int64(t)) //정수 부분만 사용
-	case json.Number: //UseNumber 사용 시
-		if i, err := t.Int64(); err == nil {
-			return big.NewInt(i)
-		}
-		if bi, ok := new(big.Int).SetString(t.String(), 10); ok { // 큰 정수 문자열 파싱
-			return bi
-		}
-	case string: //"1000" 등 문자열일 시
-		if bi, ok := new(big.Int).SetString(t, 10); ok {
-			return bi
-		}
-	}
-	return nil //변환 실패 시 nil
-} //JSON 수 표현을 *big.Int로 변환
-
-func toStringSlice(v interface{}) []string {
-	if v == nil {
-		return nil
-	}
-	switch t := v.(type) {
-	case []interface{}: //["a","b",...] 형태
-		out := make([]string, 0, len(t))
-		for _, e := range t {
-			out = append(out, toString(e)) //각 원소를 문자열로 변환
-		}
-		return out
-	case []string:
-		return t
-	case string:
-		return []string{t}
-	default:
-		return []string{toString(t)}
-	}
-} //interface{}를 []string으로 변환
-
-func toTime(v interface{}) time.Time {
-	switch t := v.(type) {
-	case string:
-		if tm, err := time.Parse(time.RFC3339, t); err == nil { //RFC3339
-			return tm
-		}
-		if bi, ok := new(big.Int).SetString(t, 10); ok { //epoch seconds 문자열
-			return time.Unix(bi.Int64(), 0).UTC()
-		}
-	case float64: //JSON 숫자(epoch seconds)
-		return time.Unix(int64(t), 0).UTC()
-	case json.Number: //UseNumber 사용 시
-		if i, err := t.Int64(); err == nil {
-			return time.Unix(i, 0).UTC()
-		}
-	}
-	return time.Time{} // 변환 실패 시 zero time
-} //interface{} 값을 time.Time으로 변환
-
-func strOrNil(x *big.Int) interface{} {
-	if x == nil {
-		return nil
-	}
-	return x.String()
-} //*big.Int를 nil 또는 문자열로 변환
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"sync"
+
+	"codec/abstraction"
+)
+
+type jsonCodec struct{} //JSON parsing/serializing
+
+func (jsonCodec) Parse(data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error) {
+	if src, ok := newJSONSource(data, opts); ok { //interface{} 트리 없이 입력에서 바로 정규화(Limits 안의 구조만)
+		am, err := messageFromSource(src, jsonParseOptions(opts))
+		src.release()
+		if err != nil {
+			return nil, decodeError(FormatJSON, data, err)
+		}
+		return am, nil
+	}
+	if err := checkJSONLimits(data, opts.Limits); err != nil { //트리를 만들기 전 구조 확인
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := unmarshalJSON(data, &m); err != nil { //큰 정수가 float64로 잘리지 않도록 json.Number로
+		return nil, decodeError(FormatJSON, data, err)
+	}
+	am, err := messageFromMap(m, jsonParseOptions(opts))
+	if err != nil {
+		return nil, decodeError(FormatJSON, data, err)
+	}
+	return am, nil //parsing 결과 반환
+} //JSON 바이트를 AbstractMessage로 변환
+
+func messageFromMap(m map[string]interface{}, opts ParseOptions) (*abstraction.AbstractMessage, error) {
+	src := mapSource{keys: make([]string, 0, len(m)), values: make([]interface{}, 0, len(m))}
+	for k, v := range m {
+		src.keys = append(src.keys, k)
+		src.values = append(src.values, v)
+	}
+	return messageFromSource(src, opts)
+} //디코딩된 key-value map(JSON, msgpack, protobuf 등)을 AbstractMessage로 정규화
+
+type fieldSource interface {
+	names() []string                                                          //최상위 key(중복 없음)
+	value(i int) interface{}                                                  //i번째 값의 디코딩된 트리
+	text(i int) (string, bool)                                                //문자열 값일 시 그 값
+	empty(i int) bool                                                         //null 또는 빈 문자열
+	object(i int) bool                                                        //객체(key-value) 값
+	setField(am *abstraction.AbstractMessage, field string, i int, c coercer) //표준 필드에 i번째 값 설정
+	extra(i int, format Format) abstraction.ExtraValue                        //i번째 값을 Extras 값으로
+} //최상위 key-value 목록(디코딩된 map 또는 codec이 직접 읽은 값)
+
+type mapSource struct {
+	keys   []string
+	values []interface{}
+} //디코딩된 map을 fieldSource로
+
+func (s mapSource) names() []string         { return s.keys }
+func (s mapSource) value(i int) interface{} { return s.values[i] }
+func (s mapSource) empty(i int) bool        { return s.values[i] == nil || s.values[i] == "" }
+
+func (s mapSource) text(i int) (string, bool) {
+	v, ok := s.values[i].(string)
+	return v, ok
+} //문자열 값
+
+func (s mapSource) object(i int) bool {
+	_, ok := s.values[i].(map[string]interface{})
+	return ok
+} //객체 값 여부
+
+func (s mapSource) setField(am *abstraction.AbstractMessage, field string, i int, c coercer) {
+	setField(am, field, s.values[i], c)
+} //디코딩된 값으로 표준 필드 설정
+
+func (s mapSource) extra(i int, format Format) abstraction.ExtraValue {
+	return extraValue(s.values[i], format)
+} //디코딩된 값을 ExtraValue로
+
+func messageFromSource(src fieldSource, opts ParseOptions) (*abstraction.AbstractMessage, error) {
+	names := src.names()
+	am := &abstraction.AbstractMessage{
+		OriginalFormat: string(opts.Format), //현재 format
+	} //AbstractMessage 초기화
+	typeAt := -1 //"type" key 위치
+	for i, k := range names {
+		if k == "type" {
+			typeAt = i
+		}
+	}
+	if opts.OverrideMsgType != "" { //타입 지정 시
+		am.Type = abstraction.MsgType(opts.OverrideMsgType)
+	} else if typeAt >= 0 { //type 키 있을 시
+		if s, ok := src.text(typeAt); ok { //문자열일 때만 처리
+			am.OriginalMsgName = s //원본 메시지명
+			mapped, ok3, err := opts.resolve("phase", s)
+			if err != nil { //모호한 메시지명(StrictSynonyms)
+				return nil, &ParseError{Format: opts.Format, Field: "type", Offset: -1, Err: err}
+			}
+			if ok3 { //유의어 정규화
+				am.Type = abstraction.MsgType(mapped)
+			} else {
+				am.Type = abstraction.MsgType(s) //그대로 사용
+			}
+		}
+	}
+	var scratch [24]string
+	assign := scratch[:0]
+	if len(names) > len(scratch) {
+		assign = make([]string, 0, len(names))
+	}
+	assign = assign[:len(names)]
+	//같은 필드의 유의어가 여럿일 시 우선순위로 하나만 채택(빈 값은 가장 뒤, 밀린 key는 Extras로, type은 제외)
+	if _, err := resolveKeys(names, typeAt, assign, func(i int) bool { return src.empty(i) }, opts); err != nil { //모호한 필드명(StrictSynonyms)
+		return nil, err
+	}
+	assigned := 0
+	for _, f := range assign {
+		if f != "" {
+			assigned++
+		}
+	}
+	am.OriginalFieldNames = make(map[string]string, assigned) //원본 필드명 -> 표준 필드명 매핑
+	am.Extras = make(map[string]abstraction.ExtraValue, len(names)-assigned)
+	c := newCoercer(opts)
+	var nested []string          //Extras로 간 최상위 key(경로 규칙 적용 대상)
+	for i, kRaw := range names { //kRaw는 원본 키
+		if i == typeAt {
+			continue
+		}
+		key := kRaw
+		if mapped := assign[i]; mapped != "" && !(src.object(i) && isObjectForScalar(mapped, src.value(i))) { //유의어 정규화
+			key = mapped
+			am.OriginalFieldNames[mapped] = kRaw //원본 필드명 기록
+		} else if _, known := canonicalFieldKeys[key]; known {
+			key = "" //채택되지 않은 Height 등 Go 필드명 그대로의 key는 Extras로
+		}
+		switch key {
+		case "type":
+		case "Height", "Round", "View", "BlockHash", "PrevHash", "Timestamp",
+			"Proposer", "Validator", "Signature", "CommitSeals", "ViewChanges":
+			src.setField(am, key, i, c)
+		default:
+			am.Extras[kRaw] = src.extra(i, opts.Format) //표준 필드가 아닐 시 Extras
+			nested = append(nested, kRaw)
+		}
+	}
+	extractPaths(am, nested, func(k string) interface{} { //중첩 객체에서 경로 규칙으로 표준 필드 추출
+		for i, n := range names {
+			if n == k {
+				return src.value(i)
+			}
+		}
+		return nil
+	}, c, opts)
+	return am, nil
+} //최상위 key-value 목록을 AbstractMessage로 정규화
+
+func setField(am *abstraction.AbstractMessage, field string, v interface{}, c coercer) {
+	if s, ok := v.(string); ok {
+		setFieldText(am, field, s, c)
+		return
+	}
+	switch field {
+	case "Height":
+		am.Height = c.bigInt(v)
+	case "Round":
+		am.Round = c.bigInt(v)
+	case "View":
+		am.View = c.bigInt(v)
+	case "BlockHash":
+		am.BlockHash = c.hash(v)
+	case "PrevHash":
+		am.PrevHash = c.hash(v)
+	case "Timestamp":
+		am.Timestamp = c.time(v)
+	case "Proposer":
+		am.Proposer = c.text(v)
+	case "Validator":
+		am.Validator = c.text(v)
+	case "Signature":
+		am.Signature = c.hash(v)
+	case "CommitSeals":
+		am.CommitSeals = c.hashes(v)
+	case "ViewChanges": //원소가 객체인 array
+		if arr, ok := v.([]interface{}); ok {
+			am.ViewChanges = make([]abstraction.ViewChangeEntry, 0, len(arr))
+			for _, iv := range arr {
+				if obj, ok := iv.(map[string]interface{}); ok {
+					am.ViewChanges = append(am.ViewChanges, abstraction.ViewChangeEntry{
+						View:      c.bigInt(obj["view"]),
+						Height:    c.bigInt(obj["height"]),
+						Validator: c.text(obj["validator"]),
+						Signature: c.hash(obj["signature"]),
+					})
+				}
+			}
+		}
+	}
+} //원시 값 v를 표준 필드 field(Height 등)에 설정
+
+func setFieldText(am *abstraction.AbstractMessage, field, s string, c coercer) {
+	switch field {
+	case "Height":
+		am.Height = c.parseInt(s)
+	case "Round":
+		am.Round = c.parseInt(s)
+	case "View":
+		am.View = c.parseInt(s)
+	case "BlockHash":
+		am.BlockHash = c.hashText(s)
+	case "PrevHash":
+		am.PrevHash = c.hashText(s)
+	case "Timestamp":
+		am.Timestamp = c.time(s)
+	case "Proposer":
+		am.Proposer = s
+	case "Validator":
+		am.Validator = s
+	case "Signature":
+		am.Signature = c.hashText(s)
+	case "CommitSeals":
+		am.CommitSeals = []string{c.hashText(s)}
+	}
+} //문자열 값 s를 표준 필드 field에 설정(값을 interface{}로 감싸지 않음)
+
+func isObjectForScalar(field string, v interface{}) bool {
+	if field == "CommitSeals" || field == "ViewChanges" || field == "Timestamp" && isSecondsNanos(v) {
+		return false
+	}
+	_, ok := v.(map[string]interface{})
+	return ok
+} //단일 값 필드에 객체가 온 경우(예: {"block_id":{"hash":..}}), 경로 규칙으로 처리
+
+func (jsonCodec) Serialize(am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error) {
+	out, err := messageToMap(am, opts, extraInterface)
+	if err != nil {
+		return nil, err
+	}
+	return encodeJSON(out, nil) //JSON 바이트 반환
+} //AbstractMessage를 JSON 바이트로 변환
+
+var jsonBuffers = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }} //직렬화마다 중간 버퍼를 새로 할당하지 않도록
+
+const maxPooledJSONBuffer = 1 << 16 //pool에 돌려놓는 버퍼의 최대 용량
+
+func encodeJSON(v interface{}, header func(dst []byte, n int) []byte) ([]byte, error) {
+	buf := jsonBuffers.Get().(*bytes.Buffer)
+	defer func() {
+		if buf.Cap() <= maxPooledJSONBuffer { //큰 메시지의 버퍼는 pool에 남기지 않음
+			jsonBuffers.Put(buf)
+		}
+	}()
+	buf.Reset()
+	if err := json.NewEncoder(buf).Encode(v); err != nil { //json.Marshal과 같은 출력(HTML escape 포함)
+		return nil, err
+	}
+	js := bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}) //Encode가 붙인 줄바꿈 제거
+	out := make([]byte, 0, len(js)+10)                //header(RLP/ULEB128 길이)는 최대 9바이트
+	if header != nil {
+		out = header(out, len(js))
+	}
+	return append(out, js...), nil
+} //v를 pool 버퍼에 JSON으로 쓴 뒤 header(JSON 길이로 만든 앞부분)와 함께 한 번에 할당해 반환
+
+func messageToMap(am *abstraction.AbstractMessage, opts SerializeOptions, extra func(abstraction.ExtraValue) interface{}) (map[string]interface{}, error) {
+	n, err := newNamer(am, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]interface{}{
+		"type": n.phase(),
+	}
+	if am.Height != nil { //필드가 존재할 시
+		n.put(out, "Height", am.Height.String()) // big.Int는 문자열로 출력
+	}
+	if am.Round != nil {
+		n.put(out, "Round", am.Round.String())
+	}
+	if am.View != nil {
+		n.put(out, "View", am.View.String())
+	}
+	if am.BlockHash != "" {
+		n.put(out, "BlockHash", am.BlockHash)
+	}
+	if am.PrevHash != "" {
+		n.put(out, "PrevHash", am.PrevHash)
+	}
+	if !am.Timestamp.IsZero() {
+		n.put(out, "Timestamp", opts.TimestampFormat.value(am.Timestamp)) //기본값 RFC3339Nano
+	}
+	if am.Proposer != "" {
+		n.put(out, "Proposer", am.Proposer)
+	}
+	if am.Validator != "" {
+		n.put(out, "Validator", am.Validator)
+	}
+	if am.Signature != "" {
+		n.put(out, "Signature", am.Signature)
+	}
+	if len(am.CommitSeals) > 0 {
+		n.put(out, "CommitSeals", am.CommitSeals)
+	}
+	if len(am.ViewChanges) > 0 {
+		vc := make([]map[string]interface{}, 0, len(am.ViewChanges))
+		for _, e := range am.ViewChanges {
+			item := map[string]interface{}{
+				"view":      strOrNil(e.View),   //nil일 시 null, 아닐 시 문자열
+				"height":    strOrNil(e.Height), //nil일 시 null, 아닐 시 문자열
+				"validator": e.Validator,
+				"signature": e.Signature,
+			}
+			vc = append(vc, item)
+		}
+		n.put(out, "ViewChanges", vc)
+	}
+	// Extras 병합
+	for k, v := range am.Extras {
+		if _, exists := out[k]; exists {
+			continue
+		}
+		out[k] = extra(v)
+	} //동일 key 가진 필드 중 표준 필드 우선
+	return out, nil
+} //AbstractMessage를 key-value map으로 변환(extra로 Extras 값 표현 결정)
+
+func strOrNil(x *big.Int) interface{} {
+	if x == nil {
+		return nil
+	}
+	return x.String()
+} //*big.Int를 nil 또는 문자열로 변환
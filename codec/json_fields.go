@@ -0,0 +1,486 @@
+package codec
+
+import (
+	"math/big"
+	"sync"
+	"unicode/utf8"
+
+	"codec/abstraction"
+)
+
+const jsonDirectDepth = 64 //직접 읽는 최대 중첩 깊이(더 깊으면 unmarshalJSON 경로)
+
+type jsonEntry struct {
+	start, end int  //값의 입력 내 범위(앞뒤 공백 제외)
+	plain      bool //escape/잘못된 UTF-8이 없는 문자열(내용을 그대로 사용)
+} //최상위 객체의 값 하나
+
+type jsonSource struct {
+	data []byte
+	keys []string
+	vals []jsonEntry
+	dict map[string]string //사전에 있는 이름은 새로 할당하지 않음
+	seen map[string]int    //항목이 많은 객체의 중복 key 확인
+	ints *intBlock         //parsing 중인 메시지의 최상위 정수 필드
+} //디코딩하지 않은 최상위 객체(값은 필요할 때 입력에서 바로 읽음)
+
+var jsonSources = sync.Pool{New: func() interface{} { return new(jsonSource) }} //parsing마다 key/범위 slice를 새로 할당하지 않도록
+
+func newJSONSource(data []byte, opts ParseOptions) (*jsonSource, bool) {
+	s := jsonSources.Get().(*jsonSource)
+	s.data, s.keys, s.vals = data, s.keys[:0], s.vals[:0]
+	s.dict, s.ints = opts.synonyms().index().names, nil
+	clear(s.seen)
+	if !s.read(opts.Limits) {
+		s.release()
+		return nil, false
+	}
+	return s, true
+} //최상위 객체의 key와 값 범위(중복 key는 마지막 값, 객체가 아니거나 unmarshalJSON과 같게 읽는다고 확인할 수 없는 입력, Limits를 넘는 값이 있을 시 false)
+
+func (s *jsonSource) read(l Limits) bool {
+	data := s.data
+	pos := skipJSONSpace(data, 0)
+	if pos >= len(data) || data[pos] != '{' || !jsonWithin(1, l) {
+		return false
+	}
+	if pos = skipJSONSpace(data, pos+1); pos < len(data) && data[pos] == '}' {
+		return skipJSONSpace(data, pos+1) == len(data)
+	}
+	for n := 1; ; n++ {
+		kEnd, plain, ok := skipJSONString(data, pos, l)
+		if !ok || !plain || l.check("MaxListLen", l.MaxListLen, n, "") != nil { //escape된 key는 unmarshalJSON 경로
+			return false
+		}
+		key := data[pos+1 : kEnd-1]
+		if pos = skipJSONSpace(data, kEnd); pos >= len(data) || data[pos] != ':' {
+			return false
+		}
+		start := skipJSONSpace(data, pos+1)
+		end, plain, ok := skipJSON(data, start, 2, l)
+		if !ok {
+			return false
+		}
+		s.put(s.key(key), jsonEntry{start, end, plain})
+		switch pos = skipJSONSpace(data, end); {
+		case pos < len(data) && data[pos] == '}':
+			return skipJSONSpace(data, pos+1) == len(data) //값 뒤에 남은 데이터
+		case pos >= len(data) || data[pos] != ',':
+			return false
+		}
+		pos = skipJSONSpace(data, pos+1)
+	}
+} //입력 전체가 객체 하나인지 확인하며 key와 값 범위 기록
+
+func (s *jsonSource) put(key string, v jsonEntry) {
+	if len(s.keys) >= 16 { //key가 많으면 map으로 중복 확인
+		if s.seen == nil {
+			s.seen = make(map[string]int, 2*len(s.keys))
+		}
+		if len(s.seen) == 0 {
+			for i, k := range s.keys {
+				s.seen[k] = i
+			}
+		}
+		if i, dup := s.seen[key]; dup {
+			s.vals[i] = v
+			return
+		}
+		s.seen[key] = len(s.keys)
+	} else {
+		for i, k := range s.keys {
+			if k == key {
+				s.vals[i] = v
+				return
+			}
+		}
+	}
+	s.keys = append(s.keys, key)
+	s.vals = append(s.vals, v)
+} //key 추가(이미 있을 시 값 범위만 교체)
+
+func (s *jsonSource) key(b []byte) string {
+	if k, ok := s.dict[string(b)]; ok {
+		return k
+	}
+	return string(b)
+} //바이트를 문자열로(사전에 있는 이름은 할당 없이)
+
+func (s *jsonSource) release() {
+	clear(s.keys)
+	clear(s.seen)
+	s.data, s.dict, s.ints = nil, nil, nil
+	jsonSources.Put(s)
+} //pool에 반환(입력/key 참조 해제)
+
+func jsonWithin(depth int, l Limits) bool {
+	return depth <= jsonDirectDepth && l.check("MaxDepth", l.MaxDepth, depth, "") == nil
+} //checkJSONLimits가 통과시키는 깊이인지 확인(넘을 시 그쪽에서 에러 보고)
+
+func skipJSONSpace(data []byte, pos int) int {
+	for pos < len(data) && isJSONSpace(data[pos]) {
+		pos++
+	}
+	return pos
+} //pos부터 공백을 건너뛴 위치
+
+func skipJSON(data []byte, pos, depth int, l Limits) (int, bool, bool) {
+	if pos >= len(data) {
+		return 0, false, false
+	}
+	switch c := data[pos]; c {
+	case '"':
+		return skipJSONString(data, pos, l)
+	case '{', '[':
+		if !jsonWithin(depth, l) {
+			return 0, false, false
+		}
+		closing := byte(']')
+		if c == '{' {
+			closing = '}'
+		}
+		if pos = skipJSONSpace(data, pos+1); pos < len(data) && data[pos] == closing {
+			return pos + 1, false, true
+		}
+		for n := 1; ; n++ {
+			if l.check("MaxListLen", l.MaxListLen, n, "") != nil {
+				return 0, false, false
+			}
+			if c == '{' {
+				end, _, ok := skipJSONString(data, pos, l)
+				if !ok {
+					return 0, false, false
+				}
+				if pos = skipJSONSpace(data, end); pos >= len(data) || data[pos] != ':' {
+					return 0, false, false
+				}
+				pos = skipJSONSpace(data, pos+1)
+			}
+			end, _, ok := skipJSON(data, pos, depth+1, l)
+			if !ok {
+				return 0, false, false
+			}
+			switch pos = skipJSONSpace(data, end); {
+			case pos < len(data) && data[pos] == closing:
+				return pos + 1, false, true
+			case pos >= len(data) || data[pos] != ',':
+				return 0, false, false
+			}
+			pos = skipJSONSpace(data, pos+1)
+		}
+	case 't':
+		return skipJSONLiteral(data, pos, "true")
+	case 'f':
+		return skipJSONLiteral(data, pos, "false")
+	case 'n':
+		return skipJSONLiteral(data, pos, "null")
+	}
+	return skipJSONNumber(data, pos)
+} //pos의 값을 건너뛴 위치와 escape 없는 문자열 여부(depth는 값이 객체/배열일 때의 깊이, 잘못된 JSON이거나 Limits를 넘을 시 false)
+
+func skipJSONLiteral(data []byte, pos int, lit string) (int, bool, bool) {
+	if len(data)-pos < len(lit) || string(data[pos:pos+len(lit)]) != lit {
+		return 0, false, false
+	}
+	return pos + len(lit), false, true
+} //true/false/null
+
+func skipJSONString(data []byte, pos int, l Limits) (int, bool, bool) {
+	if pos >= len(data) || data[pos] != '"' {
+		return 0, false, false
+	}
+	start, plain, ascii := pos+1, true, true
+	for pos = start; pos < len(data); pos++ {
+		switch c := data[pos]; {
+		case c == '"':
+			if l.check("MaxStringLen", l.MaxStringLen, pos-start, "") != nil { //escape가 있을 시 디코딩된 길이는 더 짧으므로 보수적으로
+				return 0, false, false
+			}
+			if !ascii && !utf8.Valid(data[start:pos]) { //잘못된 UTF-8의 치환은 decoder 규칙으로
+				return 0, false, false
+			}
+			return pos + 1, plain, true
+		case c < 0x20:
+			return 0, false, false
+		case c >= 0x80:
+			ascii = false
+		case c == '\\':
+			plain = false
+			if pos++; pos >= len(data) {
+				return 0, false, false
+			}
+			switch data[pos] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			case 'u':
+				if len(data)-pos <= 4 || !isHex(string(data[pos+1:pos+5])) {
+					return 0, false, false
+				}
+				pos += 4
+			default:
+				return 0, false, false
+			}
+		}
+	}
+	return 0, false, false
+} //pos의 문자열을 건너뛴 위치와 escape 없는 문자열 여부
+
+func skipJSONNumber(data []byte, pos int) (int, bool, bool) {
+	digits := func() bool {
+		start := pos
+		for pos < len(data) && data[pos] >= '0' && data[pos] <= '9' {
+			pos++
+		}
+		return pos > start
+	}
+	if pos < len(data) && data[pos] == '-' {
+		pos++
+	}
+	if pos < len(data) && data[pos] == '0' {
+		pos++
+	} else if !digits() {
+		return 0, false, false
+	}
+	if pos < len(data) && data[pos] == '.' {
+		pos++
+		if !digits() {
+			return 0, false, false
+		}
+	}
+	if pos < len(data) && (data[pos] == 'e' || data[pos] == 'E') {
+		if pos++; pos < len(data) && (data[pos] == '+' || data[pos] == '-') {
+			pos++
+		}
+		if !digits() {
+			return 0, false, false
+		}
+	}
+	return pos, false, true
+} //pos의 수를 건너뛴 위치(JSON 수 문법)
+
+func (s *jsonSource) names() []string { return s.keys }
+
+func (s *jsonSource) token(i int) []byte {
+	return s.data[s.vals[i].start:s.vals[i].end]
+} //i번째 값의 입력 바이트
+
+func (s *jsonSource) value(i int) interface{} {
+	var v interface{}
+	_ = unmarshalJSON(s.token(i), &v) //범위는 skipJSON에서 확인됨
+	return v
+} //i번째 값을 unmarshalJSON과 같은 트리로
+
+func (s *jsonSource) text(i int) (string, bool) {
+	tok := s.token(i)
+	if tok[0] != '"' {
+		return "", false
+	}
+	if s.vals[i].plain {
+		return s.key(tok[1 : len(tok)-1]), true //메시지명 등 사전에 있는 값은 할당 없이
+	}
+	var str string
+	_ = unmarshalJSON(tok, &str)
+	return str, true
+} //문자열 값
+
+func (s *jsonSource) empty(i int) bool {
+	tok := string(s.token(i))
+	return tok == "null" || tok == `""`
+} //null 또는 빈 문자열
+
+func (s *jsonSource) object(i int) bool {
+	return s.token(i)[0] == '{'
+} //객체 값 여부
+
+func (s *jsonSource) setField(am *abstraction.AbstractMessage, field string, i int, c coercer) {
+	if !s.setDirect(am, field, s.token(i), s.vals[i].plain, c) {
+		setField(am, field, s.value(i), c) //디코딩한 트리로
+	}
+} //i번째 값으로 표준 필드 설정
+
+func (s *jsonSource) setDirect(am *abstraction.AbstractMessage, field string, tok []byte, plain bool, c coercer) bool {
+	if tok[0] == '"' && plain && field != "Height" && field != "Round" && field != "View" {
+		setFieldText(am, field, string(tok[1:len(tok)-1]), c)
+		return true
+	}
+	var ok bool
+	switch field {
+	case "Height":
+		am.Height, ok = s.topInts().jsonInt(tok, plain, c)
+	case "Round":
+		am.Round, ok = s.topInts().jsonInt(tok, plain, c)
+	case "View":
+		am.View, ok = s.topInts().jsonInt(tok, plain, c)
+	case "BlockHash":
+		am.BlockHash, ok = jsonHash(tok, plain, c)
+	case "PrevHash":
+		am.PrevHash, ok = jsonHash(tok, plain, c)
+	case "Proposer":
+		am.Proposer, ok = jsonText(tok, plain)
+	case "Validator":
+		am.Validator, ok = jsonText(tok, plain)
+	case "Signature":
+		am.Signature, ok = jsonHash(tok, plain, c)
+	case "CommitSeals":
+		am.CommitSeals, ok = jsonHashes(tok, plain, c)
+	case "ViewChanges":
+		am.ViewChanges, ok = jsonViewChanges(tok, c)
+	}
+	return ok
+} //값을 interface{} 트리 없이 설정(setField와 같은 결과가 확실하지 않을 시 false)
+
+func (s *jsonSource) topInts() *intBlock {
+	if s.ints == nil {
+		s.ints = newTopInts()
+	}
+	return s.ints
+} //최상위 정수 필드용 블록(메시지마다 필요할 때 한 번 할당)
+
+func (b *intBlock) jsonInt(tok []byte, plain bool, c coercer) (*big.Int, bool) {
+	switch tok[0] {
+	case '"':
+		if !plain {
+			return nil, false
+		}
+		return b.parse(tok[1:len(tok)-1], c), true
+	case 'n', 't', 'f', '[', '{': //coercer.bigInt는 null/bool/배열/객체를 nil로
+		return nil, true
+	}
+	return b.parse(tok, c), true //json.Number는 문자열과 같이 parsing
+} //coercer.bigInt와 같은 값(int64 범위는 블록에서)
+
+func (b *intBlock) parse(s []byte, c coercer) *big.Int {
+	if x, ok := smallDecimal(string(s)); ok { //임시 문자열 없이
+		return b.new(x)
+	}
+	return c.parseInt(string(s))
+} //coercer.parseInt와 같은 값
+
+func jsonText(tok []byte, plain bool) (string, bool) {
+	switch tok[0] {
+	case '"':
+		return string(tok[1 : len(tok)-1]), plain
+	case 'n':
+		return "", true
+	case 't':
+		return "true", true
+	case 'f':
+		return "false", true
+	case '[', '{': //coercer.text는 JSON으로 다시 직렬화
+		return "", false
+	}
+	return string(tok), true //json.Number 원문
+} //coercer.text와 같은 변환
+
+func jsonHash(tok []byte, plain bool, c coercer) (string, bool) {
+	if tok[0] == '"' && plain {
+		return c.hashText(string(tok[1 : len(tok)-1])), true
+	}
+	return jsonText(tok, plain)
+} //coercer.hash와 같은 변환
+
+func jsonHashes(tok []byte, plain bool, c coercer) ([]string, bool) {
+	switch tok[0] {
+	case 'n':
+		return nil, true
+	case '{':
+		return nil, false
+	case '[':
+		out := []string{}
+		for it := jsonItems(tok); ; {
+			_, v, vPlain, ok := it.next()
+			if !ok {
+				return out, true
+			}
+			h, ok := jsonHash(v, vPlain, c)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, h)
+		}
+	}
+	h, ok := jsonHash(tok, plain, c)
+	return []string{h}, ok
+} //해시 배열(commit seal 등)
+
+func jsonViewChanges(tok []byte, c coercer) ([]abstraction.ViewChangeEntry, bool) {
+	if tok[0] != '[' { //배열이 아닌 값은 무시(setField와 같음)
+		return nil, true
+	}
+	n := 0
+	for it := jsonItems(tok); ; n++ {
+		if _, _, _, ok := it.next(); !ok {
+			break
+		}
+	}
+	out := make([]abstraction.ViewChangeEntry, 0, n)
+	ints := newIntBlock(2 * n)
+	for it := jsonItems(tok); ; {
+		_, e, _, ok := it.next()
+		if !ok {
+			return out, true
+		}
+		if e[0] != '{' { //객체가 아닌 원소는 건너뜀
+			continue
+		}
+		var vc abstraction.ViewChangeEntry
+		for fields := jsonItems(e); ; {
+			k, v, plain, ok := fields.next()
+			if !ok {
+				break
+			}
+			key, keyPlain := plainJSONString(k)
+			if !keyPlain { //escape된 key는 트리 경로로
+				return nil, false
+			}
+			switch string(key) { //같은 key는 마지막 값
+			case "view":
+				vc.View, ok = ints.jsonInt(v, plain, c)
+			case "height":
+				vc.Height, ok = ints.jsonInt(v, plain, c)
+			case "validator":
+				vc.Validator, ok = jsonText(v, plain)
+			case "signature":
+				vc.Signature, ok = jsonHash(v, plain, c)
+			}
+			if !ok {
+				return nil, false
+			}
+		}
+		out = append(out, vc)
+	}
+} //{view, height, validator, signature} 객체 배열
+
+type jsonIter struct {
+	data []byte
+	pos  int
+} //이미 확인된 객체/배열의 원소 순회
+
+func jsonItems(tok []byte) jsonIter {
+	return jsonIter{data: tok, pos: skipJSONSpace(tok, 1)}
+} //tok(객체/배열)의 첫 원소부터
+
+func (it *jsonIter) next() ([]byte, []byte, bool, bool) {
+	data := it.data
+	if it.pos >= len(data) || data[it.pos] == ']' || data[it.pos] == '}' {
+		return nil, nil, false, false
+	}
+	var key []byte
+	if data[0] == '{' {
+		end, _, _ := skipJSONString(data, it.pos, Limits{})
+		key = data[it.pos:end]
+		it.pos = skipJSONSpace(data, skipJSONSpace(data, end)+1) //':' 다음
+	}
+	start := it.pos
+	end, plain, _ := skipJSON(data, start, 0, Limits{})
+	if it.pos = skipJSONSpace(data, end); data[it.pos] == ',' {
+		it.pos = skipJSONSpace(data, it.pos+1)
+	}
+	return key, data[start:end], plain, true
+} //다음 원소의 key(배열은 nil), 값, escape 없는 문자열 여부
+
+func (s *jsonSource) extra(i int, format Format) abstraction.ExtraValue {
+	if e, ok := jsonExtra(s.token(i), format); ok {
+		return e
+	}
+	return extraValue(s.value(i), format)
+} //i번째 값을 ExtraValue로
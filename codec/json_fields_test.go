@@ -0,0 +1,227 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	bcs "github.com/fardream/go-bcs/bcs"
+)
+
+var jsonDirectOptions = []ParseOptions{
+	{Format: FormatJSON},
+	{Format: FormatJSON, HashEncoding: HashBase64, DetectBase64: true},
+	{Format: FormatJSON, HashEncoding: HashPreserve, ExactSynonyms: true, Limits: DefaultLimits},
+} //직접 경로와 트리 경로를 비교할 옵션
+
+func checkJSONDirect(t *testing.T, name string, data []byte) bool {
+	t.Helper()
+	used := false
+	for _, opts := range jsonDirectOptions {
+		src, ok := newJSONSource(data, opts)
+		if !ok {
+			continue //unmarshalJSON 경로 사용
+		}
+		used = true
+		direct, directErr := messageFromSource(src, opts)
+		src.release()
+		var decoded map[string]interface{}
+		if err := unmarshalJSON(data, &decoded); err != nil {
+			t.Fatalf("%s: direct path accepted input unmarshalJSON rejects: %v", name, err)
+		}
+		if err := checkJSONLimits(data, opts.Limits); err != nil {
+			t.Fatalf("%s: direct path accepted input over the limits: %v", name, err)
+		}
+		want, wantErr := messageFromMap(decoded, opts)
+		if (directErr != nil) != (wantErr != nil) {
+			t.Fatalf("%s: direct error %v, tree error %v", name, directErr, wantErr)
+		}
+		if !identicalMessage(direct, want) {
+			t.Fatalf("%s: direct path differs from unmarshalJSON\n direct: %#v\n   want: %#v", name, direct, want)
+		}
+	}
+	return used
+} //직접 읽은 결과가 unmarshalJSON 트리를 정규화한 결과와 같은지 확인(직접 경로를 사용했는지 반환)
+
+func TestJSONDirectMatchesUnmarshal(t *testing.T) {
+	fuzzSetup(t)
+	for i, am := range fuzzFixtures() {
+		data, err := Serialize(am, fuzzSerializeOptions(FormatJSON))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !checkJSONDirect(t, "fixture", data) {
+			t.Errorf("fixture %d fell back to unmarshalJSON", i)
+		}
+	}
+	for _, size := range benchSizes {
+		data, err := Serialize(benchMessage(size.seals, size.changes, size.extras), fuzzSerializeOptions(FormatJSON))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !checkJSONDirect(t, size.name, data) {
+			t.Errorf("%s: benchmark message fell back to unmarshalJSON", size.name)
+		}
+	}
+	for _, tc := range goldenCases {
+		if tc.opts.Format != FormatJSON {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(goldenDir, tc.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !checkJSONDirect(t, tc.file, data) {
+			t.Errorf("%s fell back to unmarshalJSON", tc.file)
+		}
+	}
+	cases := []struct {
+		name   string
+		data   string
+		direct bool
+	}{
+		{"numbers", `{"type":"commit","height":5,"round":"-3","view":1e2,"proposer":12.50,"signature":-0,"extra":[1.5,-2,3e-4]}`, true},
+		{"big numbers", `{"height":123456789012345678901234567890,"round":"0x10","view":9223372036854775807}`, true},
+		{"scalars", `{"height":true,"round":null,"view":[1],"block_hash":false,"validator":null,"proposer":true,"timestamp":1710408413}`, true},
+		{"whitespace", " {\n\t\"height\" : 1 ,\r\n \"extra\" : { \"a\" : [ 1 , \"b\" ] } } \n", true},
+		{"escapes", `{"type":"pre\u0070are","proposer":"a\nb","block_hash":"\u0030x01","commit_seals":["\u0031"],"extra":"\u00e9"}`, true},
+		{"seals", `{"commit_seals":["0x01",2,null,true,"AQ=="]}`, true},
+		{"seal scalar", `{"commit_seals":"0x01"}`, true},
+		{"seal nested", `{"commit_seals":[{"a":1}]}`, true},
+		{"seal object", `{"commit_seals":{"a":1}}`, true},
+		{"view changes", `{"view_changes":[{"view":1,"height":"0x10","validator":7,"signature":null,"other":[1]},"skipped",{"view":[1],"height":{"a":1}},{"view":1,"view":2},{"height":"\u0031"}]}`, true},
+		{"view changes tree", `{"view_changes":[{"view":1},{"validator":[1]}]}`, true},
+		{"view changes escaped key", `{"view_changes":[{"v\u0069ew":3}]}`, true},
+		{"view changes scalar", `{"view_changes":"x"}`, true},
+		{"nested paths", `{"header":{"height":9,"time":"2024-03-14T09:26:53Z"},"block_id":{"hash":"0x01"},"extras":{"k":[{"x":null}]}}`, true},
+		{"empty values", `{"height":"","block_number":"7","round":null,"view":""}`, true},
+		{"duplicate key", `{"height":1,"height":2}`, true},
+		{"many keys", `{"k0":0,"k1":1,"k2":2,"k3":3,"k4":4,"k5":5,"k6":6,"k7":7,"k8":8,"k9":9,"k10":10,"k11":11,"k12":12,"k13":13,"k14":14,"k15":15,"k16":16,"k3":33,"height":1,"k16":-1}`, true},
+		{"type not string", `{"type":1,"height":1}`, true},
+		{"empty object", `{}`, true},
+		{"escaped key", `{"h\u0065ight":1}`, false},
+		{"invalid utf8", "{\"proposer\":\"\xff\"}", false},
+		{"trailing data", `{"height":1}x`, false},
+		{"trailing comma", `{"height":1,}`, false},
+		{"leading zero", `{"height":01}`, false},
+		{"bad escape", `{"proposer":"\x"}`, false},
+		{"control character", "{\"proposer\":\"a\tb\"}", false},
+		{"array", `[1]`, false},
+		{"truncated", `{"height":1`, false},
+	}
+	for _, tc := range cases {
+		if got := checkJSONDirect(t, tc.name, []byte(tc.data)); got != tc.direct {
+			t.Errorf("%s: direct path used = %v, want %v", tc.name, got, tc.direct)
+		}
+	}
+} //fixture/벤치마크/golden/경계 입력의 JSON 직접 읽기 결과 비교
+
+func TestJSONDirectLimits(t *testing.T) {
+	l := Limits{MaxDepth: 2, MaxListLen: 2, MaxStringLen: 4}
+	for _, data := range []string{`{"a":{"b":{}}}`, `{"a":1,"b":2,"c":3}`, `{"a":[1,2,3]}`, `{"a":"12345"}`, `{"abcde":1}`, `{"a":"\u0031"}`} {
+		if src, ok := newJSONSource([]byte(data), ParseOptions{Format: FormatJSON, Limits: l}); ok {
+			src.release()
+			t.Errorf("%s: direct path accepted input over %+v", data, l)
+		}
+	}
+	src, ok := newJSONSource([]byte(`{"a":[{"b":"1234"}],"c":1}`), ParseOptions{Format: FormatJSON, Limits: Limits{MaxDepth: 3, MaxListLen: 2, MaxStringLen: 4}})
+	if !ok {
+		t.Fatal("direct path rejected input within the limits")
+	}
+	src.release()
+} //Limits를 넘는 입력은 checkJSONLimits 경로에서 에러 보고
+
+func FuzzJSONDirect(f *testing.F) {
+	fuzzSeed(f, FormatJSON)
+	for _, size := range benchSizes {
+		b, err := Serialize(benchMessage(size.seals, size.changes, size.extras), fuzzSerializeOptions(FormatJSON))
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		checkJSONDirect(t, "fuzz", data)
+	})
+} //임의 입력에서 직접 경로가 unmarshalJSON 경로와 같은 결과인지 확인
+
+func checkJSONExtra(t *testing.T, b []byte) bool {
+	t.Helper()
+	got, ok := jsonExtra(b, FormatProtobuf)
+	if !ok {
+		return false
+	}
+	var decoded interface{}
+	if err := unmarshalJSON(b, &decoded); err != nil {
+		t.Fatalf("%q: jsonExtra accepted input the decoder rejects: %v", b, err)
+	}
+	if want := extraValue(decoded, FormatProtobuf); !reflect.DeepEqual(got, want) {
+		t.Fatalf("%q: jsonExtra = %#v, decoder = %#v", b, got, want)
+	}
+	return true
+} //decoder 없이 읽은 값이 unmarshalJSON 결과의 extraValue와 같은지 확인(직접 읽었는지 반환)
+
+var jsonExtraCases = []struct {
+	in     string
+	direct bool
+}{
+	{`"a"`, true}, {`-12`, true}, {`0`, true}, {`-0`, true}, {`123456789012345678901234567890`, true}, {`null`, true}, {`[]`, true}, {`{}`, true},
+	{`["a",1,true,null,[],{"k":[{"x":"y"}]}]`, true}, {`{"a":1,"b":{"c":[false]}}`, true},
+	{`1.5`, false}, {" [1, \n2] ", true}, {`{ "a" : [ ] }`, true}, {`{"a":1,"a":2}`, false}, {`"\u0041"`, false}, {`{"a\"":1}`, false},
+	{`[1,]`, false}, {`[1`, false}, {`{"a"}`, false}, {`{"a":}`, false}, {`01`, false}, {`[1]x`, false}, {`"a`, false}, {`tru`, false},
+} //직접 읽을 표기와 decoder에 맡길 표기
+
+func TestJSONExtraMatchesDecoder(t *testing.T) {
+	for _, tc := range jsonExtraCases {
+		if got := checkJSONExtra(t, []byte(tc.in)); got != tc.direct {
+			t.Errorf("%s: read directly = %v, want %v", tc.in, got, tc.direct)
+		}
+	}
+} //packed extras 표기의 직접 읽기 결과 비교
+
+func FuzzJSONExtra(f *testing.F) {
+	for _, tc := range jsonExtraCases {
+		f.Add([]byte(tc.in))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		checkJSONExtra(t, b)
+	})
+} //임의 입력에서 jsonExtra가 decoder 경로와 같은 결과인지 확인
+
+func TestEncodeJSONMatchesMarshal(t *testing.T) {
+	values := []interface{}{"", "<a&b>", map[string]interface{}{"k": json.Number("1")}}
+	for _, n := range []int{1, 54, 55, 56, 127, 128, 255, 256, 16383, 16384, 70000} {
+		values = append(values, strings.Repeat("x", n)) //RLP/ULEB128 길이 경계
+	}
+	for _, am := range fuzzFixtures() {
+		if m, err := messageToMap(am, fuzzSerializeOptions(FormatJSON), extraInterface); err == nil {
+			values = append(values, m)
+		}
+	}
+	for _, v := range values {
+		js, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantRLP, _ := rlp.EncodeToBytes(js)
+		wantBCS, _ := bcs.Marshal(js)
+		for _, c := range []struct {
+			name   string
+			header func([]byte, int) []byte
+			want   []byte
+		}{{"json", nil, js}, {"rlp", rlpStringHeader, wantRLP}, {"bcs", bcsLengthPrefix, wantBCS}} {
+			got, err := encodeJSON(v, c.header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, c.want) {
+				t.Errorf("%s: len %d: encodeJSON differs from Marshal\n got: %.80x\nwant: %.80x", c.name, len(js), got, c.want)
+			}
+		}
+	}
+} //pool 버퍼로 쓴 JSON/RLP/BCS 출력이 json.Marshal 후 rlp/bcs 인코딩한 결과와 같은지 확인
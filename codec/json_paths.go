@@ -0,0 +1,241 @@
+package codec
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"codec/abstraction"
+)
+
+type pathRule struct {
+	pattern string   //원본 규칙 문자열(예: signatures[*].sig)
+	tokens  []string //key 또는 "[*]", "[N]" 단위로 분해한 규칙
+	folded  []string //FoldName을 적용한 tokens(비교 시마다 정규화하지 않도록)
+	field   string   //표준 필드명
+} //중첩 JSON 경로 -> 표준 필드 규칙
+
+type pathRules struct {
+	rules []pathRule
+	heads map[string]bool //규칙 첫 token(원문과 FoldName 결과)
+	wild  bool            //첫 token이 "*"인 규칙 존재
+} //컴파일된 경로 규칙(SynonymSet의 정규화 index에 캐시)
+
+type pathMatch struct {
+	path   string      //실제 경로(예: signatures[0].sig)
+	tokens []string    //path의 token(일치한 노드만)
+	value  interface{} //일치한 값
+	rule   pathRule
+} //규칙과 일치한 JSON 노드
+
+func compilePathRules(rules map[string]string) *pathRules {
+	out := &pathRules{rules: make([]pathRule, 0, len(rules)), heads: map[string]bool{}}
+	for _, p := range sortedMapKeys(rules) {
+		toks := splitPath(p)
+		folded := make([]string, len(toks))
+		for i, t := range toks {
+			folded[i] = t
+			if !strings.HasPrefix(t, "[") && t != "*" {
+				folded[i] = FoldName(t)
+			}
+		}
+		out.rules = append(out.rules, pathRule{pattern: p, tokens: toks, folded: folded, field: rules[p]})
+		if len(toks) > 0 {
+			out.heads[toks[0]] = true
+			out.heads[folded[0]] = true
+			out.wild = out.wild || toks[0] == "*"
+		}
+	}
+	return out
+} //규칙 문자열을 token 단위로 분해(정렬된 순서)
+
+func (r *pathRules) reachable(top string, exact bool) bool {
+	if r.wild || r.heads[top] {
+		return true
+	}
+	var buf [64]byte
+	return !exact && r.heads[string(appendFold(buf[:0], top))]
+} //최상위 key top 아래에 일치할 수 있는 규칙이 있는지 확인
+
+func splitPath(p string) []string {
+	var toks []string
+	for _, seg := range strings.Split(p, ".") {
+		for seg != "" {
+			i := strings.IndexByte(seg, '[')
+			if i < 0 {
+				toks = append(toks, seg)
+				break
+			}
+			if i > 0 {
+				toks = append(toks, seg[:i])
+			}
+			j := strings.IndexByte(seg[i:], ']')
+			if j < 0 { //닫는 괄호 없을 시 key로 취급
+				toks = append(toks, seg[i:])
+				break
+			}
+			toks = append(toks, seg[i:i+j+1])
+			seg = seg[i+j+1:]
+		}
+	}
+	return toks
+} //"a.b[*].c" -> ["a", "b", "[*]", "c"]
+
+func joinPath(toks []string) string {
+	var sb strings.Builder
+	for i, t := range toks {
+		if i > 0 && !strings.HasPrefix(t, "[") {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(t)
+	}
+	return sb.String()
+} //["a", "b", "[0]", "c"] -> "a.b[0].c"
+
+func plainPath(toks []string) bool {
+	for _, t := range toks {
+		if t == "" || !strings.HasPrefix(t, "[") && strings.ContainsAny(t, ".[]") {
+			return false
+		}
+	}
+	return true
+} //key token에 '.', '[', ']'가 없어 splitPath(joinPath(toks))가 toks로 돌아오는지 확인
+
+func (r *pathRule) match(toks, folded []string, exact bool) bool {
+	if len(r.tokens) != len(toks) {
+		return false
+	}
+	for i, rt := range r.tokens {
+		t := toks[i]
+		isIndex := strings.HasPrefix(t, "[")
+		switch {
+		case rt == "[*]": //임의의 배열 원소
+			if !isIndex {
+				return false
+			}
+		case rt == "*": //임의의 객체 key
+			if isIndex {
+				return false
+			}
+		case strings.HasPrefix(rt, "["): //특정 배열 원소
+			if rt != t {
+				return false
+			}
+		case isIndex:
+			return false
+		case exact:
+			if rt != t {
+				return false
+			}
+		default:
+			if r.folded[i] != folded[i] { //대소문자/구분자 정규화 비교
+				return false
+			}
+		}
+	}
+	return true
+} //경로 token이 규칙과 일치하는지 확인
+
+func collectPathMatches(toks, folded []string, v interface{}, rules *pathRules, exact bool, matches *[]pathMatch, rest *[]pathMatch) {
+	for i := range rules.rules {
+		if r := &rules.rules[i]; r.match(toks, folded, exact) { //일치한 노드는 더 내려가지 않음
+			*matches = append(*matches, pathMatch{path: joinPath(toks), tokens: toks, value: v, rule: *r})
+			return
+		}
+	}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		if len(t) == 0 {
+			break
+		}
+		for _, k := range sortedMapKeys(t) {
+			fk := k
+			if !exact {
+				fk = FoldName(k)
+			}
+			collectPathMatches(append(toks[:len(toks):len(toks)], k), append(folded[:len(folded):len(folded)], fk), t[k], rules, exact, matches, rest)
+		}
+		return
+	case []interface{}:
+		if len(t) == 0 {
+			break
+		}
+		for i, e := range t {
+			idx := "[" + strconv.Itoa(i) + "]"
+			collectPathMatches(append(toks[:len(toks):len(toks)], idx), append(folded[:len(folded):len(folded)], idx), e, rules, exact, matches, rest)
+		}
+		return
+	}
+	*rest = append(*rest, pathMatch{path: joinPath(toks), value: v}) //규칙과 일치하지 않은 leaf
+} //JSON 트리를 순회하며 규칙과 일치한 노드와 나머지 leaf 수집
+
+func extractPaths(am *abstraction.AbstractMessage, candidates []string, value func(string) interface{}, c coercer, opts ParseOptions) {
+	if len(candidates) == 0 {
+		return
+	}
+	rules := opts.synonyms().index().paths
+	var nested []string
+	trees := map[string]interface{}{}
+	for _, top := range candidates {
+		if !rules.reachable(top, opts.ExactSynonyms) { //규칙이 닿지 않는 key는 값을 읽지 않음
+			continue
+		}
+		switch v := value(top); v.(type) {
+		case map[string]interface{}, []interface{}: //중첩 값만 대상
+			nested = append(nested, top)
+			trees[top] = v
+		}
+	}
+	if len(nested) == 0 {
+		return
+	}
+	taken := make(map[string]bool, len(am.OriginalFieldNames)) //최상위 key로 채워진 표준 필드
+	for field := range am.OriginalFieldNames {
+		taken[field] = true
+	}
+	sort.Strings(nested)
+	for _, top := range nested {
+		var matches, rest []pathMatch
+		ftop := top
+		if !opts.ExactSynonyms {
+			ftop = FoldName(top)
+		}
+		collectPathMatches([]string{top}, []string{ftop}, trees[top], rules, opts.ExactSynonyms, &matches, &rest)
+		if len(matches) == 0 { //일치하는 규칙이 없을 시 통째로 Extras에 유지
+			continue
+		}
+		delete(am.Extras, top)
+		if am.OriginalPaths == nil {
+			am.OriginalPaths = map[string]string{}
+		}
+		for _, pm := range matches {
+			field := pm.rule.field
+			switch {
+			case taken[field]: //최상위 key나 앞선 경로로 이미 채워진 필드는 경로 그대로 Extras에 보존
+				rest = append(rest, pm)
+			case field == "CommitSeals": //여러 경로의 값을 모아 리스트로
+				am.CommitSeals = append(am.CommitSeals, c.hashes(pm.value)...)
+				am.OriginalPaths[field] = "" //한 위치로 되돌릴 수 없음
+			case field == "ViewChanges":
+				if obj, ok := pm.value.(map[string]interface{}); ok { //원소 객체 하나
+					pm.value = []interface{}{obj}
+				}
+				prev := am.ViewChanges
+				setField(am, field, pm.value, c)
+				am.ViewChanges = append(prev, am.ViewChanges...)
+				am.OriginalPaths[field] = ""
+			default: //단일 값 필드는 처음 일치한 경로만 채택
+				setField(am, field, pm.value, c)
+				taken[field] = true
+				path := ""
+				if plainPath(pm.tokens) { //joinPath 결과를 다시 나눠도 같은 위치일 때만 기록
+					path = pm.path
+				}
+				am.OriginalPaths[field] = path
+			}
+		}
+		for _, pm := range rest { //규칙과 일치하지 않은 나머지는 경로를 key로 Extras에 보존
+			am.Extras[pm.path] = extraValue(pm.value, opts.Format)
+		}
+	}
+} //최상위에서 매핑되지 않은 중첩 객체/배열에 경로 규칙을 적용해 표준 필드 추출
@@ -0,0 +1,78 @@
+package codec
+
+import (
+	"reflect"
+	"testing"
+
+	"codec/abstraction"
+)
+
+func TestExtractPaths(t *testing.T) {
+	custom := DefaultSynonyms()
+	custom.SetPath("meta.*.proposer", "Proposer") //임의 key
+	custom.SetPath("list[1].v", "Validator")      //특정 배열 원소
+	cases := []struct {
+		name  string
+		in    string
+		opts  ParseOptions
+		want  string            //같은 결과를 최상위 key로 적은 JSON(경로 key는 Extras)
+		paths map[string]string //OriginalPaths
+	}{
+		{"nested", `{"type":"Prevote","vote":{"height":5,"round":1,"block_id":{"hash":"0xab"}}}`, ParseOptions{},
+			`{"type":"Prevote","height":5,"round":1,"block_hash":"0xab"}`,
+			map[string]string{"Height": "vote.height", "Round": "vote.round", "BlockHash": "vote.block_id.hash"}},
+		{"folded keys", `{"type":"Prevote","Vote":{"Height":5,"blockId":{"HASH":"0xab"}}}`, ParseOptions{},
+			`{"type":"Prevote","height":5,"block_hash":"0xab"}`,
+			map[string]string{"Height": "Vote.Height", "BlockHash": "Vote.blockId.HASH"}},
+		{"exact keys", `{"type":"Prevote","Vote":{"height":5}}`, ParseOptions{ExactSynonyms: true},
+			`{"type":"Prevote","Vote":{"height":5}}`, nil},
+		{"array wildcard", `{"type":"Commit","signatures":[{"sig":"0x01"},{"signature":"0x02"},{"other":1}]}`, ParseOptions{},
+			`{"type":"Commit","commit_seals":["0x01","0x02"],"signatures[2].other":1}`,
+			map[string]string{"CommitSeals": ""}},
+		{"array of values", `{"type":"Commit","justify":{"signatures":["0x01","0x02"],"view":3}}`, ParseOptions{},
+			`{"type":"Commit","commit_seals":["0x01","0x02"],"justify.view":3}`,
+			map[string]string{"CommitSeals": ""}},
+		{"key wildcard", `{"type":"Prepare","meta":{"x":{"proposer":"n1"},"y":[{"proposer":"n2"}]}}`, ParseOptions{Synonyms: custom},
+			`{"type":"Prepare","proposer":"n1","meta.y[0].proposer":"n2"}`,
+			map[string]string{"Proposer": "meta.x.proposer"}},
+		{"index rule", `{"type":"Prepare","list":[{"v":"n1"},{"v":"n2"}]}`, ParseOptions{Synonyms: custom},
+			`{"type":"Prepare","validator":"n2","list[0].v":"n1"}`,
+			map[string]string{"Validator": "list[1].v"}},
+		{"missing leaf", `{"type":"Prevote","vote":{"round":1}}`, ParseOptions{},
+			`{"type":"Prevote","round":1}`, map[string]string{"Round": "vote.round"}},
+		{"scalar where an object is expected", `{"type":"Prevote","vote":{"round":1,"block_id":"0xab"}}`, ParseOptions{},
+			`{"type":"Prevote","round":1,"vote.block_id":"0xab"}`, map[string]string{"Round": "vote.round"}},
+		{"empty intermediate object", `{"type":"Prevote","vote":{"round":1,"block_id":{}}}`, ParseOptions{},
+			`{"type":"Prevote","round":1,"vote.block_id":{}}`, map[string]string{"Round": "vote.round"}},
+		{"null intermediate", `{"type":"Prevote","vote":null}`, ParseOptions{},
+			`{"type":"Prevote","vote":null}`, nil},
+		{"array where an object is expected", `{"type":"Prevote","header":[{"height":5}]}`, ParseOptions{},
+			`{"type":"Prevote","header":[{"height":5}]}`, nil}, //일치하는 규칙이 없으면 통째로 유지
+		{"top-level key wins", `{"type":"Prevote","height":7,"vote":{"height":5,"round":1}}`, ParseOptions{},
+			`{"type":"Prevote","height":7,"round":1,"vote.height":5}`, map[string]string{"Round": "vote.round"}},
+		{"first path wins", `{"type":"Prevote","vote":{"height":6},"header":{"height":5}}`, ParseOptions{},
+			`{"type":"Prevote","height":5,"vote.height":6}`, map[string]string{"Height": "header.height"}}, //최상위 key 순서
+		{"key containing a dot", `{"type":"Prevote","vote":{"block_id.hash":"0xab"}}`, ParseOptions{},
+			`{"type":"Prevote","vote":{"block_id.hash":"0xab"}}`, nil}, //key 하나는 경로 token 하나
+		{"key containing a bracket", `{"type":"Prevote","vote":{"height":5},"signatures":[{"sig[0]":"0x01","sig":"0x02"}]}`, ParseOptions{},
+			`{"type":"Prevote","height":5,"commit_seals":["0x02"],"signatures[0].sig[0]":"0x01"}`,
+			map[string]string{"Height": "vote.height", "CommitSeals": ""}},
+	}
+	for _, tc := range cases {
+		tc.opts.Format = FormatJSON
+		got, err := Parse([]byte(tc.in), tc.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		want, err := Parse([]byte(tc.want), ParseOptions{Format: FormatJSON, ExactSynonyms: true})
+		if err != nil {
+			t.Fatalf("%s: bad want: %v", tc.name, err)
+		}
+		if diff := want.Diff(got, abstraction.EqualOptions{IgnoreRawPayload: true}); len(diff) > 0 {
+			t.Errorf("%s: %s parsed with differences:\n%s", tc.name, tc.in, diff)
+		}
+		if !reflect.DeepEqual(got.OriginalPaths, tc.paths) {
+			t.Errorf("%s: OriginalPaths = %v, want %v", tc.name, got.OriginalPaths, tc.paths)
+		}
+	}
+} //경로 규칙(중첩, 와일드카드, 중간 객체 없음, 최상위 key와 충돌)으로 표준 필드 추출, 나머지는 경로 key로 Extras
@@ -0,0 +1,315 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"codec/abstraction"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+type Limits struct {
+	MaxBytes     int //입력 바이트 수(압축 해제 전후 모두)
+	MaxDepth     int //중첩 깊이(메시지 자체가 1, 그 안의 배열/객체/리스트마다 +1)
+	MaxListLen   int //배열/객체/리스트 하나의 원소 수(CommitSeals, ViewChanges 포함)
+	MaxExtras    int //Extras 항목 수
+	MaxStringLen int //문자열/바이트열 값 하나의 길이
+} //신뢰할 수 없는 입력에 대한 parsing 상한(0 이하인 항목은 무제한)
+
+var DefaultLimits = Limits{
+	MaxBytes:     DefaultMaxMessageSize,
+	MaxDepth:     32,
+	MaxListLen:   4096,
+	MaxExtras:    256,
+	MaxStringLen: 1 << 20,
+} //peer에게 받은 메시지에 권장하는 상한
+
+type LimitError struct {
+	Limit string //초과한 Limits 항목명(MaxDepth 등)
+	Max   int    //설정된 상한
+	Got   int    //실제 값(깊이/원소 수는 상한을 넘은 시점의 값)
+	Path  string //초과한 위치(예: CommitSeals, Extras[key], 알 수 없을 시 빈 문자열)
+} //Limits 초과
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v: %s %d > %d", ErrLimitExceeded, e.Limit, e.Got, e.Max)
+} //"limit exceeded: MaxListLen 5000 > 4096" 형태(Path는 ParseError.Field로 표기)
+
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded || (e.Limit == "MaxBytes" && target == ErrMessageTooLarge)
+} //errors.Is(err, ErrLimitExceeded) 지원, MaxBytes 초과는 ErrMessageTooLarge로도 판별
+
+func (l Limits) check(name string, max, got int, path string) error {
+	if max > 0 && got > max {
+		return &LimitError{Limit: name, Max: max, Got: got, Path: path}
+	}
+	return nil
+} //상한 설정 시 초과 여부 확인
+
+func (l Limits) structural() bool {
+	return l.MaxDepth > 0 || l.MaxListLen > 0 || l.MaxStringLen > 0
+} //디코딩 전 구조 검사가 필요한지 확인
+
+func limitError(format Format, data []byte, offset int64, err error) *ParseError {
+	var le *LimitError
+	field := ""
+	if errors.As(err, &le) {
+		field = le.Path
+	}
+	return newParseError(format, data, offset, field, err)
+} //LimitError를 위치 정보와 함께 ParseError로 감쌈
+
+type scanFrame struct {
+	obj     bool //객체인지(아닐 시 배열/리스트)
+	n       int  //지금까지의 원소(member) 수
+	wantKey bool //객체에서 다음 token이 key인지
+} //구조 검사 중 열린 컨테이너
+
+func checkJSONLimits(data []byte, l Limits) error {
+	if !l.structural() {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var stack []scanFrame
+	for {
+		off := dec.InputOffset()
+		tok, err := dec.Token()
+		if err != nil {
+			return nil //문법 에러는 디코더가 보고
+		}
+		if len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+				if stack = stack[:len(stack)-1]; len(stack) == 0 {
+					return nil
+				}
+				continue
+			}
+			if top.obj && top.wantKey { //객체 key
+				top.n++
+				top.wantKey = false
+				if err := l.check("MaxListLen", l.MaxListLen, top.n, ""); err != nil {
+					return limitError(FormatJSON, data, off, err)
+				}
+			} else if top.obj {
+				top.wantKey = true
+			} else {
+				top.n++
+				if err := l.check("MaxListLen", l.MaxListLen, top.n, ""); err != nil {
+					return limitError(FormatJSON, data, off, err)
+				}
+			}
+		}
+		switch t := tok.(type) {
+		case json.Delim:
+			if err := l.check("MaxDepth", l.MaxDepth, len(stack)+1, ""); err != nil {
+				return limitError(FormatJSON, data, off, err)
+			}
+			stack = append(stack, scanFrame{obj: t == '{', wantKey: t == '{'})
+		case string:
+			if err := l.check("MaxStringLen", l.MaxStringLen, len(t), ""); err != nil {
+				return limitError(FormatJSON, data, off, err)
+			}
+		}
+	}
+} //JSON token을 훑어 깊이/원소 수/문자열 길이 확인(트리를 만들기 전)
+
+func checkMsgPackLimits(data []byte, l Limits) error {
+	var stack []uint64 //열린 컨테이너마다 남은 자식 수
+	pos := 0
+	for {
+		if pos >= len(data) {
+			return nil //잘린 입력은 디코더가 보고
+		}
+		off := pos
+		lenBytes, extra, children, n, err := msgpackHeader(data[pos])
+		if err != nil {
+			return nil
+		}
+		pos++
+		if pos+lenBytes > len(data) {
+			return nil
+		}
+		for _, x := range data[pos : pos+lenBytes] {
+			n = n<<8 | uint64(x)
+		}
+		pos += lenBytes
+		if len(stack) > 0 {
+			stack[len(stack)-1]--
+		}
+		switch children {
+		case 0: //n: str/bin/ext 길이(그 외 0)
+			if err := l.check("MaxStringLen", l.MaxStringLen, clampInt(n), ""); err != nil {
+				return limitError(FormatMsgPack, data, int64(off), err)
+			}
+			if n+uint64(extra) > uint64(len(data)-pos) {
+				return newParseError(FormatMsgPack, data, int64(len(data)), "", truncated(fmt.Errorf("value of %d bytes at offset %d", n, off)))
+			}
+			pos += int(n) + extra
+		default:
+			if err := l.check("MaxDepth", l.MaxDepth, len(stack)+1, ""); err != nil {
+				return limitError(FormatMsgPack, data, int64(off), err)
+			}
+			if err := l.check("MaxListLen", l.MaxListLen, clampInt(n), ""); err != nil {
+				return limitError(FormatMsgPack, data, int64(off), err)
+			}
+			if n*uint64(children) > uint64(len(data)-pos) { //원소마다 최소 1바이트
+				return newParseError(FormatMsgPack, data, int64(len(data)), "", truncated(fmt.Errorf("%d elements at offset %d", n, off)))
+			}
+			stack = append(stack, n*uint64(children))
+		}
+		for len(stack) > 0 && stack[len(stack)-1] == 0 {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			return nil
+		}
+	}
+} //MessagePack header를 훑어 깊이/원소 수/길이 확인(선언된 길이로 할당하기 전, Limits가 없어도 입력보다 긴 선언은 truncated)
+
+func checkBCSLimits(data []byte) error {
+	n, k := binary.Uvarint(data)
+	if k <= 0 {
+		return nil //잘못된 길이는 디코더가 보고
+	}
+	if n > uint64(len(data)-k) {
+		return newParseError(FormatBCS, data, int64(len(data)), "", truncated(fmt.Errorf("payload of %d bytes at offset %d", n, k)))
+	}
+	return nil
+} //BCS 길이 prefix가 입력 안에 있는지 확인(선언된 길이로 할당하기 전, 깊이/원소 수 등은 안쪽 JSON 검사에서)
+
+func checkRLPLimits(data []byte, l Limits) error {
+	if !l.structural() {
+		return nil
+	}
+	kind, content, _, err := rlp.Split(data)
+	if err != nil || kind != rlp.List {
+		return nil //문자열 하나(JSON payload)는 JSON 검사에서 확인
+	}
+	return checkRLPList(data, content, 1, l)
+} //RLP 리스트 구조의 깊이/원소 수/문자열 길이 확인(interface{} 트리를 만들기 전)
+
+func checkRLPList(data, content []byte, depth int, l Limits) error {
+	offset := func(b []byte) int64 {
+		return int64(cap(data) - cap(b)) //같은 배열의 부분 slice이므로 cap 차이가 위치
+	}
+	if err := l.check("MaxDepth", l.MaxDepth, depth, ""); err != nil {
+		return limitError(FormatRLP, data, offset(content), err)
+	}
+	for n := 1; len(content) > 0; n++ {
+		if err := l.check("MaxListLen", l.MaxListLen, n, ""); err != nil {
+			return limitError(FormatRLP, data, offset(content), err)
+		}
+		kind, item, rest, err := rlp.Split(content)
+		if err != nil {
+			return nil //디코더가 보고
+		}
+		if kind == rlp.List {
+			if err := checkRLPList(data, item, depth+1, l); err != nil {
+				return err
+			}
+		} else if err := l.check("MaxStringLen", l.MaxStringLen, len(item), ""); err != nil {
+			return limitError(FormatRLP, data, offset(item), err)
+		}
+		content = rest
+	}
+	return nil
+} //RLP 리스트 하나와 그 하위 확인
+
+func clampInt(n uint64) int {
+	if n > math.MaxInt {
+		return math.MaxInt
+	}
+	return int(n)
+} //비교용으로 int 범위에 맞춤
+
+func checkMessageLimits(am *abstraction.AbstractMessage, l Limits) error {
+	if err := l.check("MaxListLen", l.MaxListLen, len(am.CommitSeals), "CommitSeals"); err != nil {
+		return err
+	}
+	if err := l.check("MaxListLen", l.MaxListLen, len(am.ViewChanges), "ViewChanges"); err != nil {
+		return err
+	}
+	if err := l.check("MaxExtras", l.MaxExtras, len(am.Extras), "Extras"); err != nil {
+		return err
+	}
+	if l.MaxStringLen > 0 {
+		for _, f := range []struct {
+			path, s string
+		}{
+			{"BlockHash", am.BlockHash}, {"PrevHash", am.PrevHash}, {"Proposer", am.Proposer},
+			{"Validator", am.Validator}, {"Signature", am.Signature},
+		} {
+			if err := l.check("MaxStringLen", l.MaxStringLen, len(f.s), f.path); err != nil {
+				return err
+			}
+		}
+		for i, s := range am.CommitSeals {
+			if len(s) > l.MaxStringLen { //경로 문자열은 초과 시에만 생성
+				return l.check("MaxStringLen", l.MaxStringLen, len(s), "CommitSeals["+strconv.Itoa(i)+"]")
+			}
+		}
+		for i, e := range am.ViewChanges {
+			if len(e.Validator) > l.MaxStringLen {
+				return l.check("MaxStringLen", l.MaxStringLen, len(e.Validator), "ViewChanges["+strconv.Itoa(i)+"].Validator")
+			}
+			if len(e.Signature) > l.MaxStringLen {
+				return l.check("MaxStringLen", l.MaxStringLen, len(e.Signature), "ViewChanges["+strconv.Itoa(i)+"].Signature")
+			}
+		}
+	}
+	for k, v := range am.Extras {
+		if err := checkExtraLimits(v, 2, l); err != nil {
+			return prefixLimitPath(abstraction.ExtraField(k), err)
+		}
+	}
+	return nil
+} //정규화된 메시지의 목록/Extras/문자열 크기 확인(모든 codec 공통)
+
+func checkExtraLimits(v abstraction.ExtraValue, depth int, l Limits) error {
+	switch v.Kind {
+	case abstraction.ExtraString:
+		return l.check("MaxStringLen", l.MaxStringLen, len(v.Str), "")
+	case abstraction.ExtraBytes:
+		return l.check("MaxStringLen", l.MaxStringLen, len(v.Bytes), "")
+	case abstraction.ExtraList:
+		if err := l.check("MaxDepth", l.MaxDepth, depth, ""); err != nil {
+			return err
+		}
+		if err := l.check("MaxListLen", l.MaxListLen, len(v.List), ""); err != nil {
+			return err
+		}
+		for i, e := range v.List {
+			if err := checkExtraLimits(e, depth+1, l); err != nil {
+				return prefixLimitPath("["+strconv.Itoa(i)+"]", err)
+			}
+		}
+	case abstraction.ExtraMap:
+		if err := l.check("MaxDepth", l.MaxDepth, depth, ""); err != nil {
+			return err
+		}
+		if err := l.check("MaxListLen", l.MaxListLen, len(v.Map), ""); err != nil {
+			return err
+		}
+		for k, e := range v.Map {
+			if err := checkExtraLimits(e, depth+1, l); err != nil {
+				return prefixLimitPath("."+k, err)
+			}
+		}
+	}
+	return nil
+} //Extras 값의 깊이/원소 수/길이 확인(에러의 Path는 v 기준 상대 경로)
+
+func prefixLimitPath(prefix string, err error) error {
+	if le, ok := err.(*LimitError); ok {
+		le.Path = prefix + le.Path
+	}
+	return err
+} //하위 값의 LimitError 경로 앞에 prefix를 붙임(경로는 초과 시에만 생성)
@@ -0,0 +1,104 @@
+package codec
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+type nodeProvider struct {
+	md protoreflect.MessageDescriptor
+} //재귀 메시지 limtest.Node만 제공
+
+func (p nodeProvider) FindMessageByName(protoreflect.FullName) (protoreflect.MessageDescriptor, error) {
+	return p.md, nil
+} //항상 Node
+
+func nodeDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("limtest/node.proto"),
+		Package: proto.String("limtest"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Node"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("child"), JsonName: proto.String("child"), Number: proto.Int32(1), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".limtest.Node")},
+				{Name: proto.String("type"), JsonName: proto.String("type"), Number: proto.Int32(2), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+			},
+		}},
+	}
+	fd, err := protodesc.NewFile(fdp, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd.Messages().ByName("Node")
+} //child로 자기 자신을 담는 메시지
+
+func nestedNode(depth int) []byte {
+	var b []byte
+	for range depth - 1 {
+		b = protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), b)
+	}
+	return protowire.AppendBytes(protowire.AppendTag(b, 2, protowire.BytesType), []byte("Commit"))
+} //최상위 포함 depth 단계로 중첩된 Node
+
+func TestProtoMaxDepth(t *testing.T) {
+	md := nodeDescriptor(t)
+	opts := ParseOptions{Format: FormatProtobuf, ProtoMessageFullName: "limtest.Node", DescriptorProvider: nodeProvider{md}, Limits: Limits{MaxDepth: 4}}
+	dup := protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), nil) //같은 메시지 필드 반복: 직접 decoding 대신 dynamicpb 경로
+	for _, path := range []struct {
+		name   string
+		prefix []byte
+	}{{"direct", nil}, {"dynamicpb", dup}} {
+		if _, err := Parse(append(path.prefix, nestedNode(4)...), opts); err != nil {
+			t.Fatalf("%s: depth 4: %v", path.name, err)
+		}
+		_, err := Parse(append(path.prefix, nestedNode(5)...), opts)
+		var le *LimitError
+		var pe *ParseError
+		if !errors.Is(err, ErrLimitExceeded) || !errors.As(err, &le) || !errors.As(err, &pe) || le.Limit != "MaxDepth" || le.Got != 5 {
+			t.Fatalf("%s: depth 5: err = %v, want MaxDepth LimitError", path.name, err)
+		}
+	}
+	opts.Limits = Limits{}
+	if _, err := Parse(nestedNode(protoDirectDepth+10), opts); err != nil { //상한 없음
+		t.Fatalf("unlimited depth: %v", err)
+	}
+} //protobuf 중첩 깊이는 문자열 비교 없이 LimitError로(직접 decoding과 dynamicpb 경로 모두)
+
+func TestBCSDeclaredLength(t *testing.T) {
+	data := append(protowire.AppendVarint(nil, 1<<30), `{"type":"Commit"}`...) //uleb128 길이가 입력보다 김
+	_, err := Parse(data, ParseOptions{Format: FormatBCS})
+	var pe *ParseError
+	if !errors.Is(err, ErrTruncatedInput) || !errors.As(err, &pe) || pe.Format != FormatBCS {
+		t.Fatalf("err = %v, want BCS ParseError wrapping ErrTruncatedInput", err)
+	}
+} //선언된 길이가 입력을 넘는 BCS payload는 할당 전에 거부
+
+func TestProtoMapEntryKeyWireTypeMismatch(t *testing.T) {
+	fuzzSetup(t)
+	for _, data := range [][]byte{
+		[]byte("2\x060000002\x06000000j\n\n\x0500000\b\xe80"), //testdata/fuzz/FuzzParseProtobuf/387b5ba14397fe6b
+		[]byte("j\x05\n\x01a\x08\x01"),                        //extras entry: key "a" 뒤 같은 번호를 varint로 반복
+	} {
+		_, err := Parse(data, fuzzParseOptions(FormatProtobuf))
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Format != FormatProtobuf {
+			t.Fatalf("Parse(%q) err = %v, want protobuf ParseError", data, err)
+		}
+	}
+	for _, data := range [][]byte{
+		[]byte("j\x05\x08\x01\n\x01a"), //key가 먼저 다른 wire type으로 온 경우는 unknown 필드
+		[]byte("j\x03\n\x01a"),
+	} {
+		if _, err := Parse(data, fuzzParseOptions(FormatProtobuf)); err != nil {
+			t.Fatalf("Parse(%q): %v", data, err)
+		}
+	}
+} //map entry key가 다른 wire type으로 반복되면 dynamicpb panic 대신 ParseError
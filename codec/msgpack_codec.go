@@ -1,35 +1,103 @@
-package codec
-
-import (
-	"fmt"
-
-	"codec/abstraction"
-
-	"github.com/vmihailenco/msgpack/v5"
-)
-
-type msgpackCodec struct{} //MessagePack 포맷 parsing/serializing
-
-func (msgpackCodec) Parse(data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error) {
-	var decoded map[string]interface{}
-	if err := msgpack.Unmarshal(data, &decoded); err != nil {
-		return nil, fmt.Errorf("msgpack decode: %w", err)
-	}
-	js, err := jsonFromInterface(decoded)
-	if err != nil {
-		return nil, err
-	}
-	return (jsonCodec{}).Parse(js, ParseOptions{Format: FormatJSON, OverrideMsgType: opts.OverrideMsgType})
-} //MessagePack 바이트를 AbstractMessage로 변환
-
-func (msgpackCodec) Serialize(am *abstraction.AbstractMessage, _ SerializeOptions) ([]byte, error) {
-	js, err := (jsonCodec{}).Serialize(am, SerializeOptions{Format: FormatJSON}) //JSON 바이트로 변환
-	if err != nil {
-		return nil, err
-	}
-	var obj map[string]interface{}
-	if err := unmarshalJSON(js, &obj); err != nil {
-		return nil, err
-	}
-	return msgpack.Marshal(obj)
-} //AbstractMessage를 MessagePack 바이트로 변환
+package codec
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"codec/abstraction"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type msgpackCodec struct{} //MessagePack 포맷 parsing/serializing
+
+func (msgpackCodec) Parse(data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error) {
+	opts.Format = FormatMsgPack
+	if src, ok := newMsgPackSource(data, opts); ok { //interface{} 트리 없이 입력에서 바로 정규화(Limits 안의 구조만)
+		am, err := messageFromSource(src, opts)
+		src.release()
+		if err != nil {
+			return nil, decodeError(FormatMsgPack, data, err)
+		}
+		return am, nil
+	}
+	if err := checkMsgPackLimits(data, opts.Limits); err != nil { //선언된 길이로 할당하기 전 구조 확인
+		return nil, err
+	}
+	var decoded map[string]interface{}
+	if err := msgpack.Unmarshal(data, &decoded); err != nil { //직접 읽을 수 없는 입력과 에러 보고
+		return nil, decodeError(FormatMsgPack, data, err)
+	}
+	am, err := messageFromMap(decoded, opts) //bin/정수 타입을 유지한 채 정규화
+	if err != nil {
+		return nil, decodeError(FormatMsgPack, data, err)
+	}
+	return am, nil
+} //MessagePack 바이트를 AbstractMessage로 변환
+
+func (msgpackCodec) Serialize(am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error) {
+	obj, err := messageToMap(am, opts, msgpackExtra) //Extras의 bytes는 bin, 정수는 int로
+	if err != nil {
+		return nil, err
+	}
+	obj = msgpackValue(obj).(map[string]interface{})
+	if opts.TimestampFormat == TimestampNative && !am.Timestamp.IsZero() { //msgpack timestamp extension(-1)
+		n, err := newNamer(am, opts)
+		if err != nil {
+			return nil, err
+		}
+		n.put(obj, "Timestamp", am.Timestamp.UTC())
+	}
+	return msgpack.Marshal(obj)
+} //AbstractMessage를 MessagePack 바이트로 변환
+
+func msgpackValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = msgpackValue(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = msgpackValue(e)
+		}
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(t.String(), 10, 64); err == nil {
+			return u
+		}
+		if !strings.ContainsAny(t.String(), ".eE") { //64비트를 넘는 정수는 정밀도 유지를 위해 문자열로
+			return t.String()
+		}
+		if f, err := t.Float64(); err == nil {
+			return f
+		}
+		return t.String()
+	}
+	return v
+} //JSON 수(json.Number)를 msgpack 정수/실수 타입으로 변환
+
+func msgpackExtra(e abstraction.ExtraValue) interface{} {
+	switch e.Kind {
+	case abstraction.ExtraBytes:
+		return e.Bytes
+	case abstraction.ExtraFloat:
+		return e.Float
+	case abstraction.ExtraList:
+		out := make([]interface{}, 0, len(e.List))
+		for _, item := range e.List {
+			out = append(out, msgpackExtra(item))
+		}
+		return out
+	case abstraction.ExtraMap:
+		out := make(map[string]interface{}, len(e.Map))
+		for k, item := range e.Map {
+			out[k] = msgpackExtra(item)
+		}
+		return out
+	}
+	return extraInterface(e) //정수(json.Number)는 msgpackValue에서 변환
+} //ExtraValue를 msgpack 고유 타입 값으로 변환
@@ -0,0 +1,585 @@
+package codec
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"strconv"
+	"sync"
+
+	"codec/abstraction"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const msgpackDirectDepth = 64 //직접 읽는 최대 중첩 깊이(더 깊으면 msgpack.Unmarshal 경로)
+
+type msgpackItem struct {
+	code byte
+	body []byte //str/bin/ext 데이터(ext는 type 바이트 제외) 또는 고정 크기 수의 big-endian 바이트
+	ext  int8   //ext type
+	n    int    //배열 원소 수 또는 map 항목 수
+	end  int    //scalar는 값 다음 위치, 배열/map은 첫 원소 위치
+} //MessagePack 값 하나의 header
+
+type msgpackEntry struct {
+	it         msgpackItem
+	start, end int //입력 내 범위
+} //최상위 map의 값 하나
+
+type msgpackSource struct {
+	data []byte
+	keys []string
+	vals []msgpackEntry
+	dict map[string]string //사전에 있는 이름은 새로 할당하지 않음
+	seen map[string]int    //항목이 많은 map의 중복 key 확인
+	ints *intBlock         //parsing 중인 메시지의 최상위 정수 필드
+} //디코딩하지 않은 최상위 map(값은 필요할 때 입력에서 바로 읽음)
+
+var msgpackSources = sync.Pool{New: func() interface{} { return new(msgpackSource) }} //parsing마다 key/범위 slice를 새로 할당하지 않도록
+
+type msgpackShape struct {
+	lenBytes, extra, children, count uint8
+	valid                            bool
+} //msgpackHeader 결과(code별 표)
+
+var msgpackShapes = func() (t [256]msgpackShape) {
+	for b := range t {
+		lenBytes, extra, children, n, err := msgpackHeader(byte(b))
+		t[b] = msgpackShape{uint8(lenBytes), uint8(extra), uint8(children), uint8(n), err == nil}
+	}
+	return t
+}() //값마다 msgpackHeader의 분기를 거치지 않도록
+
+func readMsgPack(data []byte, pos int) (msgpackItem, bool) {
+	if pos >= len(data) {
+		return msgpackItem{}, false
+	}
+	code := data[pos]
+	sh := msgpackShapes[code]
+	if !sh.valid {
+		return msgpackItem{}, false
+	}
+	pos++
+	lenBytes, extra, n := int(sh.lenBytes), int(sh.extra), uint64(sh.count)
+	if lenBytes > len(data)-pos {
+		return msgpackItem{}, false
+	}
+	for _, x := range data[pos : pos+lenBytes] {
+		n = n<<8 | uint64(x)
+	}
+	pos += lenBytes
+	it := msgpackItem{code: code}
+	if sh.children > 0 {
+		if n > uint64(len(data)-pos) { //원소마다 최소 1바이트
+			return msgpackItem{}, false
+		}
+		it.n, it.end = int(n), pos
+		return it, true
+	}
+	if n+uint64(extra) > uint64(len(data)-pos) {
+		return msgpackItem{}, false
+	}
+	it.end = pos + int(n) + extra
+	it.body = data[pos:it.end]
+	if isMsgPackExt(code) {
+		it.ext, it.body = int8(it.body[0]), it.body[1:]
+	}
+	return it, true
+} //pos의 값 header를 읽고 범위 확인
+
+func isMsgPackExt(code byte) bool {
+	return code >= 0xc7 && code <= 0xc9 || code >= 0xd4 && code <= 0xd8
+} //ext 8/16/32, fixext
+
+func isMsgPackStr(code byte) bool {
+	return code >= 0xa0 && code <= 0xbf || code >= 0xd9 && code <= 0xdb
+} //fixstr, str 8/16/32
+
+func isMsgPackBin(code byte) bool {
+	return code >= 0xc4 && code <= 0xc6
+} //bin 8/16/32
+
+func isMsgPackMap(code byte) bool {
+	return code >= 0x80 && code <= 0x8f || code == 0xde || code == 0xdf
+} //fixmap, map 16/32
+
+func isMsgPackArray(code byte) bool {
+	return code >= 0x90 && code <= 0x9f || code == 0xdc || code == 0xdd
+} //fixarray, array 16/32
+
+func skipMsgPack(data []byte, pos, depth int, l Limits) (int, bool) {
+	it, ok := readMsgPack(data, pos)
+	if !ok {
+		return 0, false
+	}
+	return skipMsgPackItem(data, it, depth, l)
+} //pos의 값을 건너뛴 위치(depth는 값이 배열/map일 때의 깊이, msgpack.Unmarshal이 같은 값으로 읽는다고 확인할 수 없거나 Limits를 넘을 시 false)
+
+func skipMsgPackItem(data []byte, it msgpackItem, depth int, l Limits) (int, bool) {
+	if !msgpackWithin(it, depth, l) {
+		return 0, false
+	}
+	var ok bool
+	pos := it.end
+	switch {
+	case isMsgPackExt(it.code): //msgpack.Unmarshal이 아는 ext는 timestamp(-1)뿐
+		return it.end, it.ext == -1 && (len(it.body) == 4 || len(it.body) == 8 || len(it.body) == 12)
+	case isMsgPackArray(it.code):
+		for range it.n {
+			if pos, ok = skipMsgPack(data, pos, depth+1, l); !ok {
+				return 0, false
+			}
+		}
+		return pos, true
+	case isMsgPackMap(it.code):
+		for range it.n {
+			k, ok := readMsgPack(data, pos)
+			if !ok || !isMsgPackStr(k.code) && !isMsgPackBin(k.code) && k.code != 0xc0 || !msgpackWithin(k, depth+1, l) { //key는 문자열로 디코딩
+				return 0, false
+			}
+			if pos, ok = skipMsgPack(data, k.end, depth+1, l); !ok {
+				return 0, false
+			}
+		}
+		return pos, true
+	}
+	return it.end, true
+} //이미 읽은 header의 값을 건너뛴 위치
+
+func msgpackWithin(it msgpackItem, depth int, l Limits) bool {
+	if isMsgPackArray(it.code) || isMsgPackMap(it.code) {
+		return depth <= msgpackDirectDepth && l.check("MaxDepth", l.MaxDepth, depth, "") == nil && l.check("MaxListLen", l.MaxListLen, it.n, "") == nil
+	}
+	return l.check("MaxStringLen", l.MaxStringLen, len(it.body), "") == nil || !isMsgPackStr(it.code) && !isMsgPackBin(it.code) && !isMsgPackExt(it.code)
+} //checkMsgPackLimits가 통과시키는 값인지 확인(넘을 시 그쪽에서 에러 보고)
+
+func newMsgPackSource(data []byte, opts ParseOptions) (*msgpackSource, bool) {
+	top, ok := readMsgPack(data, 0)
+	if !ok || !isMsgPackMap(top.code) || !msgpackWithin(top, 1, opts.Limits) {
+		return nil, false
+	}
+	s := msgpackSources.Get().(*msgpackSource)
+	s.data, s.keys, s.vals = data, s.keys[:0], s.vals[:0]
+	s.dict, s.ints = opts.synonyms().index().names, nil
+	if top.n > 16 {
+		if s.seen == nil {
+			s.seen = make(map[string]int, top.n)
+		}
+		clear(s.seen)
+	}
+	pos := top.end
+	for range top.n {
+		k, ok := readMsgPack(data, pos)
+		if !ok || !isMsgPackStr(k.code) || !msgpackWithin(k, 2, opts.Limits) { //bin/nil key는 msgpack.Unmarshal 경로
+			s.release()
+			return nil, false
+		}
+		it, ok := readMsgPack(data, k.end)
+		end := 0
+		if ok {
+			end, ok = skipMsgPackItem(data, it, 2, opts.Limits)
+		}
+		if !ok {
+			s.release()
+			return nil, false
+		}
+		s.put(s.key(k.body), msgpackEntry{it, k.end, end}, top.n > 16)
+		pos = end
+	}
+	return s, true
+} //최상위 map의 key와 값 범위(중복 key는 마지막 값, map이 아니거나 직접 읽을 수 없는 값, Limits를 넘는 값이 있을 시 false)
+
+func (s *msgpackSource) put(key string, v msgpackEntry, indexed bool) {
+	if indexed {
+		if i, dup := s.seen[key]; dup {
+			s.vals[i] = v
+			return
+		}
+		s.seen[key] = len(s.keys)
+	} else {
+		for i, k := range s.keys {
+			if k == key {
+				s.vals[i] = v
+				return
+			}
+		}
+	}
+	s.keys = append(s.keys, key)
+	s.vals = append(s.vals, v)
+} //key 추가(이미 있을 시 값 범위만 교체)
+
+func (s *msgpackSource) key(b []byte) string {
+	if k, ok := s.dict[string(b)]; ok {
+		return k
+	}
+	return string(b)
+} //바이트를 문자열로(사전에 있는 이름은 할당 없이)
+
+func (s *msgpackSource) release() {
+	clear(s.keys)
+	clear(s.seen)
+	s.data, s.dict, s.ints = nil, nil, nil
+	msgpackSources.Put(s)
+} //pool에 반환(입력/key 참조 해제)
+
+func (s *msgpackSource) names() []string { return s.keys }
+
+func (s *msgpackSource) item(i int) *msgpackItem {
+	return &s.vals[i].it
+} //i번째 값의 header(복사 없이)
+
+func (s *msgpackSource) value(i int) interface{} {
+	var v interface{}
+	_ = msgpack.Unmarshal(s.data[s.vals[i].start:s.vals[i].end], &v) //범위는 skipMsgPack에서 확인됨
+	return v
+} //i번째 값을 msgpack.Unmarshal과 같은 트리로
+
+func (s *msgpackSource) text(i int) (string, bool) {
+	it := s.item(i)
+	if !isMsgPackStr(it.code) {
+		return "", false
+	}
+	return s.key(it.body), true
+} //문자열 값(메시지명 등 사전에 있는 값은 할당 없이)
+
+func (s *msgpackSource) empty(i int) bool {
+	it := s.item(i)
+	return it.code == 0xc0 || isMsgPackStr(it.code) && len(it.body) == 0
+} //nil 또는 빈 문자열
+
+func (s *msgpackSource) object(i int) bool {
+	return isMsgPackMap(s.vals[i].it.code)
+} //map 값 여부
+
+func (s *msgpackSource) setField(am *abstraction.AbstractMessage, field string, i int, c coercer) {
+	if !s.setDirect(am, field, s.item(i), c) {
+		setField(am, field, s.value(i), c) //디코딩한 트리로
+	}
+} //i번째 값으로 표준 필드 설정
+
+func (s *msgpackSource) setDirect(am *abstraction.AbstractMessage, field string, it *msgpackItem, c coercer) bool {
+	if isMsgPackStr(it.code) && field != "Height" && field != "Round" && field != "View" {
+		setFieldText(am, field, string(it.body), c)
+		return true
+	}
+	var ok bool
+	switch field {
+	case "Height":
+		am.Height, ok = s.topInts().bigInt(*it, c)
+	case "Round":
+		am.Round, ok = s.topInts().bigInt(*it, c)
+	case "View":
+		am.View, ok = s.topInts().bigInt(*it, c)
+	case "BlockHash":
+		am.BlockHash, ok = msgpackHash(*it, c)
+	case "PrevHash":
+		am.PrevHash, ok = msgpackHash(*it, c)
+	case "Proposer":
+		am.Proposer, ok = msgpackText(*it, c)
+	case "Validator":
+		am.Validator, ok = msgpackText(*it, c)
+	case "Signature":
+		am.Signature, ok = msgpackHash(*it, c)
+	case "CommitSeals":
+		am.CommitSeals, ok = s.hashes(*it, c)
+	case "ViewChanges":
+		am.ViewChanges, ok = s.viewChanges(*it, c)
+	}
+	return ok
+} //값을 interface{} 트리 없이 설정(setField와 같은 결과가 확실하지 않을 시 false)
+
+func (s *msgpackSource) topInts() *intBlock {
+	if s.ints == nil {
+		s.ints = newTopInts()
+	}
+	return s.ints
+} //최상위 정수 필드용 블록(메시지마다 필요할 때 한 번 할당)
+
+type intBlock struct {
+	ints  []big.Int
+	words []big.Word
+} //여러 정수 값을 한 번에 할당(값마다 big.NewInt의 할당 두 번 대신)
+
+type topInts struct {
+	block intBlock
+	ints  [3]big.Int
+	words [3]big.Word
+} //한 메시지의 Height/Round/View(블록과 저장 공간을 함께 할당)
+
+func newTopInts() *intBlock {
+	t := new(topInts)
+	t.block = intBlock{ints: t.ints[:], words: t.words[:]}
+	return &t.block
+} //Height/Round/View 블록
+
+func newIntBlock(n int) *intBlock {
+	return &intBlock{ints: make([]big.Int, n), words: make([]big.Word, n)}
+} //정수 n개 분량의 블록
+
+func (b *intBlock) bigInt(it msgpackItem, c coercer) (*big.Int, bool) {
+	var x int64
+	ok := false
+	switch i, u, isInt := msgpackInt(it); {
+	case isInt && it.code >= 0xcc && it.code <= 0xcf:
+		x, ok = int64(u), u <= math.MaxInt64
+	case isInt:
+		x, ok = i, true
+	case isMsgPackStr(it.code):
+		x, ok = smallDecimal(string(it.body)) //임시 문자열 없이
+	}
+	if !ok {
+		return msgpackBigInt(it, c)
+	}
+	return b.new(x), true
+} //msgpackBigInt와 같은 값(int64 범위는 블록에서)
+
+func (b *intBlock) new(x int64) *big.Int {
+	u := uint64(x)
+	if x < 0 {
+		u = -u
+	}
+	if len(b.ints) == 0 || len(b.words) == 0 || uint64(big.Word(u)) != u { //블록을 다 썼거나 32비트 Word에 들어가지 않는 값
+		return big.NewInt(x)
+	}
+	z := &b.ints[0]
+	b.ints = b.ints[1:]
+	if u != 0 { //0은 big.NewInt(0)과 같이 abs가 nil
+		w := b.words[:1:1] //cap 1이므로 값이 커지면 새로 할당(이웃 값과 공유하지 않음)
+		b.words = b.words[1:]
+		w[0] = big.Word(u)
+		z.SetBits(w)
+		if x < 0 {
+			z.Neg(z)
+		}
+	}
+	return z
+} //big.NewInt(x)와 같은 값
+
+func (s *msgpackSource) hashes(it msgpackItem, c coercer) ([]string, bool) {
+	if it.code == 0xc0 {
+		return nil, true
+	}
+	if !isMsgPackArray(it.code) {
+		h, ok := msgpackHash(it, c)
+		return []string{h}, ok
+	}
+	out := make([]string, 0, it.n)
+	end, _ := skipMsgPackItem(s.data, it, 0, Limits{})
+	t := newTextBlock(2 * (end - it.end)) //hex로 쓴 bin 값도 담는 크기
+	pos := it.end
+	for range it.n {
+		e, _ := readMsgPack(s.data, pos)
+		h, ok := msgpackHashIn(t, e, c)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, h)
+		pos = e.end
+	}
+	return out, true
+} //해시 배열(commit seal 등)
+
+func (s *msgpackSource) viewChanges(it msgpackItem, c coercer) ([]abstraction.ViewChangeEntry, bool) {
+	if !isMsgPackArray(it.code) { //배열이 아닌 값은 무시(setField와 같음)
+		return nil, true
+	}
+	out := make([]abstraction.ViewChangeEntry, 0, it.n)
+	end, _ := skipMsgPackItem(s.data, it, 0, Limits{})
+	ints, t := newIntBlock(2*it.n), newTextBlock(2*(end-it.end))
+	pos := it.end
+	for range it.n {
+		e, _ := readMsgPack(s.data, pos)
+		if !isMsgPackMap(e.code) { //객체가 아닌 원소는 건너뜀
+			pos, _ = skipMsgPack(s.data, pos, 0, Limits{})
+			continue
+		}
+		var vc abstraction.ViewChangeEntry
+		pos = e.end
+		for range e.n {
+			k, _ := readMsgPack(s.data, pos)
+			if !isMsgPackStr(k.code) { //bin/nil key도 문자열로 디코딩되므로 트리 경로로
+				return nil, false
+			}
+			v, _ := readMsgPack(s.data, k.end)
+			ok := !isMsgPackArray(v.code) && !isMsgPackMap(v.code)
+			switch string(k.body) {
+			case "view":
+				vc.View, ok = ints.bigInt(v, c)
+			case "height":
+				vc.Height, ok = ints.bigInt(v, c)
+			case "validator":
+				vc.Validator, ok = msgpackTextIn(t, v, c)
+			case "signature":
+				vc.Signature, ok = msgpackHashIn(t, v, c)
+			default: //그 외 key는 값을 건너뜀
+				ok = true
+			}
+			if !ok {
+				return nil, false
+			}
+			pos, _ = skipMsgPack(s.data, k.end, 0, Limits{})
+		}
+		out = append(out, vc)
+	}
+	return out, true
+} //{view, height, validator, signature} 객체 배열
+
+func msgpackInt(it msgpackItem) (int64, uint64, bool) {
+	switch c := it.code; {
+	case c <= 0x7f || c >= 0xe0: //fixint
+		return int64(int8(c)), 0, true
+	case c == 0xd0:
+		return int64(int8(it.body[0])), 0, true
+	case c == 0xd1:
+		return int64(int16(binary.BigEndian.Uint16(it.body))), 0, true
+	case c == 0xd2:
+		return int64(int32(binary.BigEndian.Uint32(it.body))), 0, true
+	case c == 0xd3:
+		return int64(binary.BigEndian.Uint64(it.body)), 0, true
+	case c == 0xcc:
+		return 0, uint64(it.body[0]), true
+	case c == 0xcd:
+		return 0, uint64(binary.BigEndian.Uint16(it.body)), true
+	case c == 0xce:
+		return 0, uint64(binary.BigEndian.Uint32(it.body)), true
+	case c == 0xcf:
+		return 0, binary.BigEndian.Uint64(it.body), true
+	}
+	return 0, 0, false
+} //정수 값(int 계열은 첫 번째, uint 계열은 두 번째 값)
+
+func msgpackBigInt(it msgpackItem, c coercer) (*big.Int, bool) {
+	if i, u, ok := msgpackInt(it); ok {
+		if it.code >= 0xcc && it.code <= 0xcf {
+			return new(big.Int).SetUint64(u), true
+		}
+		return big.NewInt(i), true
+	}
+	switch {
+	case it.code == 0xc0:
+		return nil, true
+	case isMsgPackStr(it.code):
+		return c.parseInt(string(it.body)), true
+	case isMsgPackBin(it.code): //big-endian 부호 없는 정수
+		return new(big.Int).SetBytes(it.body), true
+	case it.code == 0xca:
+		return floatInt(float64(math.Float32frombits(binary.BigEndian.Uint32(it.body)))), true
+	case it.code == 0xcb:
+		return floatInt(math.Float64frombits(binary.BigEndian.Uint64(it.body))), true
+	}
+	return nil, false
+} //coercer.bigInt와 같은 변환
+
+func msgpackHash(it msgpackItem, c coercer) (string, bool) {
+	if isMsgPackStr(it.code) {
+		return c.hashText(string(it.body)), true
+	}
+	return msgpackText(it, c)
+} //coercer.hash와 같은 변환
+
+func msgpackHashIn(t *textBlock, it msgpackItem, c coercer) (string, bool) {
+	switch {
+	case isMsgPackStr(it.code):
+		return c.hashBytes(t, it.body), true
+	case isMsgPackBin(it.code):
+		return c.encodeIn(t, it.body), true
+	}
+	return msgpackText(it, c)
+} //msgpackHash와 같은 값(문자열은 블록에서)
+
+func msgpackTextIn(t *textBlock, it msgpackItem, c coercer) (string, bool) {
+	switch {
+	case isMsgPackStr(it.code):
+		return t.text(it.body), true
+	case isMsgPackBin(it.code):
+		return c.encodeIn(t, it.body), true
+	}
+	return msgpackText(it, c)
+} //msgpackText와 같은 값(문자열은 블록에서)
+
+func msgpackText(it msgpackItem, c coercer) (string, bool) {
+	switch {
+	case isMsgPackStr(it.code):
+		return string(it.body), true
+	case isMsgPackBin(it.code):
+		return c.encode(it.body), true
+	case it.code == 0xc0:
+		return "", true
+	case it.code == 0xc2 || it.code == 0xc3:
+		return strconv.FormatBool(it.code == 0xc3), true
+	case it.code == 0xcb:
+		return strconv.FormatFloat(math.Float64frombits(binary.BigEndian.Uint64(it.body)), 'f', -1, 64), true
+	}
+	if it.code == 0xca { //float32는 정수 부분만(coercer.text와 같음)
+		return "", false
+	}
+	if x, ok := msgpackBigInt(it, c); ok && x != nil {
+		return x.String(), true
+	}
+	return "", false
+} //coercer.text와 같은 변환
+
+func (s *msgpackSource) extra(i int, format Format) abstraction.ExtraValue {
+	if e, _, ok := s.extraAt(s.vals[i].start, format); ok {
+		return e
+	}
+	return extraValue(s.value(i), format)
+} //i번째 값을 ExtraValue로
+
+func (s *msgpackSource) extraAt(pos int, format Format) (abstraction.ExtraValue, int, bool) {
+	it, _ := readMsgPack(s.data, pos)
+	var e abstraction.ExtraValue
+	switch {
+	case it.code == 0xc0:
+		e = abstraction.NullExtra()
+	case it.code == 0xc2 || it.code == 0xc3:
+		e = abstraction.BoolExtra(it.code == 0xc3)
+	case isMsgPackStr(it.code):
+		e = abstraction.StringExtra(string(it.body))
+	case isMsgPackBin(it.code):
+		e = abstraction.BytesExtra(it.body)
+	case it.code == 0xca:
+		e = abstraction.ExtraFromJSON(float64(math.Float32frombits(binary.BigEndian.Uint32(it.body))))
+	case it.code == 0xcb:
+		e = abstraction.ExtraFromJSON(math.Float64frombits(binary.BigEndian.Uint64(it.body)))
+	case isMsgPackArray(it.code):
+		items := make([]abstraction.ExtraValue, 0, it.n)
+		pos = it.end
+		for range it.n {
+			item, next, ok := s.extraAt(pos, format)
+			if !ok {
+				return e, 0, false
+			}
+			items = append(items, item)
+			pos = next
+		}
+		e = abstraction.ListExtra(items...)
+		it.end = pos
+	case isMsgPackMap(it.code):
+		m := make(map[string]abstraction.ExtraValue, it.n)
+		pos = it.end
+		for range it.n {
+			k, _ := readMsgPack(s.data, pos)
+			if !isMsgPackStr(k.code) {
+				return e, 0, false
+			}
+			item, next, ok := s.extraAt(k.end, format)
+			if !ok {
+				return e, 0, false
+			}
+			m[string(k.body)] = item
+			pos = next
+		}
+		e = abstraction.MapExtra(m)
+		it.end = pos
+	default:
+		x, ok := msgpackBigInt(it, coercer{})
+		if !ok {
+			return e, 0, false //timestamp 등은 디코딩한 값으로
+		}
+		e = abstraction.ExtraValue{Kind: abstraction.ExtraInt, Int: x}
+	}
+	e.Encoding = string(format)
+	return e, it.end, true
+} //pos의 값을 extraValue와 같은 ExtraValue로(다음 위치 반환, 직접 읽지 않는 값은 false)
@@ -0,0 +1,129 @@
+package codec
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+var msgpackDirectOptions = []ParseOptions{
+	{Format: FormatMsgPack},
+	{Format: FormatMsgPack, HashEncoding: HashBase64, DetectBase64: true},
+	{Format: FormatMsgPack, HashEncoding: HashPreserve, ExactSynonyms: true, Limits: DefaultLimits},
+} //직접 경로와 트리 경로를 비교할 옵션
+
+func checkMsgPackDirect(t *testing.T, name string, data []byte) bool {
+	t.Helper()
+	used := false
+	for _, opts := range msgpackDirectOptions {
+		src, ok := newMsgPackSource(data, opts)
+		if !ok {
+			continue //msgpack.Unmarshal 경로 사용
+		}
+		used = true
+		direct, directErr := messageFromSource(src, opts)
+		src.release()
+		var decoded map[string]interface{}
+		if err := msgpack.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("%s: direct path accepted input msgpack.Unmarshal rejects: %v", name, err)
+		}
+		want, wantErr := messageFromMap(decoded, opts)
+		if (directErr != nil) != (wantErr != nil) {
+			t.Fatalf("%s: direct error %v, tree error %v", name, directErr, wantErr)
+		}
+		if !identicalMessage(direct, want) {
+			t.Fatalf("%s: direct path differs from msgpack.Unmarshal\n direct: %#v\n   want: %#v", name, direct, want)
+		}
+	}
+	return used
+} //직접 읽은 결과가 msgpack.Unmarshal 트리를 정규화한 결과와 같은지 확인(직접 경로를 사용했는지 반환)
+
+func mustMsgPack(t testing.TB, v interface{}) []byte {
+	t.Helper()
+	b, err := msgpack.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+} //테스트 입력 인코딩
+
+func TestMsgPackDirectMatchesUnmarshal(t *testing.T) {
+	fuzzSetup(t)
+	for i, am := range fuzzFixtures() {
+		data, err := Serialize(am, fuzzSerializeOptions(FormatMsgPack))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !checkMsgPackDirect(t, "fixture", data) {
+			t.Errorf("fixture %d fell back to msgpack.Unmarshal", i)
+		}
+	}
+	for _, size := range benchSizes {
+		data, err := Serialize(benchMessage(size.seals, size.changes, size.extras), fuzzSerializeOptions(FormatMsgPack))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !checkMsgPackDirect(t, size.name, data) {
+			t.Errorf("%s: benchmark message fell back to msgpack.Unmarshal", size.name)
+		}
+	}
+	ts := time.Date(2024, 3, 14, 9, 26, 53, 5, time.UTC)
+	cases := []struct {
+		name   string
+		data   []byte
+		direct bool
+	}{
+		{"int widths", mustMsgPack(t, map[string]interface{}{
+			"type": "commit", "height": uint64(1 << 63), "round": int8(-3), "view": int16(300),
+			"proposer": int64(-1 << 40), "signature": uint32(7), "extra": []interface{}{uint8(200), int32(-70000), float32(1.5), 2.0, nil, true},
+		}), true},
+		{"int fields", mustMsgPack(t, map[string]interface{}{"height": 0, "round": "-7", "view": uint64(1 << 62)}), true},
+		{"bytes", mustMsgPack(t, map[string]interface{}{
+			"height": []byte{1, 0}, "block_hash": []byte{0xab}, "validator": []byte("v"), "commit_seals": []interface{}{[]byte{1}, "0x02", nil, 3},
+		}), true},
+		{"seal scalar", mustMsgPack(t, map[string]interface{}{"commit_seals": []byte{1}}), true},
+		{"seal nested", mustMsgPack(t, map[string]interface{}{"commit_seals": []interface{}{map[string]interface{}{"a": 1}}}), true},
+		{"view changes", mustMsgPack(t, map[string]interface{}{"view_changes": []interface{}{
+			map[string]interface{}{"view": 1, "height": "0x10", "validator": []byte{9}, "signature": nil, "other": []interface{}{1}},
+			"skipped",
+			map[string]interface{}{"view": []interface{}{1}},
+		}}), true},
+		{"view changes scalar", mustMsgPack(t, map[string]interface{}{"view_changes": "x"}), true},
+		{"timestamps", mustMsgPack(t, map[string]interface{}{"timestamp": ts, "created": ts, "time": map[string]interface{}{"seconds": 1, "nanos": 2}}), true},
+		{"float fields", mustMsgPack(t, map[string]interface{}{"height": float32(2.5), "round": 3.0, "proposer": float32(1.5), "validator": 2.25, "signature": true}), true},
+		{"empty values", mustMsgPack(t, map[string]interface{}{"height": "", "block_number": "7", "round": nil, "view": ""}), true},
+		{"nested paths", mustMsgPack(t, map[string]interface{}{
+			"header": map[string]interface{}{"height": 9, "time": "2024-03-14T09:26:53Z"}, "block_id": map[string]interface{}{"hash": []byte{1}},
+			"extras": map[string]interface{}{"k": []interface{}{map[string]interface{}{"x": nil}}},
+		}), true},
+		{"ambiguous type", mustMsgPack(t, map[string]interface{}{"type": []byte("commit"), "height": 1}), true},
+		{"nil key inside", []byte{0x81, 0xa1, 'x', 0x81, 0xc0, 0x01}, true},
+		{"bin key inside", []byte{0x81, 0xa1, 'x', 0x81, 0xc4, 0x01, 'k', 0x01}, true},
+		{"duplicate key", []byte{0x82, 0xa6, 'h', 'e', 'i', 'g', 'h', 't', 0x01, 0xa6, 'h', 'e', 'i', 'g', 'h', 't', 0x02}, true},
+		{"bin top key", []byte{0x81, 0xc4, 0x01, 'k', 0x01}, false},
+		{"int key inside", []byte{0x81, 0xa1, 'x', 0x81, 0x01, 0x01}, false},
+		{"unknown ext", []byte{0x81, 0xa1, 'x', 0xd4, 0x05, 0x00}, false},
+		{"top nil", []byte{0xc0}, false},
+		{"truncated", []byte{0x81, 0xa1, 'x', 0xa5, 'a'}, false},
+	}
+	for _, tc := range cases {
+		if got := checkMsgPackDirect(t, tc.name, tc.data); got != tc.direct {
+			t.Errorf("%s: direct path used = %v, want %v", tc.name, got, tc.direct)
+		}
+	}
+} //fixture/벤치마크/경계 입력의 msgpack 직접 읽기 결과 비교
+
+func FuzzMsgPackDirect(f *testing.F) {
+	fuzzSeed(f, FormatMsgPack)
+	for _, size := range benchSizes {
+		b, err := Serialize(benchMessage(size.seals, size.changes, size.extras), fuzzSerializeOptions(FormatMsgPack))
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		checkMsgPackDirect(t, "fuzz", data)
+	})
+} //임의 입력에서 직접 경로가 msgpack.Unmarshal 경로와 같은 결과인지 확인
@@ -0,0 +1,101 @@
+package codec
+
+import (
+	"fmt"
+	"strings"
+
+	"codec/abstraction"
+)
+
+var canonicalFieldKeys = map[string]string{
+	"Height":      "height",
+	"Round":       "round",
+	"View":        "view",
+	"Timestamp":   "timestamp",
+	"BlockHash":   "block_hash",
+	"PrevHash":    "prev_hash",
+	"Proposer":    "proposer",
+	"Validator":   "validator",
+	"Signature":   "signature",
+	"CommitSeals": "commit_seals",
+	"ViewChanges": "view_changes",
+} //표준 필드명 -> 직렬화 시 기본 key
+
+type namer struct {
+	am    *abstraction.AbstractMessage
+	opts  SerializeOptions
+	vocab *Vocabulary //대상 구현체 어휘(nil일 시 표준 이름)
+} //직렬화 시 메시지명/필드 key 결정
+
+func newNamer(am *abstraction.AbstractMessage, opts SerializeOptions) (namer, error) {
+	n := namer{am: am, opts: opts}
+	if opts.Vocabulary != "" {
+		v, ok := LookupVocabulary(opts.Vocabulary)
+		if !ok {
+			return namer{}, fmt.Errorf("unknown vocabulary %q", opts.Vocabulary)
+		}
+		n.vocab = v
+	}
+	return n, nil
+} //AbstractMessage와 옵션으로 namer 생성
+
+func (n namer) phase() string {
+	if n.opts.PreserveOriginalNames && n.names("phase", n.am.OriginalMsgName, string(n.am.Type)) { //원본 메시지명 유지
+		return n.am.OriginalMsgName
+	}
+	if p, ok := n.vocab.phase(n.am.Type); ok { //대상 구현체 메시지명
+		return p
+	}
+	return string(n.am.Type)
+} //출력할 메시지 타입명(원본 이름 -> Vocabulary -> 표준 타입 순)
+
+func (n namer) key(field string) string {
+	if n.opts.PreserveOriginalNames {
+		if orig := n.am.OriginalFieldNames[field]; n.names("field", orig, field) { //원본 필드명 유지
+			return orig
+		}
+	}
+	if f, ok := n.vocab.field(field); ok { //대상 구현체 필드명
+		return f
+	}
+	return canonicalFieldKeys[field]
+} //표준 필드명(Height 등)에 대해 출력할 key(원본 이름 -> Vocabulary -> 표준 key 순)
+
+func (n namer) path(field string) []string {
+	k := n.key(field)
+	if f, ok := n.vocab.field(field); !ok || f != k || strings.IndexByte(k, '.') < 0 {
+		return nil
+	}
+	return strings.Split(k, ".")
+} //Vocabulary가 중첩 경로로 정한 필드(예: block_id.hash)의 key 목록, 최상위 key일 시 nil
+
+func (n namer) put(out map[string]interface{}, field string, v interface{}) {
+	p := n.path(field)
+	if p == nil {
+		out[n.key(field)] = v
+		return
+	}
+	for _, k := range p[:len(p)-1] {
+		child, ok := out[k].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			out[k] = child
+		}
+		out = child
+	}
+	out[p[len(p)-1]] = v
+} //out에 field 값을 출력 key(중첩 경로일 시 중첩 객체)로 설정
+
+func (n namer) names(kind, orig, want string) bool {
+	if orig == "" {
+		return false
+	}
+	canon, ok, err := ParseOptions{Synonyms: n.opts.Synonyms}.resolve(kind, orig)
+	if err != nil {
+		return false
+	}
+	if !ok { //사전에 없는 이름은 그대로 사용됨
+		canon = orig
+	}
+	return canon == want
+} //원본 이름을 다시 parsing해도 want(현재 타입/필드)가 되는지 확인(타입을 바꾸거나 필드를 옮긴 뒤 원본 이름을 쓰지 않도록)
@@ -0,0 +1,115 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"codec/abstraction"
+)
+
+func TestPreserveOriginalNames(t *testing.T) {
+	cases := []struct {
+		name   string
+		format Format
+		in     string
+		want   []string //출력에 있어야 할 원본 이름
+	}{
+		{"json", FormatJSON, `{"type":"PrePrepare","seq_num":7,"sig":"0xab"}`, []string{`"PrePrepare"`, `"seq_num"`, `"sig"`}},
+		{"generic", FormatGeneric, `Prevote(seq_num=7, sig=0xab)`, []string{"Prevote(", "seq_num=", "sig="}},
+	}
+	for _, tc := range cases {
+		am, err := Parse([]byte(tc.in), ParseOptions{Format: tc.format})
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := Serialize(am, SerializeOptions{Format: tc.format, PreserveOriginalNames: true, ForceReencode: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, w := range tc.want {
+			if !bytes.Contains(out, []byte(w)) {
+				t.Errorf("%s: output %s lacks original name %s", tc.name, out, w)
+			}
+		}
+		back, err := Parse(out, ParseOptions{Format: tc.format})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !back.Equal(am, abstraction.EqualOptions{IgnoreRawPayload: true}) {
+			t.Errorf("%s: re-parsed message differs: %s", tc.name, back.Diff(am, abstraction.EqualOptions{IgnoreRawPayload: true}))
+		}
+	}
+} //원본 메시지명/필드명으로 출력하고 다시 parsing하면 같은 메시지
+
+func TestPreserveOriginalNamesAfterEdit(t *testing.T) {
+	for _, format := range []Format{FormatJSON, FormatGeneric} {
+		in := `{"type":"Prevote","seq_num":7,"sig":"0xab"}`
+		if format == FormatGeneric {
+			in = `Prevote(seq_num=7, sig=0xab)`
+		}
+		am, err := Parse([]byte(in), ParseOptions{Format: format})
+		if err != nil {
+			t.Fatal(err)
+		}
+		am.Type = abstraction.MsgTypeCommit
+		am.Height = nil
+		am.OriginalFieldNames["Signature"] = "seq" //다른 필드를 가리키는 원본 이름
+		out, err := Serialize(am, SerializeOptions{Format: format, PreserveOriginalNames: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(out, []byte("Prevote")) || bytes.Contains(out, []byte("seq")) {
+			t.Errorf("%s: output %s still uses an original name that no longer fits", format, out)
+		}
+		back, err := Parse(out, ParseOptions{Format: format})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if back.Type != abstraction.MsgTypeCommit || back.Height != nil || back.Signature != "0xab" {
+			t.Errorf("%s: re-parsed type %q height %v signature %q from %s", format, back.Type, back.Height, back.Signature, out)
+		}
+	}
+} //타입을 바꾸거나 비운 필드, 다른 필드로 해석되는 원본 이름은 쓰지 않음
+
+func TestPreserveOriginalNamesSynonyms(t *testing.T) {
+	set := DefaultSynonyms()
+	set.SetPhase("Ballot", "Commit")
+	set.SetField("lvl", "Height")
+	am, err := Parse([]byte(`{"type":"Ballot","lvl":3}`), ParseOptions{Format: FormatJSON, Synonyms: set})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		synonyms *SynonymSet
+		want     string
+	}{
+		{nil, `{"height":"3","type":"Commit"}`}, //내장 사전에서는 원본 이름이 다른 의미
+		{set, `{"lvl":"3","type":"Ballot"}`},
+	} {
+		out, err := Serialize(am, SerializeOptions{Format: FormatJSON, PreserveOriginalNames: true, Synonyms: tc.synonyms})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got, want map[string]interface{}
+		if err := json.Unmarshal(out, &got); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(tc.want), &want); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Synonyms %v: output %s, want %s", tc.synonyms != nil, out, tc.want)
+		}
+	}
+	am.Height = big.NewInt(4)
+	out, err := Serialize(am, SerializeOptions{Format: FormatJSON, PreserveOriginalNames: true, Synonyms: set})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(out, []byte(`"lvl":"4"`)) {
+		t.Errorf("edited value output %s, want lvl 4", out)
+	}
+} //원본 이름은 SerializeOptions.Synonyms로 확인(parsing에 쓴 사전과 같을 때 유지)
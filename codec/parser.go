@@ -1,104 +1,179 @@
-package codec
-
-import (
-	"codec/abstraction"
-	"fmt"
-	"math/big"
-	"strings"
-	"time"
-)
-
-type genericCodec struct{} //Proposal(height=..., ...) 형태의 문자열을 parsing/serializing
-
-func (genericCodec) Parse(data []byte, _ ParseOptions) (*abstraction.AbstractMessage, error) {
-	raw := strings.TrimSpace(string(data)) //입력 바이트를 문자열로 바꾸고 양끝 공백 제거
-	if raw == "" {                         //빈 문자열일 시
-		return nil, fmt.Errorf("empty raw") //에러 반환
-	}
-	idx := strings.Index(raw, "(") //메시지명과 내용 구분하는 '(' 위치 탐색
-	if idx < 0 {                   // '(' 가 없을 시 포맷 오류
-		return nil, fmt.Errorf("invalid message: no '(', raw=%s", raw) //에러 반환
-	}
-	msgName := strings.TrimSpace(raw[:idx]) //'(' 이전 구간을 메시지명으로 추출하고 공백 제거
-	body := TrimBrackets(raw)               //괄호 안 내용만 잘라냄
-	am := &abstraction.AbstractMessage{
-		Extras:             map[string][]byte{},     //표준화되지 않은 필드는 binary로 보존
-		RawPayload:         []byte(raw),             //입력 원문
-		OriginalFormat:     string(FormatGeneric),   //현재 format: generic
-		OriginalMsgName:    msgName,                 //원본 메시지명
-		OriginalFieldNames: make(map[string]string), //원본 필드명 -> 표준 필드명 매핑
-	} //AbstractMessage 초기화
-	if t, ok := PhaseSynonyms[msgName]; ok { // 유의어 존재할 시
-		am.Type = abstraction.MsgType(t) // 표준 타입명으로 설정
-	} else { // 유의어 없을 시
-		am.Type = abstraction.MsgType(msgName) // 원문 그대로 사용
-		am.OriginalMsgName = msgName
-	}
-	kv := SplitKeyValuePairs(body) //key=value 쌍의 맵으로 parsing
-	for k, v := range kv {
-		if fld, ok := FieldSynonyms[k]; ok { //원본 필드명 -> 표준 필드명 정규화
-			am.OriginalFieldNames[fld] = k //원본 필드명 기록
-			switch fld {
-			case "Height":
-				if x, ok := new(big.Int).SetString(v, 10); ok { //10진수 문자열 -> big.Int 변환
-					am.Height = x
-				}
-			case "Round":
-				if x, ok := new(big.Int).SetString(v, 10); ok {
-					am.Round = x
-				}
-			case "View":
-				if x, ok := new(big.Int).SetString(v, 10); ok {
-					am.View = x
-				}
-			case "BlockHash":
-				am.BlockHash = v
-			case "PrevHash":
-				am.PrevHash = v
-			case "Timestamp":
-				if t2, err := time.Parse(time.RFC3339, v); err == nil { //RFC3339 시도
-					am.Timestamp = t2
-				} else if sec, ok := new(big.Int).SetString(v, 10); ok { //epoch seconds 시도
-					am.Timestamp = time.Unix(sec.Int64(), 0).UTC() //초 단위 epoch -> UTC Time
-				}
-			case "Proposer":
-				am.Proposer = v
-			case "Validator":
-				am.Validator = v
-			case "Signature":
-				am.Signature = v
-			case "CommitSeals": //','로 구분된 문자열 리스트
-				am.CommitSeals = strings.Split(v, ",")
-			case "ViewChanges": //view:height:validator:signature 형식의 리스트
-				am.ViewChanges = parseViewChanges(v)
-			default:
-				am.Extras[k] = []byte(v) //정의되지 않은 필드명
-			}
-		} else {
-			am.Extras[k] = []byte(v) //유의어 존재하지 않는 필드
-		}
-	}
-	return am, nil //parsing한 메시지 반환
-} //generic 포맷의 바이트를 AbstractMessage로 변환
-
-func parseViewChanges(raw string) []abstraction.ViewChangeEntry {
-	var entries []abstraction.ViewChangeEntry
-	items := strings.Split(raw, ",") //','로 분리
-	for _, item := range items {
-		parts := strings.Split(strings.TrimSpace(item), ":") //view:height:validator:signature 분해
-		if len(parts) < 4 {                                  //필드 개수 부족할 시
-			continue
-		}
-		view, _ := new(big.Int).SetString(parts[0], 10)   //view 10진수 -> big.Int (실패 시 nil)
-		height, _ := new(big.Int).SetString(parts[1], 10) //height 10진수 -> big.Int
-		validator := parts[2]                             //validator 문자열
-		signature := parts[3]                             //signature 문자열
-		entries = append(entries, abstraction.ViewChangeEntry{
-			View:      view,
-			Height:    height,
-			Validator: validator,
-			Signature: signature,
-		}) //parsing된 값을 ViewChangeEntry에 추가
-	}
-	return entries
-} //view:height:validator:signature 문자열을 필드 4개로 구성된 []ViewChangeEntry로 변환
+package codec
+
+import (
+	"codec/abstraction"
+	"math/big"
+	"strings"
+)
+
+type genericCodec struct{} //Proposal(height=..., ...) 형태의 문자열을 parsing/serializing
+
+func (genericCodec) Parse(data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error) {
+	msgName, fields, err := parseGenericMessage(data, opts.Limits) //문법에 따라 tokenize/parsing
+	if err != nil {
+		return nil, err
+	}
+	am := &abstraction.AbstractMessage{
+		Extras:             map[string]abstraction.ExtraValue{}, //표준화되지 않은 필드는 타입 있는 값으로 보존
+		OriginalFormat:     string(FormatGeneric),               //현재 format: generic
+		OriginalMsgName:    msgName,                             //원본 메시지명
+		OriginalFieldNames: make(map[string]string),             //원본 필드명 -> 표준 필드명 매핑
+	} //AbstractMessage 초기화
+	t, ok, err := opts.resolve("phase", msgName)
+	if err != nil { //모호한 메시지명(StrictSynonyms)
+		return nil, newParseError(FormatGeneric, data, 0, "", err)
+	}
+	if ok { // 유의어 존재할 시
+		am.Type = abstraction.MsgType(t) // 표준 타입명으로 설정
+	} else { // 유의어 없을 시
+		am.Type = abstraction.MsgType(msgName) // 원문 그대로 사용
+	}
+	keys := make([]string, 0, len(fields))
+	offsets := make(map[string]int, len(fields)) //key -> 입력 내 위치
+	for _, f := range fields {
+		if _, dup := offsets[f.key]; !dup {
+			keys = append(keys, f.key)
+		}
+		offsets[f.key] = f.offset
+	}
+	opts.Format = FormatGeneric
+	values := make(map[string]genericValue, len(fields))
+	for _, f := range fields {
+		values[f.key] = f.value
+	}
+	//같은 필드의 유의어가 여럿일 시 우선순위로 하나만 채택(빈 값은 가장 뒤, 밀린 key는 Extras로)
+	assign, _, err := resolveFields(keys, func(k string) bool { return values[k].kind == genericScalar && values[k].text == "" }, opts)
+	if err != nil { //모호한 필드명(StrictSynonyms)
+		pe := err.(*ParseError)
+		return nil, newParseError(FormatGeneric, data, int64(offsets[pe.Field]), pe.Field, pe.Err)
+	}
+	c := newCoercer(opts)
+	tree := map[string]interface{}{} //경로 규칙을 적용할 중첩 값
+	var nested []string
+	for _, f := range fields {
+		k, v := f.key, f.value
+		fld, ok := assign[k] //원본 필드명 -> 표준 필드명 정규화(유의어가 없거나 우선순위에서 밀린 필드는 제외)
+		if v.kind != genericScalar {
+			if iv := extraInterface(genericExtraValue(v)); !ok || isObjectForScalar(fld, iv) { //block_id={hash=..} 등은 경로 규칙으로
+				am.Extras[k] = genericExtraValue(v)
+				tree[k] = iv
+				nested = append(nested, k)
+				continue
+			}
+		}
+		if !ok {
+			am.Extras[k] = genericExtraValue(v) //유의어 존재하지 않는 필드
+			continue
+		}
+		am.OriginalFieldNames[fld] = k //원본 필드명 기록
+		switch fld {
+		case "Height":
+			am.Height = parseGenericInt(v, c)
+		case "Round":
+			am.Round = parseGenericInt(v, c)
+		case "View":
+			am.View = parseGenericInt(v, c)
+		case "BlockHash":
+			am.BlockHash = c.hash(v.text)
+		case "PrevHash":
+			am.PrevHash = c.hash(v.text)
+		case "Timestamp":
+			am.Timestamp = c.time(genericTimeValue(v)) //RFC3339, epoch s/ms/µs/ns 또는 {seconds=..,nanos=..}
+		case "Proposer":
+			am.Proposer = v.text
+		case "Validator":
+			am.Validator = v.text
+		case "Signature":
+			am.Signature = c.hash(v.text)
+		case "CommitSeals": //[a,b,...] 리스트
+			am.CommitSeals = c.hashes(parseGenericStrings(v))
+		case "ViewChanges": //[{view=..,height=..,validator=..,signature=..},...] 리스트
+			am.ViewChanges = parseViewChanges(v, c)
+		default:
+			am.Extras[k] = genericExtraValue(v) //정의되지 않은 필드명
+		}
+	}
+	extractPaths(am, nested, func(k string) interface{} { return tree[k] }, c, opts) //중첩 record/list에서 경로 규칙으로 표준 필드 추출
+	return am, nil                                                                   //parsing한 메시지 반환
+} //generic 포맷의 바이트를 AbstractMessage로 변환
+
+func genericExtra(v genericValue) string {
+	if v.kind == genericScalar {
+		return v.text
+	}
+	return v.raw //list/record는 원문 표기 그대로 보존
+} //commit seal 원소 등 문자열로 쓸 값 표현
+
+func genericTimeValue(v genericValue) interface{} {
+	if v.kind != genericRecord {
+		return v.text
+	}
+	m := map[string]interface{}{}
+	for _, f := range v.fields {
+		m[f.key] = f.value.text
+	}
+	return m
+} //timestamp 값(scalar 또는 {seconds=..,nanos=..} record)
+
+func parseGenericInt(v genericValue, c coercer) *big.Int {
+	if v.kind != genericScalar {
+		return nil
+	}
+	return c.parseInt(v.text) //10진수 또는 0x-hex 문자열 -> big.Int 변환
+} //scalar 정수 값을 *big.Int로 변환(실패 시 nil)
+
+func parseGenericStrings(v genericValue) []string {
+	switch v.kind {
+	case genericList:
+		out := make([]string, 0, len(v.items))
+		for _, item := range v.items {
+			out = append(out, genericExtra(item))
+		}
+		return out
+	case genericScalar:
+		if v.text == "" {
+			return nil
+		}
+		return []string{v.text} //원소 하나짜리 리스트로 간주
+	}
+	return nil
+} //list 값을 []string으로 변환
+
+func parseViewChanges(v genericValue, c coercer) []abstraction.ViewChangeEntry {
+	items := v.items
+	if v.kind != genericList { //list가 아닐 시 원소 하나로 간주
+		items = []genericValue{v}
+	}
+	var entries []abstraction.ViewChangeEntry
+	for _, item := range items {
+		switch item.kind {
+		case genericRecord: //{view=..,height=..,validator=..,signature=..}
+			var e abstraction.ViewChangeEntry
+			for _, f := range item.fields {
+				switch f.key {
+				case "view":
+					e.View = parseGenericInt(f.value, c)
+				case "height":
+					e.Height = parseGenericInt(f.value, c)
+				case "validator":
+					e.Validator = f.value.text
+				case "signature":
+					e.Signature = c.hash(f.value.text)
+				}
+			}
+			entries = append(entries, e)
+		case genericScalar: //이전 버전의 view:height:validator:signature 표기
+			parts := strings.Split(strings.TrimSpace(item.text), ":")
+			if len(parts) < 4 { //필드 개수 부족할 시
+				continue
+			}
+			entries = append(entries, abstraction.ViewChangeEntry{
+				View:      c.parseInt(parts[0]), //실패 시 nil
+				Height:    c.parseInt(parts[1]),
+				Validator: parts[2],
+				Signature: c.hash(parts[3]),
+			})
+		}
+	}
+	return entries
+} //record 리스트를 []ViewChangeEntry로 변환
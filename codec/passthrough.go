@@ -0,0 +1,476 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"codec/abstraction"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	bcs "github.com/fardream/go-bcs/bcs"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func passthrough(am *abstraction.AbstractMessage, format Format, opts SerializeOptions) ([]byte, bool) {
+	if opts.ForceReencode || len(am.RawPayload) == 0 || !am.Tracked() || string(format) != am.OriginalFormat {
+		return nil, false
+	}
+	if opts.Vocabulary != "" || opts.TimestampFormat != "" { //출력 형태를 바꾸는 옵션은 재인코딩
+		return nil, false
+	}
+	if format == FormatProtobuf && opts.ProtoMessageFullName != am.OriginalSchema { //다른 스키마로 출력
+		return nil, false
+	}
+	changed := am.ChangedFields()
+	if len(changed) == 0 { //변경 없음: 원본 바이트 그대로
+		return append([]byte(nil), am.RawPayload...), true
+	}
+	switch format {
+	case FormatJSON:
+		return patchJSON(am.RawPayload, am, changed)
+	case FormatGeneric:
+		return patchGeneric(am.RawPayload, am, changed)
+	case FormatMsgPack:
+		return patchMsgPack(am.RawPayload, am, changed)
+	case FormatRLP:
+		var inner []byte
+		if rlp.DecodeBytes(am.RawPayload, &inner) != nil {
+			return nil, false
+		}
+		if b, ok := patchJSON(inner, am, changed); ok {
+			out, err := rlp.EncodeToBytes(b)
+			return out, err == nil
+		}
+	case FormatBCS:
+		var inner []byte
+		if _, err := bcs.Unmarshal(am.RawPayload, &inner); err != nil {
+			return nil, false
+		}
+		if b, ok := patchJSON(inner, am, changed); ok {
+			out, err := bcs.Marshal(b)
+			return out, err == nil
+		}
+	}
+	return nil, false //protobuf는 재인코딩하며 unknown 필드 보존
+} //parsing한 포맷 그대로 출력할 때 원본 바이트 재사용(변경된 필드만 원본에 반영), 불가능할 시 false
+
+type patchEdit struct {
+	field   string //표준 필드명(Type, Extras는 빈 값)
+	key     string
+	path    []string    //key 아래 중첩 경로(경로 규칙으로 채운 필드, 최상위 key일 시 nil)
+	present bool        //false일 시 key 삭제
+	value   interface{} //messageToMap 기준 값
+} //원본 key(또는 그 아래 경로) 하나에 대한 변경
+
+func patchEdits(am *abstraction.AbstractMessage, changed []string, extra func(abstraction.ExtraValue) interface{}) ([]patchEdit, bool) {
+	opts := SerializeOptions{Format: FormatJSON, PreserveOriginalNames: true}
+	full, err := messageToMap(am, opts, extra)
+	if err != nil {
+		return nil, false
+	}
+	n, _ := newNamer(am, opts)
+	var edits []patchEdit
+	for _, field := range changed {
+		var key string
+		switch k, isExtra := abstraction.ExtraKey(field); {
+		case field == "Type": //원본 메시지명은 바뀐 타입과 맞지 않으므로 표준 이름으로
+			edits = append(edits, patchEdit{key: "type", present: true, value: string(am.Type)})
+			continue
+		case isExtra:
+			if strings.ContainsAny(k, ".[") { //중첩 경로에서 온 Extras는 원본 위치를 찾을 수 없음
+				return nil, false
+			}
+			key = k
+		default:
+			if p, nested := am.OriginalPaths[field]; nested { //경로 규칙으로 채운 필드는 원래 위치에
+				toks := splitPath(p)
+				if len(toks) < 2 { //여러 경로에서 모은 값 등 한 위치로 되돌릴 수 없음
+					return nil, false
+				}
+				v, ok := full[n.key(field)]
+				edits = append(edits, patchEdit{field: field, key: toks[0], path: toks[1:], present: ok, value: v})
+				continue
+			}
+			key = am.OriginalFieldNames[field]
+			if key == "" {
+				key = canonicalFieldKeys[field] //새로 추가된 필드
+			}
+			v, ok := full[n.key(field)] //원본 이름이 다른 필드를 가리키면 출력 key는 표준 이름
+			edits = append(edits, patchEdit{field: field, key: key, present: ok, value: v})
+			continue
+		}
+		v, ok := full[key]
+		edits = append(edits, patchEdit{field: field, key: key, present: ok, value: v})
+	}
+	return edits, true
+} //변경된 필드 목록을 원본 key 단위 변경으로 변환
+
+type docMember struct {
+	key      string
+	start    int //key 시작 위치
+	valStart int //값 시작 위치
+	valEnd   int //값 끝 위치
+} //원본 문서의 key-value 한 쌍 위치
+
+func spliceMembers(raw []byte, members []docMember, closeAt int, set map[string][]byte, added [][]byte, defaultSep string) []byte {
+	sep := []byte(defaultSep)
+	if len(members) > 1 {
+		sep = raw[members[0].valEnd:members[1].start] //첫 구분자(공백 포함)를 새 key에 사용
+	}
+	prefix, suffix := raw[:closeAt], raw[closeAt:]
+	if len(members) > 0 {
+		prefix, suffix = raw[:members[0].start], raw[members[len(members)-1].valEnd:]
+	}
+	var out bytes.Buffer
+	out.Write(prefix)
+	first := true
+	write := func(lead, member []byte) {
+		if !first {
+			out.Write(lead)
+		}
+		out.Write(member)
+		first = false
+	}
+	for i, m := range members {
+		lead := sep
+		if i > 0 {
+			lead = raw[members[i-1].valEnd:m.start] //원본에서 이 key 앞의 구분자
+		}
+		v, ok := set[m.key]
+		switch {
+		case !ok: //변경 없음
+			write(lead, raw[m.start:m.valEnd])
+		case v != nil: //값 교체(key 표기와 그 사이 공백은 원본 유지)
+			write(lead, append(append([]byte(nil), raw[m.start:m.valStart]...), v...))
+		} //v == nil이면 삭제
+	}
+	for _, a := range added {
+		write(sep, a)
+	}
+	out.Write(suffix)
+	return out.Bytes()
+} //원본 문서에서 일부 값만 교체/삭제/추가(나머지 바이트, 순서, 구분자는 그대로)
+
+func scanJSONObject(raw []byte) ([]docMember, int, bool) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, 0, false
+	}
+	var members []docMember
+	for dec.More() {
+		pos := int(dec.InputOffset())
+		for pos < len(raw) && raw[pos] != '"' { //구분자와 공백 건너뜀
+			pos++
+		}
+		t, err := dec.Token()
+		if err != nil {
+			return nil, 0, false
+		}
+		key, _ := t.(string)
+		var val json.RawMessage
+		if err := dec.Decode(&val); err != nil {
+			return nil, 0, false
+		}
+		end := int(dec.InputOffset())
+		members = append(members, docMember{key: key, start: pos, valStart: end - len(val), valEnd: end})
+	}
+	closeAt := int(dec.InputOffset())
+	for closeAt < len(raw) && raw[closeAt] != '}' {
+		closeAt++
+	}
+	if closeAt == len(raw) {
+		return nil, 0, false
+	}
+	return members, closeAt, true
+} //최상위 JSON 객체의 key별 위치 수집
+
+func patchJSON(raw []byte, am *abstraction.AbstractMessage, changed []string) ([]byte, bool) {
+	edits, ok := patchEdits(am, changed, extraInterface)
+	if !ok {
+		return nil, false
+	}
+	for _, e := range edits { //중첩 경로 값은 먼저 그 자리에서 교체
+		if e.path != nil {
+			if raw, ok = patchJSONPath(raw, e); !ok {
+				return nil, false
+			}
+		}
+	}
+	members, closeAt, ok := scanJSONObject(raw)
+	if !ok {
+		return nil, false
+	}
+	existing := map[string]docMember{}
+	for _, m := range members {
+		existing[m.key] = m
+	}
+	set := map[string][]byte{}
+	var added [][]byte
+	for _, e := range edits {
+		if e.path != nil {
+			continue
+		}
+		m, found := existing[e.key]
+		var vb []byte
+		if e.present {
+			var orig []byte
+			if found {
+				orig = raw[m.valStart:m.valEnd]
+			}
+			if vb, ok = patchJSONValue(e, orig); !ok {
+				return nil, false
+			}
+		}
+		if found {
+			set[e.key] = vb
+		} else if e.present {
+			kb, _ := json.Marshal(e.key)
+			added = append(added, append(append(kb, ':'), vb...))
+		}
+	}
+	return spliceMembers(raw, members, closeAt, set, added, ","), true
+} //원본 JSON에서 변경된 key(또는 중첩 경로)의 값만 교체(나머지 바이트는 그대로)
+
+func patchJSONValue(e patchEdit, orig []byte) ([]byte, bool) {
+	if s, ok := e.value.(string); ok && isIntegerField(e.field) && len(orig) > 0 && (orig[0] == '-' || '0' <= orig[0] && orig[0] <= '9') && jsonIntToken([]byte(s)) {
+		return []byte(s), true //원본이 JSON 수이면 따옴표 없이
+	}
+	b, err := json.Marshal(e.value)
+	return b, err == nil
+} //교체할 값의 JSON 표기(원본 값 orig가 수이면 수로)
+
+func isIntegerField(field string) bool {
+	return field == "Height" || field == "Round" || field == "View"
+} //*big.Int 표준 필드
+
+func patchJSONPath(raw []byte, e patchEdit) ([]byte, bool) {
+	toks := append([]string{e.key}, e.path...)
+	start, end := 0, len(raw)
+	for _, t := range toks[:len(toks)-1] { //바뀔 값을 담은 객체/배열까지 내려감
+		s, en, ok := jsonChild(raw[start:end], t)
+		if !ok {
+			return nil, false
+		}
+		start, end = start+s, start+en
+	}
+	parent, last := raw[start:end], toks[len(toks)-1]
+	s, en, ok := jsonChild(parent, last)
+	if !ok {
+		return nil, false
+	}
+	var vb []byte
+	if e.present {
+		if vb, ok = patchJSONValue(e, parent[s:en]); !ok {
+			return nil, false
+		}
+	}
+	var patched []byte
+	switch {
+	case !strings.HasPrefix(last, "["): //객체 member(삭제 포함)
+		members, closeAt, _ := scanJSONObject(parent)
+		patched = spliceMembers(parent, members, closeAt, map[string][]byte{last: vb}, nil, ",")
+	case e.present:
+		patched = append(append(append([]byte(nil), parent[:s]...), vb...), parent[en:]...)
+	default: //배열 원소를 지우면 뒤 원소 위치가 바뀜
+		return nil, false
+	}
+	return append(append(append([]byte(nil), raw[:start]...), patched...), raw[end:]...), true
+} //e.key 아래 e.path 위치의 값을 교체(present가 false면 객체 member 삭제)
+
+func jsonChild(raw []byte, tok string) (int, int, bool) {
+	if strings.HasPrefix(tok, "[") {
+		i, ok := pathIndex(tok)
+		items, scanned := scanJSONArray(raw)
+		if !ok || !scanned || i >= len(items) {
+			return 0, 0, false
+		}
+		return items[i][0], items[i][1], true
+	}
+	members, _, ok := scanJSONObject(raw)
+	if !ok {
+		return 0, 0, false
+	}
+	for i := len(members) - 1; i >= 0; i-- { //중복 key는 decoder처럼 마지막 값
+		if members[i].key == tok {
+			return members[i].valStart, members[i].valEnd, true
+		}
+	}
+	return 0, 0, false
+} //JSON 객체의 key 또는 배열의 "[N]" 원소 값 위치
+
+func scanJSONArray(raw []byte) ([][2]int, bool) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if t, err := dec.Token(); err != nil || t != json.Delim('[') {
+		return nil, false
+	}
+	var items [][2]int
+	for dec.More() {
+		var val json.RawMessage
+		if err := dec.Decode(&val); err != nil {
+			return nil, false
+		}
+		end := int(dec.InputOffset())
+		items = append(items, [2]int{end - len(val), end})
+	}
+	return items, true
+} //JSON 배열 원소별 위치
+
+func pathIndex(tok string) (int, bool) {
+	if len(tok) < 3 || tok[0] != '[' || tok[len(tok)-1] != ']' {
+		return 0, false
+	}
+	i, err := strconv.Atoi(tok[1 : len(tok)-1])
+	return i, err == nil && i >= 0
+} //"[N]" -> N
+
+func patchGeneric(raw []byte, am *abstraction.AbstractMessage, changed []string) ([]byte, bool) {
+	_, fields, err := parseGenericMessage(raw, Limits{}) //이미 parsing된 원본
+	if err != nil {
+		return nil, false
+	}
+	opts := SerializeOptions{Format: FormatGeneric, PreserveOriginalNames: true}
+	parts, err := genericParts(am, opts)
+	if err != nil {
+		return nil, false
+	}
+	n, _ := newNamer(am, opts)
+	texts := map[string]string{}
+	for _, p := range parts {
+		texts[p.key] = p.value
+	}
+	existing := map[string]bool{}
+	members := make([]docMember, 0, len(fields))
+	for _, f := range fields {
+		existing[f.key] = true
+		end := f.value.offset + len(f.value.raw)
+		members = append(members, docMember{key: f.key, start: f.offset, valStart: f.value.offset, valEnd: end})
+	}
+	set := map[string][]byte{}
+	var added [][]byte
+	retype := false
+	for _, field := range changed {
+		key := am.OriginalFieldNames[field]
+		switch k, isExtra := abstraction.ExtraKey(field); {
+		case field == "Type":
+			retype = true
+			continue
+		case isExtra:
+			if !existing[k] && strings.ContainsAny(k, ".[") {
+				return nil, false
+			}
+			key = k
+		case key == "":
+			if _, nested := am.OriginalPaths[field]; nested { //중첩 record 안의 값은 재인코딩
+				return nil, false
+			}
+			key = canonicalFieldKeys[field]
+		}
+		tk := key
+		if _, isExtra := abstraction.ExtraKey(field); !isExtra {
+			tk = n.key(field) //genericParts가 쓴 key
+		}
+		text, present := texts[tk]
+		switch {
+		case existing[key] && present:
+			set[key] = []byte(text)
+		case existing[key]:
+			set[key] = nil
+		case present:
+			added = append(added, []byte(quoteGeneric(key)+"="+text))
+		}
+	}
+	closeAt := bytes.LastIndexByte(raw, ')')
+	if closeAt < 0 {
+		return nil, false
+	}
+	out := spliceMembers(raw, members, closeAt, set, added, ",")
+	if retype { //메시지명 교체(앞 공백 유지)
+		lx := &genericLexer{data: out}
+		name, err := lx.next()
+		if err != nil {
+			return nil, false
+		}
+		open, err := lx.next()
+		if err != nil {
+			return nil, false
+		}
+		out = append(append(append([]byte(nil), out[:name.offset]...), quoteGeneric(string(am.Type))...), out[open.offset:]...)
+	}
+	return out, true
+} //원본 generic 문자열에서 변경된 필드 값만 교체
+
+func patchMsgPack(raw []byte, am *abstraction.AbstractMessage, changed []string) ([]byte, bool) {
+	var m map[string]interface{}
+	if err := msgpack.Unmarshal(raw, &m); err != nil {
+		return nil, false
+	}
+	edits, ok := patchEdits(am, changed, msgpackExtra)
+	if !ok {
+		return nil, false
+	}
+	for _, e := range edits {
+		if !patchMsgPackValue(m, e) {
+			return nil, false
+		}
+	}
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetSortMapKeys(true)
+	if err := enc.Encode(m); err != nil {
+		return nil, false
+	}
+	return buf.Bytes(), true
+} //원본 MessagePack map에서 변경된 key만 교체(다른 값은 원래 타입 그대로)
+
+func patchMsgPackValue(m map[string]interface{}, e patchEdit) bool {
+	var parent interface{} = m
+	toks := append([]string{e.key}, e.path...)
+	for _, t := range toks[:len(toks)-1] {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			parent = p[t]
+		case []interface{}:
+			i, ok := pathIndex(t)
+			if !ok || i >= len(p) {
+				return false
+			}
+			parent = p[i]
+		default:
+			return false
+		}
+	}
+	last := toks[len(toks)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		old, found := p[last]
+		switch {
+		case !e.present:
+			delete(p, last)
+		case e.path != nil && !found: //원래 경로에 값이 없음
+			return false
+		default:
+			p[last] = msgpackPatchValue(e, old)
+		}
+	case []interface{}:
+		i, ok := pathIndex(last)
+		if !ok || i >= len(p) || !e.present {
+			return false
+		}
+		p[i] = msgpackPatchValue(e, p[i])
+	default:
+		return false
+	}
+	return true
+} //decoding한 map에서 e.key(와 e.path) 위치의 값을 교체/삭제
+
+func msgpackPatchValue(e patchEdit, old interface{}) interface{} {
+	if s, ok := e.value.(string); ok && isIntegerField(e.field) {
+		switch old.(type) {
+		case int8, int16, int32, int64, uint8, uint16, uint32, uint64:
+			return msgpackValue(json.Number(s)) //원본이 정수이면 정수로
+		}
+	}
+	return msgpackValue(e.value)
+} //교체할 값의 msgpack 표현(원본 값 old가 정수이면 정수 유지)
@@ -0,0 +1,149 @@
+package codec
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"codec/abstraction"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestTrackChangesOptIn(t *testing.T) {
+	in := []byte(`{ "type": "Commit", "height": 7, "chain": "x" }`)
+	am, err := Parse(in, ParseOptions{Format: FormatJSON})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if am.Tracked() {
+		t.Fatal("Parse tracked changes without TrackChanges")
+	}
+	am, err = Parse(in, ParseOptions{Format: FormatJSON, TrackChanges: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := Serialize(am, SerializeOptions{Format: FormatJSON})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, in) {
+		t.Fatalf("unchanged tracked message = %s, want original bytes", out)
+	}
+	am.Height = big.NewInt(8)
+	out, err = Serialize(am, SerializeOptions{Format: FormatJSON})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{ "type": "Commit", "height": 8, "chain": "x" }`; string(out) != want {
+		t.Fatalf("patched = %s, want %s", out, want)
+	}
+} //TrackChanges일 때만 기준 상태 기록, 변경 없으면 원본 그대로, 바뀐 값만 교체
+
+func TestPatchPathExtractedField(t *testing.T) {
+	in := []byte(`{"type":"Prevote","vote":{"height":5,"round":1,"signature":"0xab"},"chain":"x"}`)
+	cases := []struct {
+		name string
+		edit func(am *abstraction.AbstractMessage)
+		want string
+	}{
+		{"replace", func(am *abstraction.AbstractMessage) { am.Height = big.NewInt(99) },
+			`{"type":"Prevote","vote":{"height":99,"round":1,"signature":"0xab"},"chain":"x"}`},
+		{"replace string", func(am *abstraction.AbstractMessage) { am.Signature = "0xcd" },
+			`{"type":"Prevote","vote":{"height":5,"round":1,"signature":"0xcd"},"chain":"x"}`},
+		{"clear", func(am *abstraction.AbstractMessage) { am.Round = nil },
+			`{"type":"Prevote","vote":{"height":5,"signature":"0xab"},"chain":"x"}`},
+	}
+	for _, tc := range cases {
+		am, err := Parse(in, ParseOptions{Format: FormatJSON, TrackChanges: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := am.OriginalPaths["Height"]; got != "vote.height" {
+			t.Fatalf("OriginalPaths[Height] = %q, want vote.height", got)
+		}
+		tc.edit(am)
+		out, err := Serialize(am, SerializeOptions{Format: FormatJSON})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != tc.want {
+			t.Errorf("%s: patched = %s, want %s", tc.name, out, tc.want)
+		}
+		back, err := Parse(out, ParseOptions{Format: FormatJSON})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !back.Equal(am, abstraction.EqualOptions{IgnoreRawPayload: true}) {
+			t.Errorf("%s: re-parsed message differs: %s", tc.name, back.Diff(am, abstraction.EqualOptions{IgnoreRawPayload: true}))
+		}
+	}
+} //경로 규칙으로 채운 필드를 바꾸면 원래 중첩 위치의 값만 교체(최상위 key를 추가하지 않음)
+
+func TestPatchPathExtractedMsgPack(t *testing.T) {
+	in, err := msgpack.Marshal(map[string]interface{}{"type": "Prevote", "vote": map[string]interface{}{"height": 5, "round": 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	am, err := Parse(in, ParseOptions{Format: FormatMsgPack, TrackChanges: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	am.Height = big.NewInt(99)
+	out, err := Serialize(am, SerializeOptions{Format: FormatMsgPack})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := msgpack.Unmarshal(out, &m); err != nil {
+		t.Fatal(err)
+	}
+	if _, top := m["height"]; top {
+		t.Errorf("patched msgpack added a top-level height: %v", m)
+	}
+	vote, _ := m["vote"].(map[string]interface{})
+	if h := reflect.ValueOf(vote["height"]); !h.CanInt() || h.Int() != 99 {
+		t.Errorf("vote.height = %#v, want int 99", vote["height"])
+	}
+	back, err := Parse(out, ParseOptions{Format: FormatMsgPack})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Height == nil || back.Height.Int64() != 99 || len(back.Extras) != 0 {
+		t.Errorf("re-parsed height %v, extras %v", back.Height, back.Extras)
+	}
+} //msgpack도 중첩 위치의 값을 정수 그대로 교체
+
+func TestPatchPathExtractedReencodes(t *testing.T) {
+	cases := []struct {
+		name   string
+		format Format
+		in     string
+		edit   func(am *abstraction.AbstractMessage)
+	}{
+		{"generic record", FormatGeneric, `Prevote(vote={height=5, round=1})`, func(am *abstraction.AbstractMessage) { am.Height = big.NewInt(99) }},
+		{"collected seals", FormatJSON, `{"type":"Commit","signatures":[{"sig":"0x01"},{"sig":"0x02"}]}`, func(am *abstraction.AbstractMessage) { am.CommitSeals = am.CommitSeals[:1] }},
+	}
+	for _, tc := range cases {
+		am, err := Parse([]byte(tc.in), ParseOptions{Format: tc.format, TrackChanges: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tc.edit(am)
+		if _, ok := passthrough(am, tc.format, SerializeOptions{Format: tc.format}); ok {
+			t.Errorf("%s: patched in place, want full re-encoding", tc.name)
+		}
+		out, err := Serialize(am, SerializeOptions{Format: tc.format})
+		if err != nil {
+			t.Fatal(err)
+		}
+		back, err := Parse(out, ParseOptions{Format: tc.format})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(back.Height, am.Height) || !reflect.DeepEqual(back.CommitSeals, am.CommitSeals) {
+			t.Errorf("%s: re-parsed height %v seals %v, want %v %v (%s)", tc.name, back.Height, back.CommitSeals, am.Height, am.CommitSeals, out)
+		}
+	}
+} //원본 위치로 되돌릴 수 없는 경로 값은 전체 재인코딩
@@ -1,187 +1,628 @@
-//.proto, .pb.go, .desc(option) 필요
-package codec
-
-import (
-	"fmt"
-	"log"
-	"os"
-	"path/filepath"
-	"strings"
-
-	"google.golang.org/protobuf/encoding/protojson"
-	"google.golang.org/protobuf/proto"
-	"google.golang.org/protobuf/reflect/protodesc"
-	"google.golang.org/protobuf/reflect/protoreflect"
-	"google.golang.org/protobuf/reflect/protoregistry"
-	"google.golang.org/protobuf/types/descriptorpb"
-	"google.golang.org/protobuf/types/dynamicpb"
-
-	"codec/abstraction"
-)
-
-type ProtoDescriptorProvider interface {
-	FindMessageByName(fullName protoreflect.FullName) (protoreflect.MessageDescriptor, error)
-} //full name으로 메시지 descriptor 탐색
-
-type globalRegistryProvider struct{} //global registry 조회
-
-func (globalRegistryProvider) FindMessageByName(fn protoreflect.FullName) (protoreflect.MessageDescriptor, error) {
-	mt, err := protoregistry.GlobalTypes.FindMessageByName(fn)
-	if err != nil {
-		return nil, err
-	}
-	return mt.Descriptor(), nil
-} //global registry에서 메시지 타입 찾고 descriptor 반환
-
-var DefaultDescriptorRegistry = NewDescriptorRegistry() //파일 registry 인스턴스
-
-type compositeProvider struct {
-	primary  ProtoDescriptorProvider //파일에서 로딩된 registry 등 우선 조회
-	fallback ProtoDescriptorProvider //global registry 등 대체
-} //primary -> fallback 순으로 제공
-
-func (c compositeProvider) FindMessageByName(fn protoreflect.FullName) (protoreflect.MessageDescriptor, error) {
-	if md, err := c.primary.FindMessageByName(fn); err == nil {
-		return md, nil
-	}
-	return c.fallback.FindMessageByName(fn)
-} //우선 primary에서 탐색 후 실패 시 fallback에서 탐색
-
-type DescriptorRegistry struct {
-	files *protoregistry.Files // 파일 단위 레지스트리(여러 .proto 집합)
-} //파일 descriptor set
-
-func NewDescriptorRegistry() *DescriptorRegistry {
-	return &DescriptorRegistry{files: &protoregistry.Files{}}
-} //파일 registry 생성
-
-func (r *DescriptorRegistry) RegisterFile(fd protoreflect.FileDescriptor) error {
-	return r.files.RegisterFile(fd)
-} //파일 descriptor를 registry에 등록
-
-func (r *DescriptorRegistry) RegisterFileDescriptorSet(fds *descriptorpb.FileDescriptorSet) error {
-	files, err := protodesc.NewFiles(fds) // descriptorpb → protoreflect 변환
-	if err != nil {
-		return err
-	}
-	var regErr error
-	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
-		if err := r.files.RegisterFile(fd); err != nil { //각 파일을 registry에 등록
-			regErr = err
-		}
-		return true
-	})
-	_ = regErr
-	return nil
-} //.protoset/.desc 파일에 있는 FileDescriptorSet 등록
-
-func (r *DescriptorRegistry) FindMessageByName(fullName protoreflect.FullName) (protoreflect.MessageDescriptor, error) {
-	d, err := r.files.FindDescriptorByName(fullName) //이름으로 descriptor 조회
-	if err == nil {
-		if md, ok := d.(protoreflect.MessageDescriptor); ok {
-			return md, nil
-		}
-		return nil, fmt.Errorf("descriptor %s found but not a message", fullName) //메시지가 아닌 경우
-	}
-	return nil, err //조회 실패
-} //등록된 파일에서 full name으로 메시지 descriptor 탐색
-
-func RegisterDescriptorSetFile(path string) error {
-	blob, err := os.ReadFile(path) //파일 전체 읽기
-	if err != nil {
-		return err //파일 접근 실패
-	}
-	var fds descriptorpb.FileDescriptorSet              //파일 내용은 FileDescriptorSet 바이너리
-	if err := proto.Unmarshal(blob, &fds); err != nil { //protobuf 바이너리 → fds
-		return fmt.Errorf("descriptor set unmarshal: %w", err) //parsing 실패
-	}
-	return DefaultDescriptorRegistry.RegisterFileDescriptorSet(&fds) //registry에 등록
-} //.protoset/.desc 파일 읽어 DefaultDescriptorRegistry에 등록
-
-func init() {
-	paths := os.Getenv("PROTO_DESC_FILES") //경로 목록
-	if paths == "" {
-		return //환경변수 미설정 시
-	}
-	for _, p := range strings.Split(paths, string(os.PathListSeparator)) { //경로 목록 순회
-		p = strings.TrimSpace(p) //공백 제거
-		if p == "" {
-			continue
-		}
-		matches, _ := filepath.Glob(p)
-		if len(matches) == 0 { //매칭 결과가 없을 시
-			_ = RegisterDescriptorSetFile(p) //glob 없이 단일 경로 시도
-			continue
-		}
-		for _, m := range matches { //매칭된 파일 각각 등록
-			if err := RegisterDescriptorSetFile(m); err != nil {
-				log.Printf("[proto] register desc failed: %s: %v\n", m, err) //실패
-			} else {
-				log.Printf("[proto] registered descriptor: %s\n", m) //성공
-			}
-		}
-	}
-} //환경변수 PROTO_DESC_FILES 읽어 자동으로 descriptor 등록
-
-type protoCodec struct{} //protobuf 바이너리 <-> AbstractMessage 변환
-
-func (protoCodec) providerFrom(opts ParseOptions) ProtoDescriptorProvider {
-	if opts.DescriptorProvider != nil {
-		return opts.DescriptorProvider
-	}
-	return compositeProvider{
-		primary:  DefaultDescriptorRegistry,
-		fallback: globalRegistryProvider{},
-	} //local registry 우선, 실패 시 global registry
-} //DescriptorProvider 우선 사용, 없을 시 DefaultDescriptorRegistry -> global registry 조회
-
-func (pc protoCodec) Parse(data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error) {
-	provider := pc.providerFrom(opts)
-	if opts.ProtoMessageFullName == "" {
-		return nil, fmt.Errorf("protobuf parse requires ProtoMessageFullName")
-	}
-	md, err := provider.FindMessageByName(protoreflect.FullName(opts.ProtoMessageFullName)) //full name으로 메시지 descriptor 조회
-	if err != nil {
-		return nil, fmt.Errorf("descriptor not found for %s: %w", opts.ProtoMessageFullName, err)
-	}
-	msg := dynamicpb.NewMessage(md)
-	if err := proto.Unmarshal(data, msg); err != nil {
-		return nil, fmt.Errorf("protobuf unmarshal: %w", err)
-	}
-	js, err := protojson.MarshalOptions{
-		UseEnumNumbers:  false, //enum을 문자열로 출력, true일 시 숫자 출력
-		EmitUnpopulated: false, //빈 필드 무시
-	}.Marshal(msg)
-	if err != nil {
-		return nil, fmt.Errorf("protojson marshal: %w", err)
-	}
-	return (jsonCodec{}).Parse(js, ParseOptions{Format: FormatJSON, OverrideMsgType: opts.OverrideMsgType}) //jsonCodec으로 parsing하여 AbstractMessage로 정규화
-} //protobuf 바이너리를 AbstractMessage로 변환
-
-func (pc protoCodec) Serialize(am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error) {
-	provider := pc.providerFrom(ParseOptions{
-		DescriptorProvider: opts.DescriptorProvider, //SerializeOptions에서 전달
-	})
-	if opts.ProtoMessageFullName == "" { //대상 protobuf 메시지 타입
-		return nil, fmt.Errorf("protobuf serialize requires ProtoMessageFullName")
-	}
-	md, err := provider.FindMessageByName(protoreflect.FullName(opts.ProtoMessageFullName)) //대상 메시지 descriptor 조회
-	if err != nil {
-		return nil, fmt.Errorf("descriptor not found for %s: %w", opts.ProtoMessageFullName, err)
-	}
-	msg := dynamicpb.NewMessage(md)                                              //descriptor 기반 동적 메시지 생성
-	js, err := (jsonCodec{}).Serialize(am, SerializeOptions{Format: FormatJSON}) //jsonCodec으로 AbstractMessage -> JSON 텍스트로 직렬화
-	if err != nil {
-		return nil, err
-	}
-	uopts := protojson.UnmarshalOptions{} //JSON 텍스트 -> protobuf 동적 메시지
-	if opts.ProtoDiscardUnknown {
-		uopts.DiscardUnknown = true //빈 필드 무시
-	}
-	if err := uopts.Unmarshal(js, msg); err != nil { //JSON → 메시지
-		return nil, fmt.Errorf("protojson unmarshal to message(%s): %w", md.FullName(), err)
-	}
-	return proto.Marshal(msg)
-} //AbstractMessage를 protobuf 바이너리로 변환
-
-
+//.proto, .pb.go, .desc(option) 필요
+package codec
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"codec/abstraction"
+)
+
+type ProtoDescriptorProvider interface {
+	FindMessageByName(fullName protoreflect.FullName) (protoreflect.MessageDescriptor, error)
+} //full name으로 메시지 descriptor 탐색
+
+type ContextDescriptorProvider interface {
+	FindMessageByNameContext(ctx context.Context, fullName protoreflect.FullName) (protoreflect.MessageDescriptor, error)
+} //취소/기한을 받는 descriptor 조회(원격 schema registry 등), ProtoDescriptorProvider와 함께 구현
+
+func findMessageDescriptor(ctx context.Context, p ProtoDescriptorProvider, fn protoreflect.FullName) (protoreflect.MessageDescriptor, error) {
+	if cp, ok := p.(ContextDescriptorProvider); ok {
+		return cp.FindMessageByNameContext(ctx, fn)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return p.FindMessageByName(fn)
+} //ContextDescriptorProvider일 시 ctx를 전달, 아닐 시 조회 전에 취소 확인
+
+type globalRegistryProvider struct{} //global registry 조회
+
+func (globalRegistryProvider) FindMessageByName(fn protoreflect.FullName) (protoreflect.MessageDescriptor, error) {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(fn)
+	if err != nil {
+		return nil, err
+	}
+	return mt.Descriptor(), nil
+} //global registry에서 메시지 타입 찾고 descriptor 반환
+
+var DefaultDescriptorRegistry = NewDescriptorRegistry() //파일 registry 인스턴스
+
+type compositeProvider struct {
+	primary  ProtoDescriptorProvider //파일에서 로딩된 registry 등 우선 조회
+	fallback ProtoDescriptorProvider //global registry 등 대체
+} //primary -> fallback 순으로 제공
+
+func (c compositeProvider) FindMessageByName(fn protoreflect.FullName) (protoreflect.MessageDescriptor, error) {
+	return c.FindMessageByNameContext(context.Background(), fn)
+} //우선 primary에서 탐색 후 실패 시 fallback에서 탐색
+
+func (c compositeProvider) FindMessageByNameContext(ctx context.Context, fn protoreflect.FullName) (protoreflect.MessageDescriptor, error) {
+	md, err := findMessageDescriptor(ctx, c.primary, fn)
+	if err == nil {
+		return md, nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil { //취소 시 fallback 조회 안 함
+		return nil, ctxErr
+	}
+	return findMessageDescriptor(ctx, c.fallback, fn)
+} //primary/fallback 조회에 ctx 전달
+
+type DescriptorRegistry struct {
+	files *protoregistry.Files // 파일 단위 레지스트리(여러 .proto 집합)
+} //파일 descriptor set
+
+func NewDescriptorRegistry() *DescriptorRegistry {
+	return &DescriptorRegistry{files: &protoregistry.Files{}}
+} //파일 registry 생성
+
+func (r *DescriptorRegistry) RegisterFile(fd protoreflect.FileDescriptor) error {
+	return r.files.RegisterFile(fd)
+} //파일 descriptor를 registry에 등록
+
+func (r *DescriptorRegistry) RegisterFileDescriptorSet(fds *descriptorpb.FileDescriptorSet) error {
+	files, err := protodesc.NewFiles(fds) // descriptorpb → protoreflect 변환
+	if err != nil {
+		return err
+	}
+	var regErr error
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		if err := r.files.RegisterFile(fd); err != nil { //각 파일을 registry에 등록
+			regErr = err
+		}
+		return true
+	})
+	_ = regErr
+	return nil
+} //.protoset/.desc 파일에 있는 FileDescriptorSet 등록
+
+func (r *DescriptorRegistry) FindMessageByName(fullName protoreflect.FullName) (protoreflect.MessageDescriptor, error) {
+	d, err := r.files.FindDescriptorByName(fullName) //이름으로 descriptor 조회
+	if err == nil {
+		if md, ok := d.(protoreflect.MessageDescriptor); ok {
+			return md, nil
+		}
+		return nil, fmt.Errorf("descriptor %s found but not a message", fullName) //메시지가 아닌 경우
+	}
+	return nil, err //조회 실패
+} //등록된 파일에서 full name으로 메시지 descriptor 탐색
+
+func RegisterDescriptorSetFile(path string) error {
+	blob, err := os.ReadFile(path) //파일 전체 읽기
+	if err != nil {
+		return err //파일 접근 실패
+	}
+	var fds descriptorpb.FileDescriptorSet              //파일 내용은 FileDescriptorSet 바이너리
+	if err := proto.Unmarshal(blob, &fds); err != nil { //protobuf 바이너리 → fds
+		return fmt.Errorf("descriptor set unmarshal: %w", err) //parsing 실패
+	}
+	return DefaultDescriptorRegistry.RegisterFileDescriptorSet(&fds) //registry에 등록
+} //.protoset/.desc 파일 읽어 DefaultDescriptorRegistry에 등록
+
+func init() {
+	paths := os.Getenv("PROTO_DESC_FILES") //경로 목록
+	if paths == "" {
+		return //환경변수 미설정 시
+	}
+	for _, p := range strings.Split(paths, string(os.PathListSeparator)) { //경로 목록 순회
+		p = strings.TrimSpace(p) //공백 제거
+		if p == "" {
+			continue
+		}
+		matches, _ := filepath.Glob(p)
+		if len(matches) == 0 { //매칭 결과가 없을 시
+			_ = RegisterDescriptorSetFile(p) //glob 없이 단일 경로 시도
+			continue
+		}
+		for _, m := range matches { //매칭된 파일 각각 등록
+			if err := RegisterDescriptorSetFile(m); err != nil {
+				log.Printf("[proto] register desc failed: %s: %v\n", m, err) //실패
+			} else {
+				log.Printf("[proto] registered descriptor: %s\n", m) //성공
+			}
+		}
+	}
+} //환경변수 PROTO_DESC_FILES 읽어 자동으로 descriptor 등록
+
+type protoCodec struct{} //protobuf 바이너리 <-> AbstractMessage 변환
+
+func (protoCodec) providerFrom(opts ParseOptions) ProtoDescriptorProvider {
+	if opts.DescriptorProvider != nil {
+		return opts.DescriptorProvider
+	}
+	return compositeProvider{
+		primary:  DefaultDescriptorRegistry,
+		fallback: globalRegistryProvider{},
+	} //local registry 우선, 실패 시 global registry
+} //DescriptorProvider 우선 사용, 없을 시 DefaultDescriptorRegistry -> global registry 조회
+
+func (pc protoCodec) Parse(data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error) {
+	return pc.ParseContext(context.Background(), data, opts)
+} //protobuf 바이너리를 AbstractMessage로 변환
+
+func (pc protoCodec) ParseContext(ctx context.Context, data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error) {
+	provider := pc.providerFrom(opts)
+	if opts.ProtoMessageFullName == "" {
+		return nil, &ParseError{Format: FormatProtobuf, Offset: -1, Err: fmt.Errorf("%w: ProtoMessageFullName is required", ErrDescriptorNotFound)}
+	}
+	md, err := findMessageDescriptor(ctx, provider, protoreflect.FullName(opts.ProtoMessageFullName)) //full name으로 메시지 descriptor 조회
+	if ctxErr := ctx.Err(); ctxErr != nil { //조회 중 취소/기한 초과
+		return nil, &ParseError{Format: FormatProtobuf, Offset: -1, Err: ctxErr}
+	}
+	if err != nil {
+		return nil, &ParseError{Format: FormatProtobuf, Offset: -1, Err: fmt.Errorf("%w: %s: %w", ErrDescriptorNotFound, opts.ProtoMessageFullName, err)}
+	}
+	lv := protoLevel{depth: 1, max: opts.Limits.MaxDepth}
+	opts.Format = FormatProtobuf
+	f, err := readProtoFields(data, md, lv) //dynamicpb 메시지와 map을 거치지 않고 필드 값에서 바로 정규화
+	var le *LimitError
+	if errors.As(err, &le) {
+		return nil, limitError(FormatProtobuf, data, -1, err)
+	}
+	var am *abstraction.AbstractMessage
+	if err == nil {
+		am, err = protoMessageFromFields(f, md, opts)
+		f.release()
+	} else { //잘못된 입력, 병합 등은 dynamicpb로 parsing(에러 보고 포함)
+		if err := protoWireCheck(data, md, lv); err != nil { //직접 decoding이 중간에 멈췄을 수 있어 깊이를 다시 확인
+			if errors.As(err, &le) {
+				return nil, limitError(FormatProtobuf, data, -1, err)
+			}
+			return nil, newParseError(FormatProtobuf, data, -1, "", err)
+		}
+		msg := dynamicpb.NewMessage(md)
+		if err := (proto.UnmarshalOptions{}).Unmarshal(data, msg); err != nil {
+			off, cause := protoWireError(data, err)
+			if isTruncation(cause) {
+				cause = truncated(err)
+			}
+			return nil, newParseError(FormatProtobuf, data, off, "", cause)
+		}
+		am, err = protoMessageFromMap(protoToMap(msg), md, opts)
+	}
+	if err != nil {
+		return nil, decodeError(FormatProtobuf, data, err)
+	}
+	return am, nil
+} //descriptor 조회에 ctx를 전달하는 Parse
+
+func protoMessageFromFields(f *protoFields, md protoreflect.MessageDescriptor, opts ParseOptions) (*abstraction.AbstractMessage, error) {
+	extras := protoExtrasField(md)
+	packed := f.unpackExtras(extras)
+	f.source(extras, opts)
+	am, err := messageFromSource(f, opts)
+	if err != nil {
+		return nil, err
+	}
+	return mergeProtoExtras(am, packed), nil
+} //직접 읽은 필드 값을 AbstractMessage로 정규화
+
+func protoMessageFromMap(m map[string]interface{}, md protoreflect.MessageDescriptor, opts ParseOptions) (*abstraction.AbstractMessage, error) {
+	packed := unpackProtoExtras(m, md)
+	am, err := messageFromMap(m, opts) //bytes/int64 타입을 유지한 채 정규화
+	if err != nil {
+		return nil, err
+	}
+	return mergeProtoExtras(am, packed), nil
+} //protoToMap 결과를 AbstractMessage로 정규화
+
+func mergeProtoExtras(am *abstraction.AbstractMessage, packed map[string]abstraction.ExtraValue) *abstraction.AbstractMessage {
+	if len(packed) > 0 {
+		for k, e := range am.Extras { //같은 이름의 최상위 필드 우선(큰 packed map에 합쳐 재할당을 줄임)
+			packed[k] = e
+		}
+		am.Extras = packed
+	}
+	return am
+} //extras map 필드에서 복원한 값을 Extras에 합침
+
+func (pc protoCodec) Serialize(am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error) {
+	return pc.SerializeContext(context.Background(), am, opts)
+} //AbstractMessage를 protobuf 바이너리로 변환
+
+func (pc protoCodec) SerializeContext(ctx context.Context, am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error) {
+	provider := pc.providerFrom(ParseOptions{
+		DescriptorProvider: opts.DescriptorProvider, //SerializeOptions에서 전달
+	})
+	if opts.ProtoMessageFullName == "" { //대상 protobuf 메시지 타입
+		return nil, fmt.Errorf("%w: ProtoMessageFullName is required", ErrDescriptorNotFound)
+	}
+	md, err := findMessageDescriptor(ctx, provider, protoreflect.FullName(opts.ProtoMessageFullName)) //대상 메시지 descriptor 조회
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrDescriptorNotFound, opts.ProtoMessageFullName, err)
+	}
+	obj, err := protoObject(am, md, opts) //JSON과 같은 key-value 구조
+	if err != nil {
+		return nil, err
+	}
+	msg := dynamicpb.NewMessage(md)                                             //descriptor 기반 동적 메시지 생성
+	if err := setProtoMessage(msg, obj, opts.ProtoDiscardUnknown); err != nil { //직접 설정할 수 없는 값이 있을 시 JSON 텍스트 경유
+		msg = dynamicpb.NewMessage(md)
+		if err := protoFromJSON(msg, obj, opts.ProtoDiscardUnknown); err != nil {
+			return nil, err
+		}
+	}
+	if unknown := protoUnknown(am, md, opts); len(unknown) > 0 { //원본의 스키마에 없는 필드 bytes 보존
+		msg.SetUnknown(unknown)
+	}
+	return proto.Marshal(msg)
+} //descriptor 조회에 ctx를 전달하는 Serialize
+
+func protoObject(am *abstraction.AbstractMessage, md protoreflect.MessageDescriptor, opts SerializeOptions) (map[string]interface{}, error) {
+	obj, err := messageToMap(am, jsonOptions(opts), extraInterface)
+	if err != nil {
+		return nil, err
+	}
+	if err := schemaFieldKeys(obj, am, md, opts); err != nil { //스키마에 없는 원본/어휘 key는 표준 key로
+		return nil, err
+	}
+	if _, err := fitProtoFields(obj, md, ""); err != nil { //정수 범위 검사, hex 해시 등을 bytes 필드의 base64로
+		return nil, err
+	}
+	packProtoExtras(obj, am, md) //스키마에 없는 Extras는 extras map 필드로
+	return obj, nil
+} //AbstractMessage를 스키마 md에 맞춘 protojson 형태의 key-value 구조로
+
+func protoFromJSON(msg *dynamicpb.Message, obj map[string]interface{}, discardUnknown bool) error {
+	js, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	uopts := protojson.UnmarshalOptions{} //JSON 텍스트 -> protobuf 동적 메시지
+	if discardUnknown {
+		uopts.DiscardUnknown = true //빈 필드 무시
+	}
+	if err := uopts.Unmarshal(js, msg); err != nil { //JSON → 메시지
+		return fmt.Errorf("protojson unmarshal to message(%s): %w", msg.Descriptor().FullName(), err)
+	}
+	return nil
+} //key-value 구조를 JSON 텍스트로 만들어 protojson으로 설정(setProtoMessage가 처리하지 못하는 값의 경로)
+
+func schemaFieldKeys(obj map[string]interface{}, am *abstraction.AbstractMessage, md protoreflect.MessageDescriptor, opts SerializeOptions) error {
+	n, err := newNamer(am, jsonOptions(opts))
+	if err != nil {
+		return err
+	}
+	has := func(k string) bool {
+		fields := md.Fields()
+		return fields.ByName(protoreflect.Name(k)) != nil || fields.ByJSONName(k) != nil
+	}
+	for field, canon := range canonicalFieldKeys {
+		if p := n.path(field); p != nil { //Vocabulary의 중첩 경로는 최상위 key로 확인
+			if v, ok := obj[p[0]]; ok && !has(p[0]) && has(canon) {
+				if v, ok = takePath(obj, p); ok {
+					obj[canon] = v
+				}
+			}
+			continue
+		}
+		k := n.key(field)
+		v, ok := obj[k]
+		if !ok || k == canon || has(k) || !has(canon) {
+			continue
+		}
+		delete(obj, k)
+		obj[canon] = v
+	}
+	return nil
+} //PreserveOriginalNames/Vocabulary로 정한 key가 스키마에 없고 표준 key는 있을 시 표준 key로(필드명은 스키마가 결정)
+
+func takePath(obj map[string]interface{}, p []string) (interface{}, bool) {
+	v, ok := obj[p[0]]
+	if !ok || len(p) == 1 {
+		delete(obj, p[0])
+		return v, ok
+	}
+	child, isMap := v.(map[string]interface{})
+	if !isMap {
+		return nil, false
+	}
+	if v, ok = takePath(child, p[1:]); ok && len(child) == 0 {
+		delete(obj, p[0]) //비게 된 상위 객체
+	}
+	return v, ok
+} //중첩 경로 p의 값을 꺼내 삭제(비게 된 상위 객체도 삭제)
+
+func protoWireError(data []byte, err error) (int64, error) {
+	var off int64
+	for len(data) > 0 {
+		num, typ, tn := protowire.ConsumeTag(data) //tag(field number + wire type)
+		if tn < 0 {
+			return off, protowire.ParseError(tn)
+		}
+		vn := protowire.ConsumeFieldValue(num, typ, data[tn:]) //값 길이
+		if vn < 0 {
+			return off + int64(tn), protowire.ParseError(vn)
+		}
+		off += int64(tn + vn)
+		data = data[tn+vn:]
+	}
+	return -1, err //wire 구조는 정상, 의미 단위 오류
+} //protobuf wire 구조를 최상위 필드 단위로 훑어 실패 위치와 원인 반환
+
+func protoToMap(m protoreflect.Message) map[string]interface{} {
+	out := map[string]interface{}{}
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool { //값이 설정된 필드만
+		out[string(fd.Name())] = protoFieldValue(fd, v)
+		return true
+	})
+	return out
+} //protobuf 메시지를 proto 필드명 key의 map으로 변환(bytes는 []byte, 정수는 int64/uint64 유지)
+
+func protoFieldValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch {
+	case fd.IsList():
+		l := v.List()
+		out := make([]interface{}, 0, l.Len())
+		for i := 0; i < l.Len(); i++ {
+			out = append(out, protoScalarValue(fd, l.Get(i)))
+		}
+		return out
+	case fd.IsMap():
+		out := map[string]interface{}{}
+		v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+			out[k.String()] = protoScalarValue(fd.MapValue(), mv)
+			return true
+		})
+		return out
+	}
+	return protoScalarValue(fd, v)
+} //repeated/map/단일 필드 값 변환
+
+func protoScalarValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if fd.Message().FullName() == "google.protobuf.Timestamp" { //well-known Timestamp
+			f := fd.Message().Fields()
+			sec := v.Message().Get(f.ByName("seconds")).Int()
+			nanos := v.Message().Get(f.ByName("nanos")).Int()
+			return time.Unix(sec, nanos).UTC()
+		}
+		return protoToMap(v.Message())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil { //enum은 이름으로
+			return string(ev.Name())
+		}
+		return int64(v.Enum())
+	case protoreflect.BytesKind:
+		return append([]byte(nil), v.Bytes()...)
+	}
+	return v.Interface() //string, bool, int32/int64, uint32/uint64, float/double
+} //단일 원소 값 변환
+
+func protoUnknown(am *abstraction.AbstractMessage, md protoreflect.MessageDescriptor, opts SerializeOptions) protoreflect.RawFields {
+	if opts.ForceReencode || am.OriginalFormat != string(FormatProtobuf) || am.OriginalSchema != string(md.FullName()) || len(am.RawPayload) == 0 {
+		return nil
+	}
+	orig := dynamicpb.NewMessage(md)
+	if protoWireCheck(am.RawPayload, md, protoLevel{depth: 1}) != nil || proto.Unmarshal(am.RawPayload, orig) != nil {
+		return nil
+	}
+	return orig.GetUnknown()
+} //같은 스키마로 parsing한 원본의 unknown 필드 bytes
+
+func protoExtrasField(md protoreflect.MessageDescriptor) protoreflect.FieldDescriptor {
+	fd := md.Fields().ByName("extras")
+	if fd == nil || !fd.IsMap() || fd.MapKey().Kind() != protoreflect.StringKind {
+		return nil
+	}
+	if k := fd.MapValue().Kind(); k != protoreflect.BytesKind && k != protoreflect.StringKind {
+		return nil
+	}
+	return fd
+} //스키마의 map<string, bytes|string> extras 필드(없을 시 nil)
+
+func packProtoExtras(obj map[string]interface{}, am *abstraction.AbstractMessage, md protoreflect.MessageDescriptor) {
+	fd := protoExtrasField(md)
+	if fd == nil {
+		return
+	}
+	packed, _ := obj[string(fd.Name())].(map[string]interface{})
+	for _, k := range sortedMapKeys(am.Extras) {
+		e := am.Extras[k]
+		if md.Fields().ByName(protoreflect.Name(k)) != nil || md.Fields().ByJSONName(k) != nil {
+			continue //스키마 필드와 같은 이름은 해당 필드로
+		}
+		if v, ok := obj[k]; !ok || !reflect.DeepEqual(v, extraInterface(e)) {
+			continue //표준 필드에 밀린 key
+		}
+		b, _ := e.MarshalJSON() //값 타입을 JSON 표기로 보존
+		if packed == nil {
+			packed = map[string]interface{}{}
+		}
+		if fd.MapValue().Kind() == protoreflect.BytesKind {
+			packed[k] = base64.StdEncoding.EncodeToString(b)
+		} else {
+			packed[k] = string(b)
+		}
+		delete(obj, k)
+	}
+	if packed != nil {
+		obj[string(fd.Name())] = packed
+	}
+} //스키마에 필드가 없는 Extras를 JSON 표기로 extras map 필드에 담음
+
+func unpackProtoExtras(m map[string]interface{}, md protoreflect.MessageDescriptor) map[string]abstraction.ExtraValue {
+	fd := protoExtrasField(md)
+	if fd == nil {
+		return nil
+	}
+	packed, ok := m[string(fd.Name())].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	delete(m, string(fd.Name()))
+	out := make(map[string]abstraction.ExtraValue, len(packed))
+	for k, v := range packed {
+		var b []byte
+		switch t := v.(type) {
+		case []byte:
+			b = t
+		case string:
+			b = []byte(t)
+		}
+		if e, ok := protoPackedExtra(nil, b); ok {
+			out[k] = e
+		} else {
+			out[k] = extraValue(v, FormatProtobuf) //JSON이 아닌 값은 bytes/string 그대로
+		}
+	}
+	return out
+} //extras map 필드의 값을 타입 있는 Extras로 복원
+
+func protoPackedExtra(blk *extraBlock, b []byte) (abstraction.ExtraValue, bool) {
+	if e, ok := jsonExtraIn(blk, b, FormatProtobuf); ok { //MarshalJSON 출력은 decoder 없이
+		return e, true
+	}
+	var decoded interface{}
+	if err := unmarshalJSON(b, &decoded); err == nil { //packProtoExtras가 쓴 JSON 표기
+		return extraValue(decoded, FormatProtobuf), true
+	}
+	return abstraction.ExtraValue{}, false
+} //packProtoExtras가 JSON 표기로 담은 값
+
+func fitProtoFields(obj map[string]interface{}, md protoreflect.MessageDescriptor, prefix string) (bool, error) {
+	changed := false
+	for _, k := range sortedMapKeys(obj) { //에러 보고 순서 고정
+		v := obj[k]
+		fd := md.Fields().ByName(protoreflect.Name(k))
+		if fd == nil {
+			fd = md.Fields().ByJSONName(k)
+		}
+		if fd == nil || fd.IsMap() {
+			continue
+		}
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if ms, ok := v.([]map[string]interface{}); ok { //messageToMap의 view_changes
+			l := make([]interface{}, len(ms))
+			for i, m := range ms {
+				l[i] = m
+			}
+			v, obj[k] = l, l
+		}
+		items, isList := v.([]interface{})
+		if !isList || !fd.IsList() {
+			items = []interface{}{v}
+		}
+		for i, e := range items {
+			p := path
+			if isList && fd.IsList() {
+				p = path + "[" + strconv.Itoa(i) + "]"
+			}
+			switch fd.Kind() {
+			case protoreflect.BytesKind:
+				if b, ok := hexBytes(e); ok {
+					items[i] = base64.StdEncoding.EncodeToString(b)
+					changed = true
+				}
+			case protoreflect.MessageKind:
+				if fd.Message().FullName() == "google.protobuf.Timestamp" { //protojson은 RFC3339 문자열만 받음
+					if _, isText := e.(string); !isText && e != nil {
+						if tm := (coercer{}).time(e); !tm.IsZero() {
+							items[i] = tm.Format(time.RFC3339Nano)
+							changed = true
+						}
+					}
+					continue
+				}
+				if sub, ok := e.(map[string]interface{}); ok {
+					c, err := fitProtoFields(sub, fd.Message(), p)
+					if err != nil {
+						return false, err
+					}
+					changed = changed || c
+				}
+			default:
+				if err := checkProtoInt(fd.Kind(), e, p); err != nil {
+					return false, err
+				}
+			}
+		}
+		if !isList || !fd.IsList() {
+			obj[k] = items[0]
+		}
+	}
+	return changed, nil
+} //JSON 객체를 descriptor와 함께 순회(중첩 메시지/repeated 포함)
+
+var protoIntRanges = map[protoreflect.Kind]struct {
+	name     string
+	min, max *big.Int
+}{
+	protoreflect.Int32Kind:    {"int32", big.NewInt(math.MinInt32), big.NewInt(math.MaxInt32)},
+	protoreflect.Sint32Kind:   {"sint32", big.NewInt(math.MinInt32), big.NewInt(math.MaxInt32)},
+	protoreflect.Sfixed32Kind: {"sfixed32", big.NewInt(math.MinInt32), big.NewInt(math.MaxInt32)},
+	protoreflect.Int64Kind:    {"int64", big.NewInt(math.MinInt64), big.NewInt(math.MaxInt64)},
+	protoreflect.Sint64Kind:   {"sint64", big.NewInt(math.MinInt64), big.NewInt(math.MaxInt64)},
+	protoreflect.Sfixed64Kind: {"sfixed64", big.NewInt(math.MinInt64), big.NewInt(math.MaxInt64)},
+	protoreflect.Uint32Kind:   {"uint32", new(big.Int), big.NewInt(math.MaxUint32)},
+	protoreflect.Fixed32Kind:  {"fixed32", new(big.Int), big.NewInt(math.MaxUint32)},
+	protoreflect.Uint64Kind:   {"uint64", new(big.Int), new(big.Int).SetUint64(math.MaxUint64)},
+	protoreflect.Fixed64Kind:  {"fixed64", new(big.Int), new(big.Int).SetUint64(math.MaxUint64)},
+} //protobuf 정수 타입별 범위
+
+func checkProtoInt(kind protoreflect.Kind, v interface{}, path string) error {
+	r, ok := protoIntRanges[kind]
+	if !ok {
+		return nil
+	}
+	x := coercer{}.bigInt(v) //"123", json.Number 등
+	if x == nil {
+		return nil //정수가 아닌 값은 protojson이 보고
+	}
+	if x.Cmp(r.min) < 0 || x.Cmp(r.max) > 0 {
+		return &OverflowError{Format: FormatProtobuf, Field: path, Type: r.name, Value: x}
+	}
+	return nil
+} //값이 protobuf 정수 필드 범위 안인지 확인
+
+func hexBytes(v interface{}) ([]byte, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, false
+	}
+	h, ok := cutHexPrefix(s)
+	if !ok {
+		return nil, false
+	}
+	b, err := hex.DecodeString(h)
+	return b, err == nil
+} //"0x.." 문자열을 바이트로 변환
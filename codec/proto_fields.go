@@ -0,0 +1,979 @@
+package codec
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"codec/abstraction"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var errProtoFallback = errors.New("value needs protojson") //직접 설정할 수 없는 값(protojson 경로에서 처리/에러 보고)
+
+func setProtoMessage(msg protoreflect.Message, obj map[string]interface{}, discardUnknown bool) error {
+	md := msg.Descriptor()
+	for k, v := range obj {
+		fd := md.Fields().ByName(protoreflect.Name(k))
+		if fd == nil {
+			fd = md.Fields().ByJSONName(k)
+		}
+		if fd == nil {
+			if discardUnknown {
+				continue
+			}
+			return errProtoFallback //unknown field 에러
+		}
+		if v == nil { //null은 미설정
+			continue
+		}
+		if msg.Has(fd) || (fd.ContainingOneof() != nil && msg.WhichOneof(fd.ContainingOneof()) != nil) {
+			return errProtoFallback //이름/JSON 이름 중복, oneof 중복
+		}
+		var err error
+		switch {
+		case fd.IsList():
+			err = setProtoList(msg.Mutable(fd).List(), fd, v, discardUnknown)
+		case fd.IsMap():
+			err = setProtoMap(msg.Mutable(fd).Map(), fd, v, discardUnknown)
+		default:
+			var pv protoreflect.Value
+			pv, err = protoValue(msg.NewField(fd), fd, v, discardUnknown)
+			if err == nil {
+				msg.Set(fd, pv)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+} //messageToMap 결과를 JSON 텍스트 없이 동적 메시지에 설정(protojson과 같은 결과가 확실하지 않을 시 errProtoFallback)
+
+func setProtoList(l protoreflect.List, fd protoreflect.FieldDescriptor, v interface{}, discardUnknown bool) error {
+	add := func(e interface{}) error {
+		if e == nil {
+			return errProtoFallback
+		}
+		pv, err := protoValue(l.NewElement(), fd, e, discardUnknown)
+		if err != nil {
+			return err
+		}
+		l.Append(pv)
+		return nil
+	}
+	switch t := v.(type) {
+	case []interface{}:
+		for _, e := range t {
+			if err := add(e); err != nil {
+				return err
+			}
+		}
+	case []string: //CommitSeals
+		for _, e := range t {
+			if err := add(e); err != nil {
+				return err
+			}
+		}
+	case []map[string]interface{}: //ViewChanges
+		for _, e := range t {
+			if err := add(e); err != nil {
+				return err
+			}
+		}
+	default:
+		return errProtoFallback
+	}
+	return nil
+} //repeated 필드 설정
+
+func setProtoMap(m protoreflect.Map, fd protoreflect.FieldDescriptor, v interface{}, discardUnknown bool) error {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return errProtoFallback
+	}
+	for k, e := range obj {
+		if e == nil {
+			return errProtoFallback
+		}
+		key, err := protoValue(protoreflect.Value{}, fd.MapKey(), k, discardUnknown)
+		if err != nil {
+			return err
+		}
+		val, err := protoValue(m.NewValue(), fd.MapValue(), e, discardUnknown)
+		if err != nil {
+			return err
+		}
+		m.Set(key.MapKey(), val)
+	}
+	return nil
+} //map 필드 설정(key는 JSON 객체 key 문자열)
+
+func protoValue(field protoreflect.Value, fd protoreflect.FieldDescriptor, v interface{}, discardUnknown bool) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		switch t := v.(type) {
+		case bool:
+			return protoreflect.ValueOfBool(t), nil
+		case string: //map key
+			if b, err := strconv.ParseBool(t); err == nil && fd.ContainingMessage().IsMapEntry() {
+				return protoreflect.ValueOfBool(b), nil
+			}
+		}
+	case protoreflect.StringKind:
+		if s, ok := v.(string); ok && utf8.ValidString(s) { //잘못된 UTF-8은 json.Marshal이 치환
+			return protoreflect.ValueOfString(s), nil
+		}
+	case protoreflect.BytesKind:
+		switch t := v.(type) {
+		case []byte:
+			return protoreflect.ValueOfBytes(t), nil
+		case string:
+			if b, err := base64.StdEncoding.DecodeString(t); err == nil {
+				return protoreflect.ValueOfBytes(b), nil
+			}
+		}
+	case protoreflect.EnumKind:
+		switch t := v.(type) {
+		case string:
+			if ev := fd.Enum().Values().ByName(protoreflect.Name(t)); ev != nil {
+				return protoreflect.ValueOfEnum(ev.Number()), nil
+			}
+		case json.Number:
+			if n, err := strconv.ParseInt(t.String(), 10, 32); err == nil && fd.Enum().Values().ByNumber(protoreflect.EnumNumber(n)) != nil {
+				return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+			}
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		if n, ok := protoInt(v, 32); ok {
+			return protoreflect.ValueOfInt32(int32(n)), nil
+		}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		if n, ok := protoInt(v, 64); ok {
+			return protoreflect.ValueOfInt64(n), nil
+		}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		if n, ok := protoUint(v, 32); ok {
+			return protoreflect.ValueOfUint32(uint32(n)), nil
+		}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		if n, ok := protoUint(v, 64); ok {
+			return protoreflect.ValueOfUint64(n), nil
+		}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		var s string
+		switch t := v.(type) {
+		case json.Number:
+			s = t.String()
+		case float64:
+			if fd.Kind() == protoreflect.DoubleKind {
+				return protoreflect.ValueOfFloat64(t), nil
+			}
+		}
+		if s != "" {
+			bits := 64
+			if fd.Kind() == protoreflect.FloatKind {
+				bits = 32
+			}
+			if f, err := strconv.ParseFloat(s, bits); err == nil && !math.IsInf(f, 0) {
+				if bits == 32 {
+					return protoreflect.ValueOfFloat32(float32(f)), nil
+				}
+				return protoreflect.ValueOfFloat64(f), nil
+			}
+		}
+	case protoreflect.MessageKind:
+		md := fd.Message()
+		if md.FullName() == "google.protobuf.Timestamp" {
+			s, ok := v.(string)
+			if !ok {
+				break
+			}
+			tm, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil || tm.Year() < 1 || tm.Year() > 9999 {
+				break
+			}
+			m := field.Message()
+			m.Set(md.Fields().ByName("seconds"), protoreflect.ValueOfInt64(tm.Unix()))
+			m.Set(md.Fields().ByName("nanos"), protoreflect.ValueOfInt32(int32(tm.Nanosecond())))
+			return field, nil
+		}
+		if md.FullName().Parent() == "google.protobuf" { //그 외 well-known type은 protojson 표기 규칙이 다름
+			break
+		}
+		if obj, ok := v.(map[string]interface{}); ok {
+			if err := setProtoMessage(field.Message(), obj, discardUnknown); err != nil {
+				return protoreflect.Value{}, err
+			}
+			return field, nil
+		}
+	}
+	return protoreflect.Value{}, errProtoFallback
+} //단일 값을 필드 타입의 protoreflect.Value로 변환
+
+func protoInt(v interface{}, bits int) (int64, bool) {
+	var s string
+	switch t := v.(type) {
+	case string:
+		s = t
+	case json.Number:
+		s = t.String()
+	case int64:
+		s = strconv.FormatInt(t, 10)
+	default:
+		return 0, false
+	}
+	n, err := strconv.ParseInt(s, 10, bits)
+	return n, err == nil && strconv.FormatInt(n, 10) == s //"+1", "01" 등 JSON 수가 아닌 표기 제외
+} //10진수 정수 표기를 bits 범위의 정수로 변환
+
+func protoUint(v interface{}, bits int) (uint64, bool) {
+	var s string
+	switch t := v.(type) {
+	case string:
+		s = t
+	case json.Number:
+		s = t.String()
+	case uint64:
+		s = strconv.FormatUint(t, 10)
+	default:
+		return 0, false
+	}
+	n, err := strconv.ParseUint(s, 10, bits)
+	return n, err == nil && strconv.FormatUint(n, 10) == s
+} //10진수 정수 표기를 bits 범위의 부호 없는 정수로 변환
+
+const protoDirectDepth = 64 //직접 decoding할 최대 중첩 깊이(넘을 시 dynamicpb 경로)
+
+type protoLevel struct {
+	depth int //현재 메시지의 중첩 깊이(최상위 메시지가 1)
+	max   int //Limits.MaxDepth(0일 시 무제한)
+} //중첩 메시지 깊이 추적
+
+func (lv protoLevel) nested() protoLevel {
+	return protoLevel{depth: lv.depth + 1, max: lv.max}
+} //한 단계 안쪽 메시지
+
+func (lv protoLevel) check() error {
+	if lv.max > 0 && lv.depth > lv.max {
+		return &LimitError{Limit: "MaxDepth", Max: lv.max, Got: lv.depth}
+	}
+	return nil
+} //MaxDepth 초과 시 LimitError
+
+type protoWire struct {
+	x   uint64       //varint/fixed 값
+	raw []byte       //string/bytes 값(입력을 가리킴)
+	msg *protoFields //메시지 값
+} //Go 값으로 바꾸지 않은 필드 값 하나
+
+type protoEntry struct {
+	fd   protoreflect.FieldDescriptor
+	set  bool        //값 있음(proto3 기본값, 빈 repeated 필드는 미설정)
+	val  protoWire   //단일 필드 값
+	list []protoWire //repeated 값 또는 map 값
+	keys []protoWire //map key(list와 같은 순서)
+} //메시지 필드 하나
+
+type protoFields struct {
+	md      protoreflect.MessageDescriptor
+	entries []protoEntry //처음 나온 순서
+	slot    []int        //필드 index -> entries 위치+1
+	keys    []string     //값이 있는 필드 이름(fieldSource, 최상위 메시지만)
+	at      []int        //keys[i]의 entries 위치
+	dict    map[string]string
+	ints    *intBlock
+} //읽은 메시지 필드(값은 필요할 때 Go 값으로 바꿈)
+
+var protoFieldsPool = sync.Pool{New: func() interface{} { return new(protoFields) }} //중첩 메시지마다 필드 목록을 새로 할당하지 않도록
+
+func readProtoFields(b []byte, md protoreflect.MessageDescriptor, lv protoLevel) (*protoFields, error) {
+	if err := lv.check(); err != nil {
+		return nil, err
+	}
+	if lv.depth > protoDirectDepth || md.RequiredNumbers().Len() > 0 { //required 검사는 dynamicpb 경로에서
+		return nil, errProtoFallback
+	}
+	f := protoFieldsPool.Get().(*protoFields)
+	f.md, f.entries = md, f.entries[:0]
+	if n := md.Fields().Len(); cap(f.slot) < n {
+		f.slot = make([]int, n)
+	} else {
+		f.slot = f.slot[:n]
+		clear(f.slot)
+	}
+	if err := f.read(b, lv); err != nil {
+		f.release()
+		return nil, err
+	}
+	return f, nil
+} //protobuf 바이너리의 필드를 dynamicpb 없이 읽음(protoToMap과 같은 결과가 확실하지 않을 시 errProtoFallback)
+
+func (f *protoFields) read(b []byte, lv protoLevel) error {
+	var oneofs map[protoreflect.Name]protoreflect.FieldNumber //oneof -> 설정된 필드
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 || !num.IsValid() {
+			return errProtoFallback
+		}
+		b = b[n:]
+		fd := f.md.Fields().ByNumber(num)
+		if fd == nil { //unknown 필드는 protoToMap 결과에 나타나지 않음
+			if f.md.ExtensionRanges().Has(num) {
+				return errProtoFallback
+			}
+			if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+				return errProtoFallback
+			}
+			b = b[n:]
+			continue
+		}
+		if od := fd.ContainingOneof(); od != nil && !od.IsSynthetic() {
+			if oneofs == nil {
+				oneofs = map[protoreflect.Name]protoreflect.FieldNumber{}
+			}
+			if prev, ok := oneofs[od.Name()]; ok && prev != num { //다른 oneof 필드가 앞선 값을 지움
+				return errProtoFallback
+			}
+			oneofs[od.Name()] = num
+		}
+		e := f.entry(fd) //중첩 메시지는 다른 protoFields에 읽으므로 e는 이 필드를 다 읽을 때까지 유효
+		switch {
+		case fd.IsMap():
+			entry, n := protowire.ConsumeBytes(b)
+			if typ != protowire.BytesType || n < 0 {
+				return errProtoFallback
+			}
+			b = b[n:]
+			k, v, err := readProtoMapEntry(entry, fd, lv)
+			if err != nil {
+				return err
+			}
+			e.keys, e.list, e.set = append(e.keys, k), append(e.list, v), true
+		case fd.IsList():
+			if typ == protowire.BytesType && protoPackable(fd.Kind()) { //packed
+				packed, n := protowire.ConsumeBytes(b)
+				if n < 0 {
+					return errProtoFallback
+				}
+				b = b[n:]
+				wt := protoWireType(fd.Kind())
+				for len(packed) > 0 {
+					w, _, n, err := readProtoWire(fd, wt, packed, lv)
+					if err != nil {
+						return err
+					}
+					packed = packed[n:]
+					e.list = append(e.list, w)
+				}
+			} else {
+				w, _, n, err := readProtoWire(fd, typ, b, lv)
+				if err != nil {
+					return err
+				}
+				b = b[n:]
+				e.list = append(e.list, w)
+			}
+			e.set = len(e.list) > 0 //빈 repeated 필드는 미설정
+		default:
+			if e.set && fd.Message() != nil { //같은 메시지 필드가 반복될 시 병합
+				return errProtoFallback
+			}
+			w, zero, n, err := readProtoWire(fd, typ, b, lv)
+			if err != nil {
+				return err
+			}
+			b = b[n:]
+			e.val, e.set = w, !zero || fd.HasPresence() //proto3 기본값은 미설정
+		}
+	}
+	return nil
+} //필드를 순서대로 읽음(같은 필드가 반복될 시 protobuf 규칙대로 마지막 값/이어 붙인 목록)
+
+func (f *protoFields) entry(fd protoreflect.FieldDescriptor) *protoEntry {
+	i := fd.Index()
+	if j := f.slot[i]; j > 0 {
+		return &f.entries[j-1]
+	}
+	if n := len(f.entries); n < cap(f.entries) { //이전에 쓴 목록 공간 재사용
+		f.entries = f.entries[:n+1]
+		e := &f.entries[n]
+		*e = protoEntry{fd: fd, list: e.list[:0], keys: e.keys[:0]}
+	} else {
+		f.entries = append(f.entries, protoEntry{fd: fd})
+	}
+	f.slot[i] = len(f.entries)
+	return &f.entries[len(f.entries)-1]
+} //fd의 항목(없을 시 추가)
+
+func (f *protoFields) release() {
+	if f == nil {
+		return
+	}
+	for i := range f.entries {
+		e := &f.entries[i]
+		e.val.msg.release()
+		for _, w := range e.list {
+			w.msg.release()
+		}
+		clear(e.list[:cap(e.list)])
+		clear(e.keys[:cap(e.keys)])
+		e.val = protoWire{}
+	}
+	clear(f.keys)
+	f.md, f.dict, f.ints = nil, nil, nil
+	protoFieldsPool.Put(f)
+} //중첩 메시지와 함께 pool에 반환(입력 참조 해제)
+
+func protoWireToMap(b []byte, md protoreflect.MessageDescriptor, lv protoLevel) (map[string]interface{}, error) {
+	f, err := readProtoFields(b, md, lv)
+	if err != nil {
+		return nil, err
+	}
+	defer f.release()
+	return f.toMap(), nil
+} //protobuf 바이너리를 dynamicpb 없이 protoToMap과 같은 map으로(같은 결과가 확실하지 않을 시 errProtoFallback)
+
+func (f *protoFields) toMap() map[string]interface{} {
+	out := make(map[string]interface{}, len(f.entries))
+	for i := range f.entries {
+		if e := &f.entries[i]; e.set {
+			out[string(e.fd.Name())] = e.value()
+		}
+	}
+	return out
+} //protoToMap과 같은 map
+
+func (e *protoEntry) value() interface{} {
+	switch {
+	case e.fd.IsMap():
+		kfd, vfd := e.fd.MapKey(), e.fd.MapValue()
+		m := make(map[string]interface{}, len(e.list))
+		for i, k := range e.keys { //같은 key는 마지막 값
+			m[protoreflect.ValueOf(k.value(kfd)).MapKey().String()] = e.list[i].value(vfd)
+		}
+		return m
+	case e.fd.IsList():
+		list := make([]interface{}, len(e.list))
+		for i, w := range e.list {
+			list[i] = w.value(e.fd)
+		}
+		return list
+	}
+	return e.val.value(e.fd)
+} //필드 값을 protoFieldValue와 같은 Go 값으로
+
+func protoWireCheck(b []byte, md protoreflect.MessageDescriptor, lv protoLevel) error {
+	if err := lv.check(); err != nil {
+		return err
+	}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil //잘못된 입력은 디코더가 보고
+		}
+		b = b[n:]
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return nil
+		}
+		val := b[:n]
+		b = b[n:]
+		fd := md.Fields().ByNumber(num)
+		if fd == nil || (fd.Message() == nil && !fd.IsMap()) {
+			continue //unknown 필드와 scalar는 중첩 메시지로 읽지 않음
+		}
+		switch {
+		case fd.IsMap():
+			if typ != protowire.BytesType {
+				continue
+			}
+			entry, _ := protowire.ConsumeBytes(val)
+			if err := protoWireCheckEntry(entry, fd, lv); err != nil {
+				return err
+			}
+		case typ == protowire.BytesType:
+			inner, _ := protowire.ConsumeBytes(val)
+			if err := protoWireCheck(inner, fd.Message(), lv.nested()); err != nil {
+				return err
+			}
+		case typ == protowire.StartGroupType:
+			inner, _ := protowire.ConsumeGroup(num, val)
+			if err := protoWireCheck(inner, fd.Message(), lv.nested()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+} //dynamicpb로 parsing하기 전 중첩 메시지 깊이(MaxDepth 초과 시 LimitError)와 dynamicpb가 panic하는 map entry 확인
+
+func protoWireCheckEntry(entry []byte, fd protoreflect.FieldDescriptor, lv protoLevel) error {
+	kfd, vfd := fd.MapKey(), fd.MapValue()
+	hasKey := false
+	for len(entry) > 0 {
+		num, typ, n := protowire.ConsumeTag(entry)
+		if n < 0 {
+			return nil
+		}
+		entry = entry[n:]
+		n = protowire.ConsumeFieldValue(num, typ, entry)
+		if n < 0 {
+			return nil
+		}
+		switch {
+		case num == kfd.Number() && typ == protoWireType(kfd.Kind()):
+			hasKey = true
+		case num == kfd.Number() && hasKey: //dynamicpb가 읽은 key를 지운 뒤 nil key로 panic("type mismatch: cannot convert nil to map key")
+			return fmt.Errorf("map entry of %s repeats its key with wire type %d", fd.FullName(), typ)
+		case num == vfd.Number() && vfd.Message() != nil && typ == protowire.BytesType: //map entry 자체는 깊이에 포함되지 않고 메시지 값만 한 단계 안쪽
+			inner, _ := protowire.ConsumeBytes(entry[:n])
+			if err := protoWireCheck(inner, vfd.Message(), lv.nested()); err != nil {
+				return err
+			}
+		}
+		entry = entry[n:]
+	}
+	return nil
+} //map entry 하나 확인
+
+func (f *protoFields) source(skip protoreflect.FieldDescriptor, opts ParseOptions) {
+	f.keys, f.at = f.keys[:0], f.at[:0]
+	for i := range f.entries {
+		if e := &f.entries[i]; e.set && e.fd != skip {
+			f.keys = append(f.keys, string(e.fd.Name()))
+			f.at = append(f.at, i)
+		}
+	}
+	f.dict, f.ints = opts.synonyms().index().names, nil
+} //최상위 메시지를 fieldSource로 쓰기 위한 key 목록(skip 필드 제외)
+
+func (f *protoFields) names() []string { return f.keys }
+
+func (f *protoFields) field(i int) *protoEntry {
+	return &f.entries[f.at[i]]
+} //i번째 key의 항목
+
+func (f *protoFields) value(i int) interface{} {
+	return f.field(i).value()
+} //i번째 값을 protoToMap과 같은 Go 값으로
+
+func (f *protoFields) text(i int) (string, bool) {
+	e := f.field(i)
+	if e.fd.IsList() || e.fd.IsMap() {
+		return "", false
+	}
+	switch e.fd.Kind() {
+	case protoreflect.StringKind:
+		if k, ok := f.dict[string(e.val.raw)]; ok { //메시지명 등 사전에 있는 값은 할당 없이
+			return k, true
+		}
+		return string(e.val.raw), true
+	case protoreflect.EnumKind:
+		s, ok := e.val.value(e.fd).(string)
+		return s, ok
+	}
+	return "", false
+} //문자열 값(enum은 이름)
+
+func (f *protoFields) empty(i int) bool {
+	e := f.field(i)
+	return !e.fd.IsList() && !e.fd.IsMap() && e.fd.Kind() == protoreflect.StringKind && len(e.val.raw) == 0
+} //빈 문자열(presence가 있는 필드만 빈 값으로 남음)
+
+func (f *protoFields) object(i int) bool {
+	e := f.field(i)
+	return e.fd.IsMap() || !e.fd.IsList() && e.fd.Message() != nil && !isProtoTimestamp(e.fd)
+} //map 값 여부
+
+func (f *protoFields) setField(am *abstraction.AbstractMessage, field string, i int, c coercer) {
+	if e := f.field(i); !f.setDirect(am, field, e, c) {
+		setField(am, field, e.value(), c) //Go 값으로 바꾼 뒤
+	}
+} //i번째 값으로 표준 필드 설정
+
+func (f *protoFields) setDirect(am *abstraction.AbstractMessage, field string, e *protoEntry, c coercer) bool {
+	switch {
+	case e.fd.IsMap():
+		return false
+	case e.fd.IsList():
+		switch field {
+		case "CommitSeals":
+			seals, ok := protoHashes(e, c)
+			am.CommitSeals = seals
+			return ok
+		case "ViewChanges":
+			changes, ok := protoViewChanges(e, c)
+			am.ViewChanges = changes
+			return ok
+		}
+		return false
+	}
+	kind := e.fd.Kind()
+	switch field {
+	case "Height", "Round", "View":
+		var x *big.Int
+		if v, ok := protoWireInt(e.fd, e.val); ok {
+			x = f.topInts().new(v)
+		} else if kind == protoreflect.StringKind {
+			x = c.parseInt(string(e.val.raw))
+		} else {
+			return false
+		}
+		switch field {
+		case "Height":
+			am.Height = x
+		case "Round":
+			am.Round = x
+		default:
+			am.View = x
+		}
+		return true
+	case "Timestamp":
+		if !isProtoTimestamp(e.fd) {
+			break
+		}
+		am.Timestamp = e.val.msg.timestamp()
+		return true
+	}
+	switch kind {
+	case protoreflect.StringKind:
+		setFieldText(am, field, string(e.val.raw), c)
+		return true
+	case protoreflect.BytesKind:
+		switch field {
+		case "BlockHash":
+			am.BlockHash = c.encode(e.val.raw)
+		case "PrevHash":
+			am.PrevHash = c.encode(e.val.raw)
+		case "Proposer":
+			am.Proposer = c.encode(e.val.raw)
+		case "Validator":
+			am.Validator = c.encode(e.val.raw)
+		case "Signature":
+			am.Signature = c.encode(e.val.raw)
+		default:
+			return false
+		}
+		return true
+	}
+	return false
+} //값을 Go 값으로 바꾸지 않고 설정(setField와 같은 결과가 확실하지 않을 시 false)
+
+func (f *protoFields) topInts() *intBlock {
+	if f.ints == nil {
+		f.ints = newTopInts()
+	}
+	return f.ints
+} //최상위 정수 필드용 블록(메시지마다 필요할 때 한 번 할당)
+
+func protoWireInt(fd protoreflect.FieldDescriptor, w protoWire) (int64, bool) {
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sfixed32Kind:
+		return int64(int32(w.x)), true
+	case protoreflect.Sint32Kind:
+		return int64(int32(protowire.DecodeZigZag(w.x & math.MaxUint32))), true
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return int64(uint32(w.x)), true
+	case protoreflect.Int64Kind, protoreflect.Sfixed64Kind:
+		return int64(w.x), true
+	case protoreflect.Sint64Kind:
+		return protowire.DecodeZigZag(w.x), true
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return int64(w.x), w.x <= math.MaxInt64
+	case protoreflect.StringKind:
+		return smallDecimal(string(w.raw)) //임시 문자열 없이
+	}
+	return 0, false
+} //int64 범위의 정수 값
+
+func protoHashes(e *protoEntry, c coercer) ([]string, bool) {
+	kind := e.fd.Kind()
+	if kind != protoreflect.StringKind && kind != protoreflect.BytesKind {
+		return nil, false
+	}
+	n := 0
+	for _, w := range e.list {
+		n += 2 + 2*len(w.raw)
+	}
+	out, t := make([]string, 0, len(e.list)), newTextBlock(n)
+	for _, w := range e.list {
+		if kind == protoreflect.StringKind {
+			out = append(out, c.hashBytes(t, w.raw))
+		} else {
+			out = append(out, c.encodeIn(t, w.raw))
+		}
+	}
+	return out, true
+} //repeated string/bytes 해시 목록(coercer.hashes와 같음)
+
+func protoViewChanges(e *protoEntry, c coercer) ([]abstraction.ViewChangeEntry, bool) {
+	if e.fd.Message() == nil {
+		return nil, false
+	}
+	out := make([]abstraction.ViewChangeEntry, 0, len(e.list))
+	if isProtoTimestamp(e.fd) { //객체가 아닌 원소는 건너뜀
+		return out, true
+	}
+	n := 0
+	for _, w := range e.list {
+		for i := range w.msg.entries {
+			n += 2 + 2*len(w.msg.entries[i].val.raw)
+		}
+	}
+	ints, t := newIntBlock(2*len(e.list)), newTextBlock(n)
+	for _, w := range e.list {
+		var vc abstraction.ViewChangeEntry
+		for i := range w.msg.entries {
+			f := &w.msg.entries[i]
+			if !f.set {
+				continue
+			}
+			switch f.fd.Name() {
+			case "view":
+				vc.View = protoBigInt(f, ints, c)
+			case "height":
+				vc.Height = protoBigInt(f, ints, c)
+			case "validator":
+				if f.fd.Kind() == protoreflect.StringKind && !f.fd.IsList() {
+					vc.Validator = t.text(f.val.raw)
+				} else {
+					vc.Validator = c.text(f.value())
+				}
+			case "signature":
+				if f.fd.Kind() == protoreflect.StringKind && !f.fd.IsList() {
+					vc.Signature = c.hashBytes(t, f.val.raw)
+				} else {
+					vc.Signature = c.hash(f.value())
+				}
+			}
+		}
+		out = append(out, vc)
+	}
+	return out, true
+} //repeated 메시지의 {view, height, validator, signature}
+
+func protoBigInt(e *protoEntry, ints *intBlock, c coercer) *big.Int {
+	if !e.fd.IsList() && !e.fd.IsMap() {
+		if x, ok := protoWireInt(e.fd, e.val); ok {
+			return ints.new(x)
+		}
+	}
+	return c.bigInt(e.value())
+} //정수 필드 값(coercer.bigInt와 같음)
+
+func (f *protoFields) extra(i int, format Format) abstraction.ExtraValue {
+	return extraValue(f.value(i), format)
+} //i번째 값을 ExtraValue로
+
+func (f *protoFields) unpackExtras(fd protoreflect.FieldDescriptor) map[string]abstraction.ExtraValue {
+	if fd == nil || f.slot[fd.Index()] == 0 || !f.entries[f.slot[fd.Index()]-1].set {
+		return nil
+	}
+	e := &f.entries[f.slot[fd.Index()]-1]
+	out := make(map[string]abstraction.ExtraValue, len(e.list))
+	n := 0
+	for i, k := range e.keys {
+		n += len(k.raw) + len(e.list[i].raw)
+	}
+	blk := newExtraBlock(len(e.list), n)
+	for i, k := range e.keys { //같은 key는 마지막 값
+		if x, ok := protoPackedExtra(blk, e.list[i].raw); ok {
+			out[blk.str(k.raw)] = x
+		} else {
+			out[blk.str(k.raw)] = extraValue(e.list[i].value(fd.MapValue()), FormatProtobuf) //JSON이 아닌 값은 bytes/string 그대로
+		}
+	}
+	return out
+} //extras map 필드(protoExtrasField)의 값을 타입 있는 Extras로 복원(unpackProtoExtras와 같음)
+
+func readProtoMapEntry(b []byte, fd protoreflect.FieldDescriptor, lv protoLevel) (protoWire, protoWire, error) {
+	kfd, vfd := fd.MapKey(), fd.MapValue()
+	var k, v protoWire //생략된 key/value는 기본값(0, 빈 문자열)
+	hasValue := false
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 || !num.IsValid() {
+			v.msg.release()
+			return k, v, errProtoFallback
+		}
+		b = b[n:]
+		var err error
+		switch num {
+		case kfd.Number():
+			k, _, n, err = readProtoWire(kfd, typ, b, lv)
+		case vfd.Number():
+			if hasValue && vfd.Message() != nil { //병합
+				v.msg.release()
+				return k, v, errProtoFallback
+			}
+			v, _, n, err = readProtoWire(vfd, typ, b, lv)
+			hasValue = err == nil
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if err == nil && n < 0 {
+			err = errProtoFallback
+		}
+		if err != nil {
+			v.msg.release()
+			return k, v, err
+		}
+		b = b[n:]
+	}
+	if !hasValue {
+		if vfd.Kind() == protoreflect.EnumKind { //enum 기본값은 첫 번째 값일 수 있음
+			return k, v, errProtoFallback
+		}
+		if vfd.Message() != nil { //빈 메시지
+			m, err := readProtoFields(nil, vfd.Message(), lv.nested())
+			if err != nil {
+				return k, v, err
+			}
+			v.msg = m
+		}
+	}
+	return k, v, nil
+} //map entry 메시지의 key와 값
+
+func readProtoWire(fd protoreflect.FieldDescriptor, typ protowire.Type, b []byte, lv protoLevel) (protoWire, bool, int, error) {
+	var w protoWire
+	if fd.Kind() == protoreflect.GroupKind || typ != protoWireType(fd.Kind()) { //wire type이 다를 시 unknown 필드
+		return w, false, 0, errProtoFallback
+	}
+	n := -1
+	switch typ {
+	case protowire.VarintType:
+		w.x, n = protowire.ConsumeVarint(b)
+	case protowire.Fixed32Type:
+		var x32 uint32
+		x32, n = protowire.ConsumeFixed32(b)
+		w.x = uint64(x32)
+	case protowire.Fixed64Type:
+		w.x, n = protowire.ConsumeFixed64(b)
+	case protowire.BytesType:
+		w.raw, n = protowire.ConsumeBytes(b)
+	}
+	if n < 0 {
+		return w, false, 0, errProtoFallback
+	}
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		num := protoreflect.EnumNumber(int32(w.x))
+		if fd.Enum().Values().ByNumber(num) == nil && fd.Enum().IsClosed() { //닫힌 enum의 모르는 값은 unknown 필드
+			return w, false, 0, errProtoFallback
+		}
+		return w, num == 0, n, nil
+	case protoreflect.FloatKind:
+		f := math.Float32frombits(uint32(w.x))
+		return w, f == 0 && !math.Signbit(float64(f)), n, nil
+	case protoreflect.DoubleKind:
+		f := math.Float64frombits(w.x)
+		return w, f == 0 && !math.Signbit(f), n, nil
+	case protoreflect.StringKind:
+		if !utf8.Valid(w.raw) { //UTF-8 검사 여부는 syntax/edition에 따름
+			return w, false, 0, errProtoFallback
+		}
+		return w, len(w.raw) == 0, n, nil
+	case protoreflect.BytesKind:
+		return w, len(w.raw) == 0, n, nil
+	case protoreflect.MessageKind:
+		m, err := readProtoFields(w.raw, fd.Message(), lv.nested())
+		if err != nil {
+			return w, false, 0, err
+		}
+		w.msg = m
+		return w, false, n, nil
+	}
+	return w, w.x == 0, n, nil
+} //단일 값 읽기(값, 기본값 여부, 읽은 길이)
+
+func (w protoWire) value(fd protoreflect.FieldDescriptor) interface{} {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return protowire.DecodeBool(w.x)
+	case protoreflect.EnumKind:
+		num := protoreflect.EnumNumber(int32(w.x))
+		if ev := fd.Enum().Values().ByNumber(num); ev != nil { //enum은 이름으로
+			return string(ev.Name())
+		}
+		return int64(num)
+	case protoreflect.Int32Kind, protoreflect.Sfixed32Kind:
+		return int32(w.x)
+	case protoreflect.Sint32Kind:
+		return int32(protowire.DecodeZigZag(w.x & math.MaxUint32))
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return uint32(w.x)
+	case protoreflect.Int64Kind, protoreflect.Sfixed64Kind:
+		return int64(w.x)
+	case protoreflect.Sint64Kind:
+		return protowire.DecodeZigZag(w.x)
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return w.x
+	case protoreflect.FloatKind:
+		return math.Float32frombits(uint32(w.x))
+	case protoreflect.DoubleKind:
+		return math.Float64frombits(w.x)
+	case protoreflect.StringKind:
+		return string(w.raw)
+	case protoreflect.BytesKind:
+		return append([]byte(nil), w.raw...)
+	case protoreflect.MessageKind:
+		if isProtoTimestamp(fd) {
+			return w.msg.timestamp()
+		}
+		return w.msg.toMap()
+	}
+	return nil
+} //protoScalarValue와 같은 Go 타입으로
+
+func isProtoTimestamp(fd protoreflect.FieldDescriptor) bool {
+	return fd.Message() != nil && fd.Message().FullName() == "google.protobuf.Timestamp"
+} //well-known Timestamp 메시지 필드
+
+func (f *protoFields) timestamp() time.Time {
+	var sec, nanos int64
+	for i := range f.entries {
+		e := &f.entries[i]
+		if !e.set || e.fd.IsList() {
+			continue
+		}
+		switch {
+		case e.fd.Name() == "seconds" && e.fd.Kind() == protoreflect.Int64Kind:
+			sec = int64(e.val.x)
+		case e.fd.Name() == "nanos" && e.fd.Kind() == protoreflect.Int32Kind:
+			nanos = int64(int32(e.val.x))
+		}
+	}
+	return time.Unix(sec, nanos).UTC()
+} //Timestamp 메시지의 시각
+
+func protoPackable(k protoreflect.Kind) bool {
+	switch k {
+	case protoreflect.StringKind, protoreflect.BytesKind, protoreflect.MessageKind, protoreflect.GroupKind:
+		return false
+	}
+	return true
+} //packed 인코딩이 가능한 scalar 타입
+
+func protoWireType(k protoreflect.Kind) protowire.Type {
+	switch k {
+	case protoreflect.Fixed32Kind, protoreflect.Sfixed32Kind, protoreflect.FloatKind:
+		return protowire.Fixed32Type
+	case protoreflect.Fixed64Kind, protoreflect.Sfixed64Kind, protoreflect.DoubleKind:
+		return protowire.Fixed64Type
+	case protoreflect.StringKind, protoreflect.BytesKind, protoreflect.MessageKind:
+		return protowire.BytesType
+	case protoreflect.GroupKind:
+		return protowire.StartGroupType
+	}
+	return protowire.VarintType
+} //필드 타입의 wire type
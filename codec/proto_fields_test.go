@@ -0,0 +1,264 @@
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"codec/abstraction"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func checkProtoDirect(t *testing.T, name string, am *abstraction.AbstractMessage, md protoreflect.MessageDescriptor, opts SerializeOptions) bool {
+	t.Helper()
+	obj, err := protoObject(am, md, opts)
+	if err != nil {
+		return false //스키마에 담을 수 없는 메시지
+	}
+	viaJSON := dynamicpb.NewMessage(md)
+	jsonErr := protoFromJSON(viaJSON, obj, opts.ProtoDiscardUnknown)
+	direct := dynamicpb.NewMessage(md)
+	if err := setProtoMessage(direct, obj, opts.ProtoDiscardUnknown); err != nil {
+		return false //protojson 경로 사용
+	}
+	if jsonErr != nil {
+		t.Errorf("%s: direct path accepted a message protojson rejects: %v", name, jsonErr)
+	} else if !proto.Equal(direct, viaJSON) {
+		t.Errorf("%s: direct path differs from protojson\n direct: %v\n   json: %v", name, direct, viaJSON)
+	}
+	return true
+} //직접 설정한 메시지가 protojson 경로의 결과와 같은지 확인(직접 경로를 사용했는지 반환)
+
+func TestProtoDirectMatchesJSON(t *testing.T) {
+	fuzzSetup(t)
+	md, err := DefaultDescriptorRegistry.FindMessageByName(fuzzProtoMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := fuzzSerializeOptions(FormatProtobuf)
+	for i, am := range fuzzFixtures() {
+		checkProtoDirect(t, fmt.Sprintf("%s/fixture%d", fuzzProtoMessage, i), am, md, opts)
+	}
+	for _, size := range benchSizes {
+		if !checkProtoDirect(t, fuzzProtoMessage+"/"+size.name, benchMessage(size.seals, size.changes, size.extras), md, opts) {
+			t.Errorf("%s: benchmark message fell back to protojson", size.name)
+		}
+	}
+	provider := goldenProvider(t)
+	for _, tc := range goldenCases {
+		if tc.opts.Format != FormatProtobuf {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(goldenDir, tc.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		popts := tc.opts
+		popts.DescriptorProvider = provider
+		am, err := Parse(data, popts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		md, err := provider.FindMessageByName(protoreflect.FullName(tc.opts.ProtoMessageFullName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkProtoDirect(t, tc.file, am, md, SerializeOptions{Format: FormatProtobuf, ProtoMessageFullName: tc.opts.ProtoMessageFullName, ProtoDiscardUnknown: true})
+	}
+} //fixture/벤치마크/golden 메시지의 protobuf 직접 설정 결과 비교
+
+var protoFieldsOptions = []ParseOptions{
+	{Format: FormatProtobuf},
+	{Format: FormatProtobuf, HashEncoding: HashBase64, DetectBase64: true},
+	{Format: FormatProtobuf, HashEncoding: HashPreserve, ExactSynonyms: true, Limits: DefaultLimits},
+} //직접 정규화와 트리 정규화를 비교할 옵션
+
+func checkProtoFields(t *testing.T, name string, data []byte, md protoreflect.MessageDescriptor) bool {
+	t.Helper()
+	used := false
+	for _, opts := range protoFieldsOptions {
+		f, err := readProtoFields(data, md, protoLevel{depth: 1})
+		if err != nil {
+			continue //dynamicpb 경로 사용
+		}
+		used = true
+		direct, directErr := protoMessageFromFields(f, md, opts)
+		f.release()
+		msg := dynamicpb.NewMessage(md)
+		if err := proto.Unmarshal(data, msg); err != nil {
+			t.Fatalf("%s: direct decoding accepted input dynamicpb rejects: %v", name, err)
+		}
+		want, wantErr := protoMessageFromMap(protoToMap(msg), md, opts)
+		if (directErr != nil) != (wantErr != nil) {
+			t.Fatalf("%s: direct error %v, tree error %v", name, directErr, wantErr)
+		}
+		if !identicalMessage(direct, want) {
+			t.Fatalf("%s: direct normalization differs from dynamicpb\n direct: %#v\n   want: %#v", name, direct, want)
+		}
+	}
+	return used
+} //필드 값에서 바로 정규화한 결과가 dynamicpb 메시지를 map으로 바꿔 정규화한 결과와 같은지 확인(직접 경로를 사용했는지 반환)
+
+type protoInput struct {
+	name string
+	data []byte
+	md   protoreflect.MessageDescriptor
+} //descriptor와 그 메시지의 인코딩
+
+func protoInputs(tb testing.TB) []protoInput {
+	tb.Helper()
+	md, err := DefaultDescriptorRegistry.FindMessageByName(fuzzProtoMessage)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	var in []protoInput
+	for i, am := range fuzzFixtures() {
+		data, err := Serialize(am, fuzzSerializeOptions(FormatProtobuf))
+		if err != nil {
+			continue //스키마에 담을 수 없는 메시지
+		}
+		in = append(in, protoInput{fmt.Sprintf("fixture%d", i), data, md})
+	}
+	for _, size := range benchSizes {
+		data, err := Serialize(benchMessage(size.seals, size.changes, size.extras), fuzzSerializeOptions(FormatProtobuf))
+		if err != nil {
+			tb.Fatal(err)
+		}
+		in = append(in, protoInput{size.name, data, md})
+	}
+	golden := goldenProvider(tb)
+	for _, tc := range goldenCases { //enum, oneof, Timestamp가 있는 스키마
+		if tc.opts.Format != FormatProtobuf {
+			continue
+		}
+		md, err := golden.FindMessageByName(protoreflect.FullName(tc.opts.ProtoMessageFullName))
+		if err != nil {
+			tb.Fatal(err)
+		}
+		data, err := os.ReadFile(filepath.Join(goldenDir, tc.file))
+		if err != nil {
+			tb.Fatal(err)
+		}
+		in = append(in, protoInput{tc.file, data, md})
+	}
+	return in
+} //fixture/벤치마크/golden 메시지의 protobuf 인코딩
+
+func TestProtoFieldsMatchTree(t *testing.T) {
+	fuzzSetup(t)
+	for _, in := range protoInputs(t) {
+		if !checkProtoFields(t, in.name, in.data, in.md) {
+			t.Errorf("%s: fell back to dynamicpb", in.name)
+		}
+	}
+} //fixture/벤치마크/golden 입력의 protobuf 직접 정규화 결과 비교
+
+func FuzzProtoWireToMap(f *testing.F) {
+	fuzzSeed(f, FormatProtobuf)
+	var mds []protoreflect.MessageDescriptor
+	seen := map[protoreflect.FullName]bool{}
+	for _, in := range protoInputs(f) {
+		f.Add(in.data)
+		if !seen[in.md.FullName()] {
+			seen[in.md.FullName()] = true
+			mds = append(mds, in.md)
+		}
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, md := range mds {
+			checkProtoFields(t, string(md.FullName()), data, md)
+			direct, err := protoWireToMap(data, md, protoLevel{depth: 1})
+			if err != nil {
+				continue //dynamicpb 경로 사용
+			}
+			msg := dynamicpb.NewMessage(md)
+			if err := proto.Unmarshal(data, msg); err != nil {
+				t.Fatalf("%s: direct decoding accepted input dynamicpb rejects: %v", md.FullName(), err)
+			}
+			if want := protoToMap(msg); !reflect.DeepEqual(direct, want) {
+				t.Fatalf("%s: direct decoding differs from dynamicpb\n direct: %#v\n   want: %#v", md.FullName(), direct, want)
+			}
+		}
+	})
+} //dynamicpb 없이 decoding한 map과 정규화 결과가 protoToMap 경로와 같은지 확인
+
+func TestProtoOverflow(t *testing.T) {
+	fuzzSetup(t)
+	maxU64 := new(big.Int).SetUint64(math.MaxUint64)
+	over := func(x *big.Int, d int64) *big.Int { return new(big.Int).Add(x, big.NewInt(d)) }
+	smartbft := SerializeOptions{Format: FormatProtobuf, ProtoMessageFullName: "smartbftprotos.Commit", DescriptorProvider: goldenProvider(t), ProtoDiscardUnknown: true}
+	cases := []struct {
+		name  string
+		am    *abstraction.AbstractMessage
+		sopts SerializeOptions
+		field string //빈 값일 시 범위 안
+		typ   string
+	}{
+		{"MaxUint64 into uint64", &abstraction.AbstractMessage{View: maxU64}, smartbft, "", ""},
+		{"MaxUint64+1 into uint64", &abstraction.AbstractMessage{View: over(maxU64, 1)}, smartbft, "view", "uint64"},
+		{"negative into uint64", &abstraction.AbstractMessage{View: big.NewInt(-1)}, smartbft, "view", "uint64"},
+		{"MaxInt64 into int64", &abstraction.AbstractMessage{Height: big.NewInt(math.MaxInt64)}, fuzzSerializeOptions(FormatProtobuf), "", ""},
+		{"MinInt64 into int64", &abstraction.AbstractMessage{Height: big.NewInt(math.MinInt64)}, fuzzSerializeOptions(FormatProtobuf), "", ""},
+		{"MaxInt64+1 into int64", &abstraction.AbstractMessage{Height: over(big.NewInt(math.MaxInt64), 1)}, fuzzSerializeOptions(FormatProtobuf), "height", "int64"},
+		{"MinInt64-1 into int64", &abstraction.AbstractMessage{Height: over(big.NewInt(math.MinInt64), -1)}, fuzzSerializeOptions(FormatProtobuf), "height", "int64"},
+		{"MaxUint64 into int64", &abstraction.AbstractMessage{Round: maxU64}, fuzzSerializeOptions(FormatProtobuf), "round", "int64"},
+		{"nested int64", &abstraction.AbstractMessage{ViewChanges: []abstraction.ViewChangeEntry{{View: big.NewInt(1)}, {View: over(maxU64, 1)}}}, fuzzSerializeOptions(FormatProtobuf), "view_changes[1].view", "int64"},
+	}
+	for _, tc := range cases {
+		tc.am.Type = abstraction.MsgTypeCommit
+		for _, from := range []Format{FormatJSON, FormatRLP, FormatMsgPack} {
+			src, err := Serialize(tc.am, SerializeOptions{Format: from})
+			if err != nil {
+				t.Fatalf("%s from %s: %v", tc.name, from, err)
+			}
+			am, err := Parse(src, ParseOptions{Format: from})
+			if err != nil {
+				t.Fatalf("%s from %s: %v", tc.name, from, err)
+			}
+			if diff := tc.am.Diff(am, abstraction.EqualOptions{IgnoreRawPayload: true}); len(diff) > 0 {
+				t.Fatalf("%s from %s: integer changed while decoding: %s", tc.name, from, diff) //float64을 거치지 않음
+			}
+			out, err := Convert(src, ParseOptions{Format: from}, tc.sopts)
+			if tc.field == "" {
+				if err != nil || len(out) == 0 {
+					t.Errorf("%s from %s: Convert = %v", tc.name, from, err)
+				}
+				continue
+			}
+			var oe *OverflowError
+			if !errors.Is(err, ErrOverflow) || !errors.As(err, &oe) {
+				t.Errorf("%s from %s: Convert = %v, want OverflowError", tc.name, from, err)
+				continue
+			}
+			want := tc.am.View
+			switch {
+			case tc.am.Height != nil:
+				want = tc.am.Height
+			case tc.am.Round != nil:
+				want = tc.am.Round
+			case tc.am.ViewChanges != nil:
+				want = tc.am.ViewChanges[1].View
+			}
+			if oe.Format != FormatProtobuf || oe.Field != tc.field || oe.Type != tc.typ || oe.Value.Cmp(want) != 0 {
+				t.Errorf("%s from %s: OverflowError = %+v, want %s %s %v", tc.name, from, oe, tc.field, tc.typ, want)
+			}
+		}
+	}
+	src := []byte(`{"type":"Commit","view":18446744073709551616}`) //float64이면 MaxUint64+1과 구별 안 되는 값
+	_, err := Convert(src, ParseOptions{Format: FormatJSON}, smartbft)
+	var oe *OverflowError
+	if !errors.As(err, &oe) || oe.Value.String() != "18446744073709551616" {
+		t.Errorf("JSON view 2^64 = %v, want OverflowError with the exact value", err)
+	}
+	if _, err := Convert([]byte(`{"type":"Commit","view":18446744073709551615}`), ParseOptions{Format: FormatJSON}, smartbft); err != nil {
+		t.Errorf("JSON view MaxUint64 = %v", err)
+	}
+} //JSON/RLP/msgpack에서 읽은 정수를 protobuf 고정 폭 정수로 변환할 때 경계값(범위 밖은 OverflowError)
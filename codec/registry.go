@@ -0,0 +1,56 @@
+package codec
+
+import (
+	"sort"
+	"sync"
+)
+
+var (
+	codecMu sync.RWMutex
+	codecs  = map[Format]registeredCodec{}
+) //포맷 -> Codec 등록부
+
+type registeredCodec struct {
+	c   Codec
+	ctx ContextCodec //WithContext(c)(호출마다 adapter를 새로 할당하지 않도록)
+}
+
+func RegisterCodec(format Format, c Codec) {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	codecs[format] = registeredCodec{c: c, ctx: WithContext(c)}
+} //Codec 등록(같은 포맷은 덮어씀, Parse/Serialize/Decoder가 이 포맷으로 사용)
+
+func LookupCodec(format Format) (Codec, bool) {
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+	r, ok := codecs[format]
+	return r.c, ok
+} //포맷으로 Codec 조회
+
+func lookupContextCodec(format Format) (ContextCodec, bool) {
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+	r, ok := codecs[format]
+	return r.ctx, ok
+} //포맷으로 등록 시 만든 ContextCodec 조회
+
+func CodecFormats() []Format {
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+	formats := make([]Format, 0, len(codecs))
+	for f := range codecs {
+		formats = append(formats, f)
+	}
+	sort.Slice(formats, func(i, j int) bool { return formats[i] < formats[j] })
+	return formats
+} //등록된 포맷 목록(정렬)
+
+func init() {
+	RegisterCodec(FormatGeneric, genericCodec{})
+	RegisterCodec(FormatJSON, jsonCodec{})
+	RegisterCodec(FormatProtobuf, protoCodec{})
+	RegisterCodec(FormatRLP, rlpCodec{})
+	RegisterCodec(FormatMsgPack, msgpackCodec{})
+	RegisterCodec(FormatBCS, bcsCodec{})
+} //내장 codec 등록
@@ -1,34 +1,165 @@
-package codec
-
-import (
-	"codec/abstraction"
-	"fmt"
-
-	"github.com/ethereum/go-ethereum/rlp"
-)
-
-type rlpCodec struct{} //rlp 포맷 parsing/serializing
-
-func (rlpCodec) Parse(data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error) {
-	var raw []byte
-	if err := rlp.DecodeBytes(data, &raw); err == nil {
-		return (jsonCodec{}).Parse(raw, ParseOptions{Format: FormatJSON, OverrideMsgType: opts.OverrideMsgType})
-	}
-	var decoded interface{}
-	if err := rlp.DecodeBytes(data, &decoded); err != nil {
-		return nil, fmt.Errorf("rlp decode: %w", err)
-	}
-	js, err := jsonFromInterface(decoded)
-	if err != nil {
-		return nil, err
-	}
-	return (jsonCodec{}).Parse(js, ParseOptions{Format: FormatJSON, OverrideMsgType: opts.OverrideMsgType})
-} //rlp 바이트를 AbstractMessage로 변환
-
-func (rlpCodec) Serialize(am *abstraction.AbstractMessage, _ SerializeOptions) ([]byte, error) {
-	js, err := (jsonCodec{}).Serialize(am, SerializeOptions{Format: FormatJSON}) //JSON 바이트로 변환
-	if err != nil {
-		return nil, err
-	}
-	return rlp.EncodeToBytes(js) //
-} //AbstractMessage를 rlp 바이트로 변환
+package codec
+
+import (
+	"fmt"
+
+	"codec/abstraction"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+type rlpCodec struct{} //rlp 포맷 parsing/serializing
+
+func (rlpCodec) Parse(data []byte, opts ParseOptions) (*abstraction.AbstractMessage, error) {
+	if kind, raw, rest, err := rlp.Split(data); err == nil && kind != rlp.List && len(rest) == 0 { //rlp.DecodeBytes가 []byte로 읽는 값을 복사 없이
+		am, err := (jsonCodec{}).Parse(raw, jsonParseOptions(opts))
+		if err != nil {
+			return nil, decodeError(FormatRLP, data, err) //내부 JSON payload 에러
+		}
+		am.OriginalFormat = string(FormatRLP) //원본 포맷 기록
+		return am, nil
+	}
+	if err := checkRLPLimits(data, opts.Limits); err != nil { //interface{} 트리를 만들기 전 구조 확인
+		return nil, err
+	}
+	if len(opts.RLPFields) > 0 { //위치로 구분되는 리스트(IBFT/QBFT 등)
+		if err := rlpCheck(data); err != nil {
+			if derr := rlp.DecodeBytes(data, new(interface{})); derr != nil { //에러 보고는 디코더 메시지로
+				err = derr
+			}
+			return nil, decodeError(FormatRLP, data, err)
+		}
+		m, err := rlpFieldMap(data, opts.RLPFields) //interface{} 트리 없이 위치별 값을 바로 map으로
+		if err != nil {
+			return nil, &ParseError{Format: FormatRLP, Offset: -1, Err: err}
+		}
+		opts.Format = FormatRLP
+		am, err := messageFromMap(m, opts) //bytes 값을 유지한 채 정규화(정수는 big-endian)
+		if err != nil {
+			return nil, decodeError(FormatRLP, data, err)
+		}
+		return am, nil
+	}
+	var decoded interface{}
+	if err := rlp.DecodeBytes(data, &decoded); err != nil {
+		return nil, decodeError(FormatRLP, data, err)
+	}
+	js, err := jsonFromInterface(decoded)
+	if err != nil {
+		return nil, decodeError(FormatRLP, data, err)
+	}
+	am, err := (jsonCodec{}).Parse(js, jsonParseOptions(opts))
+	if err != nil {
+		return nil, decodeError(FormatRLP, data, err)
+	}
+	am.OriginalFormat = string(FormatRLP) //원본 포맷 기록
+	return am, nil
+} //rlp 바이트를 AbstractMessage로 변환
+
+type RLPField struct {
+	Name   string     //필드명(빈 이름은 버림)
+	Fields []RLPField //원소가 고정 길이 리스트일 때 그 원소들의 필드(Name이 있으면 Name 아래 map으로, 없으면 바깥 필드와 같은 단계로)
+} //RLP 리스트의 한 위치(Fields가 없으면 원소 값 그대로, 리스트면 가변 길이 리스트 전체)
+
+func rlpCheck(b []byte) error {
+	_, content, rest, err := rlp.Split(b)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return rlp.ErrMoreThanOneValue
+	}
+	if b[0] < 0xc0 { //문자열
+		return nil
+	}
+	for len(content) > 0 {
+		_, _, next, err := rlp.Split(content)
+		if err != nil {
+			return err
+		}
+		if err := rlpCheck(content[:len(content)-len(next)]); err != nil {
+			return err
+		}
+		content = next
+	}
+	return nil
+} //rlp.DecodeBytes가 받아들이는 값 하나인지 확인(트리는 만들지 않음)
+
+func rlpFieldMap(data []byte, fields []RLPField) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, len(fields))
+	kind, content, _, _ := rlp.Split(data) //rlpCheck에서 확인됨
+	if err := rlpAssign(m, kind, content, fields, "RLP list"); err != nil {
+		return nil, err
+	}
+	return m, nil
+} //RLP 리스트 원소를 위치별로 fields와 짝지음
+
+func rlpAssign(m map[string]interface{}, kind rlp.Kind, content []byte, fields []RLPField, at string) error {
+	if kind != rlp.List {
+		return fmt.Errorf("%s: RLPFields requires a list, got a string", at)
+	}
+	if n, _ := rlp.CountValues(content); n != len(fields) {
+		return fmt.Errorf("%s has %d items, RLPFields names %d", at, n, len(fields))
+	}
+	for i, f := range fields {
+		k, c, rest, _ := rlp.Split(content)
+		content = rest
+		var item interface{}
+		if len(f.Fields) > 0 {
+			dst := m //이름 없는 중첩 리스트는 바깥 필드와 같은 단계로
+			if f.Name != "" {
+				dst = make(map[string]interface{}, len(f.Fields))
+			}
+			if err := rlpAssign(dst, k, c, f.Fields, fmt.Sprintf("%s item %d", at, i)); err != nil {
+				return err
+			}
+			item = dst
+		} else if f.Name != "" {
+			item = rlpTree(k, c)
+		}
+		if f.Name == "" { //빈 이름은 버림
+			continue
+		}
+		if _, dup := m[f.Name]; dup {
+			return fmt.Errorf("%s: RLPFields names %q twice", at, f.Name)
+		}
+		m[f.Name] = item
+	}
+	return nil
+} //list의 각 원소를 같은 위치의 field에 기록(중첩 리스트는 재귀)
+
+func rlpTree(kind rlp.Kind, content []byte) interface{} {
+	if kind != rlp.List {
+		return content //정규화 시 복사되므로 입력을 그대로 참조
+	}
+	list := []interface{}{}
+	for len(content) > 0 {
+		k, c, rest, _ := rlp.Split(content)
+		list = append(list, rlpTree(k, c))
+		content = rest
+	}
+	return list
+} //rlp.DecodeBytes가 interface{}로 읽는 값([]byte 또는 []interface{})
+
+func (rlpCodec) Serialize(am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error) {
+	out, err := messageToMap(am, jsonOptions(opts), extraInterface)
+	if err != nil {
+		return nil, err
+	}
+	return encodeJSON(out, rlpStringHeader) //JSON 바이트를 rlp 문자열로
+} //AbstractMessage를 rlp 바이트로 변환
+
+func rlpStringHeader(dst []byte, n int) []byte {
+	if n < 56 { //JSON 객체는 2바이트 이상이라 단일 바이트 문자열 규칙은 해당 없음
+		return append(dst, 0x80+byte(n))
+	}
+	size := 0
+	for x := n; x > 0; x >>= 8 {
+		size++
+	}
+	dst = append(dst, 0xb7+byte(size))
+	for i := size - 1; i >= 0; i-- {
+		dst = append(dst, byte(n>>(8*i)))
+	}
+	return dst
+} //길이 n인 rlp 문자열의 header(rlp.EncodeToBytes와 같은 big-endian 길이)
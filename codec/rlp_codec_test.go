@@ -0,0 +1,111 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestRLPFieldsPositional(t *testing.T) {
+	fields := []RLPField{
+		{Fields: []RLPField{{Name: "sequence"}, {Name: "round"}, {Name: "commit_seals"}}},
+		{Name: "signature"},
+		{Name: "proposal", Fields: []RLPField{{Name: "digest"}, {}}}, //이름 있는 중첩 리스트는 map, 빈 이름은 버림
+	}
+	for _, n := range []int{0, 1, 3} { //seal 수가 달라도 같은 RLPFields
+		seals := make([][]byte, n)
+		for i := range seals {
+			seals[i] = []byte{byte(0xa0 + i)}
+		}
+		data, err := rlp.EncodeToBytes([]interface{}{
+			[]interface{}{uint64(7), uint64(1), seals},
+			[]byte{0xab},
+			[]interface{}{[]byte{0xcd}, []byte("dropped")},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		am, err := Parse(data, ParseOptions{Format: FormatRLP, OverrideMsgType: "Commit", RLPFields: fields})
+		if err != nil {
+			t.Fatalf("%d seals: %v", n, err)
+		}
+		if am.Height.Int64() != 7 || am.Round.Int64() != 1 || am.Signature != "0xab" || len(am.CommitSeals) != n {
+			t.Fatalf("%d seals: got height %v round %v signature %q seals %v", n, am.Height, am.Round, am.Signature, am.CommitSeals)
+		}
+		if want := fmt.Sprintf("0x%02x", 0xa0+n-1); n > 0 && am.CommitSeals[n-1] != want {
+			t.Errorf("%d seals: last seal = %q", n, am.CommitSeals[n-1])
+		}
+		proposal := am.Extras["proposal"]
+		if len(proposal.Map) != 1 || !bytes.Equal(proposal.Map["digest"].Bytes, []byte{0xcd}) {
+			t.Errorf("%d seals: Extras[proposal] = %+v, want map with digest only", n, proposal)
+		}
+	}
+
+	data, _ := rlp.EncodeToBytes([]interface{}{[]interface{}{uint64(7), uint64(1)}, []byte{0xab}, []interface{}{}})
+	_, err := Parse(data, ParseOptions{Format: FormatRLP, RLPFields: fields})
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("short payload list: err = %v, want ParseError", err)
+	}
+} //RLPFields는 위치별로 짝지어 가변 길이 리스트를 한 필드로 유지(깊이 우선 펼침 아님)
+
+func checkRLPTree(t *testing.T, data []byte) {
+	t.Helper()
+	var want interface{}
+	wantErr := rlp.DecodeBytes(data, &want)
+	if err := rlpCheck(data); (err == nil) != (wantErr == nil) {
+		t.Fatalf("%x: rlpCheck error %v, rlp.DecodeBytes error %v", data, err, wantErr)
+	}
+	if wantErr != nil {
+		return
+	}
+	kind, content, _, _ := rlp.Split(data)
+	if got := rlpTree(kind, content); !reflect.DeepEqual(got, want) {
+		t.Fatalf("%x: rlpTree = %#v, rlp.DecodeBytes = %#v", data, got, want)
+	}
+} //트리 없이 확인/참조한 값이 rlp.DecodeBytes 결과와 같은지 확인
+
+func rlpTreeSeeds(tb testing.TB) [][]byte {
+	tb.Helper()
+	seeds := [][]byte{
+		{0x80}, {0x00}, {0x7f}, {0x81, 0x80}, {0xc0}, {0xc1, 0xc0}, {0xc2, 0x80, 0x01},
+		{0x81, 0x01},             //한 바이트 값을 문자열로(비표준)
+		{0xb8, 0x01, 0x80},       //짧은 문자열을 긴 형식으로(비표준)
+		{0xc2, 0x80},             //리스트 길이가 내용보다 큼
+		{0x80, 0x80},             //값 뒤에 남은 데이터
+		{0xc1, 0x81},             //리스트 안의 잘린 값
+		{0xc3, 0xc1, 0x81, 0x01}, //리스트 안의 비표준 값
+	}
+	for _, tc := range goldenCases {
+		if tc.opts.Format != FormatRLP {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(goldenDir, tc.file))
+		if err != nil {
+			tb.Fatal(err)
+		}
+		seeds = append(seeds, data)
+	}
+	return seeds
+} //표준/비표준 RLP 값과 golden RLP 입력
+
+func TestRLPTreeMatchesDecoder(t *testing.T) {
+	for _, data := range rlpTreeSeeds(t) {
+		checkRLPTree(t, data)
+	}
+} //RLPFields 경로의 확인/참조 결과 비교
+
+func FuzzRLPTree(f *testing.F) {
+	for _, data := range rlpTreeSeeds(f) {
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		checkRLPTree(t, data)
+	})
+} //임의 입력에서 rlpCheck/rlpTree가 rlp.DecodeBytes와 같은 결과인지 확인
@@ -1,55 +1,126 @@
-package codec
-
-import (
-	"codec/abstraction"
-	"fmt"
-	"strings"
-	"time"
-)
-
-func (genericCodec) Serialize(am *abstraction.AbstractMessage, _ SerializeOptions) ([]byte, error) {
-	s, err := SerializeGeneric(am)
-	if err != nil {
-		return nil, err
-	}
-	return []byte(s), nil //문자열을 바이트로 변환하여 반환
-} //AbstractMessage를 generic 문자열 포맷으로 serializing
-
-func SerializeGeneric(am *abstraction.AbstractMessage) (string, error) {
-	phase := string(am.Type)
-	var parts []string    //"k=v" 항목
-	if am.Height != nil { //값이 존재할 시
-		parts = append(parts, fmt.Sprintf("height=%s", am.Height)) //big.Int를 10진수 출력
-	}
-	if am.Round != nil {
-		parts = append(parts, fmt.Sprintf("round=%s", am.Round))
-	}
-	if am.View != nil {
-		parts = append(parts, fmt.Sprintf("view=%s", am.View))
-	}
-	if am.BlockHash != "" {
-		parts = append(parts, fmt.Sprintf("block_hash=%s", am.BlockHash))
-	}
-	if am.PrevHash != "" {
-		parts = append(parts, fmt.Sprintf("prev_hash=%s", am.PrevHash))
-	}
-	if !am.Timestamp.IsZero() { //Zero 타임이 아닐 시 포함
-		parts = append(parts, fmt.Sprintf("timestamp=%s", am.Timestamp.UTC().Format(time.RFC3339))) //RFC3339로 출력
-	}
-	if am.Proposer != "" {
-		parts = append(parts, fmt.Sprintf("proposer=%s", am.Proposer))
-	}
-	if am.Validator != "" {
-		parts = append(parts, fmt.Sprintf("validator=%s", am.Validator))
-	}
-	if am.Signature != "" {
-		parts = append(parts, fmt.Sprintf("signature=%s", am.Signature))
-	}
-	if len(am.CommitSeals) > 0 { //배열은 ','로 연결
-		parts = append(parts, fmt.Sprintf("commit_seals=%s", strings.Join(am.CommitSeals, ",")))
-	}
-	for k, v := range am.Extras { //Extras는 표준화되지 않은 key-value 쌍
-		parts = append(parts, fmt.Sprintf("%s=%s", k, string(v))) //[]byte 값을 문자열로 변환
-	}
-	return fmt.Sprintf("%s(%s)", phase, strings.Join(parts, ",")), nil
-} //Phase(k=v,...) 형태의 문자열 생성
+package codec
+
+import (
+	"codec/abstraction"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func (genericCodec) Serialize(am *abstraction.AbstractMessage, opts SerializeOptions) ([]byte, error) {
+	s, err := serializeGeneric(am, opts)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil //문자열을 바이트로 변환하여 반환
+} //AbstractMessage를 generic 문자열 포맷으로 serializing
+
+func SerializeGeneric(am *abstraction.AbstractMessage) (string, error) {
+	return serializeGeneric(am, SerializeOptions{Format: FormatGeneric})
+} //Phase(k=v,...) 형태의 문자열 생성
+
+func serializeGeneric(am *abstraction.AbstractMessage, opts SerializeOptions) (string, error) {
+	n, err := newNamer(am, opts)
+	if err != nil {
+		return "", err
+	}
+	parts, err := genericParts(am, opts)
+	if err != nil {
+		return "", err
+	}
+	items := make([]string, 0, len(parts)) //"k=v" 항목
+	for _, p := range parts {
+		items = append(items, quoteGeneric(p.key)+"="+p.value)
+	}
+	return fmt.Sprintf("@%d %s(%s)", GenericGrammarVersion, quoteGeneric(n.phase()), strings.Join(items, ",")), nil
+} //옵션에 따라 메시지명/필드명을 정해 "@1 Phase(k=v,...)" 문자열 생성(앞에 문법 version 표시)
+
+type genericPart struct {
+	key   string
+	value string //generic 값 표기
+} //출력할 k=v 한 쌍
+
+func genericParts(am *abstraction.AbstractMessage, opts SerializeOptions) ([]genericPart, error) {
+	n, err := newNamer(am, opts)
+	if err != nil {
+		return nil, err
+	}
+	var parts []genericPart
+	used := map[string]bool{} //이미 출력한 key
+	add := func(key, val string) {
+		used[key] = true
+		parts = append(parts, genericPart{key: key, value: val})
+	}
+	addField := func(field, val string) {
+		p := n.path(field)
+		if p == nil {
+			add(n.key(field), val)
+			return
+		}
+		for i := len(p) - 1; i > 0; i-- { //중첩 경로는 record로(예: block_id={hash=..})
+			val = "{" + quoteGeneric(p[i]) + "=" + val + "}"
+		}
+		add(p[0], val)
+	}
+	if am.Height != nil { //값이 존재할 시
+		addField("Height", am.Height.String()) //big.Int를 10진수 출력
+	}
+	if am.Round != nil {
+		addField("Round", am.Round.String())
+	}
+	if am.View != nil {
+		addField("View", am.View.String())
+	}
+	if am.BlockHash != "" {
+		addField("BlockHash", quoteGeneric(am.BlockHash))
+	}
+	if am.PrevHash != "" {
+		addField("PrevHash", quoteGeneric(am.PrevHash))
+	}
+	if !am.Timestamp.IsZero() { //Zero 타임이 아닐 시 포함
+		addField("Timestamp", opts.TimestampFormat.text(am.Timestamp)) //기본값 RFC3339Nano
+	}
+	if am.Proposer != "" {
+		addField("Proposer", quoteGeneric(am.Proposer))
+	}
+	if am.Validator != "" {
+		addField("Validator", quoteGeneric(am.Validator))
+	}
+	if am.Signature != "" {
+		addField("Signature", quoteGeneric(am.Signature))
+	}
+	if len(am.CommitSeals) > 0 { //배열은 [a,b,...] 리스트로 표기
+		seals := make([]string, 0, len(am.CommitSeals))
+		for _, s := range am.CommitSeals {
+			seals = append(seals, quoteGeneric(s))
+		}
+		addField("CommitSeals", "["+strings.Join(seals, ",")+"]")
+	}
+	if len(am.ViewChanges) > 0 { //[{view=..,height=..,validator=..,signature=..},...]
+		entries := make([]string, 0, len(am.ViewChanges))
+		for _, e := range am.ViewChanges {
+			var fs []string
+			if e.View != nil {
+				fs = append(fs, fmt.Sprintf("view=%s", e.View))
+			}
+			if e.Height != nil {
+				fs = append(fs, fmt.Sprintf("height=%s", e.Height))
+			}
+			fs = append(fs, "validator="+quoteGeneric(e.Validator), "signature="+quoteGeneric(e.Signature))
+			entries = append(entries, "{"+strings.Join(fs, ",")+"}")
+		}
+		addField("ViewChanges", "["+strings.Join(entries, ",")+"]")
+	}
+	keys := make([]string, 0, len(am.Extras))
+	for k := range am.Extras {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)       //출력 순서 고정
+	for _, k := range keys { //Extras는 표준화되지 않은 key-value 쌍
+		if used[k] { //동일 key 가진 필드 중 표준 필드 우선
+			continue
+		}
+		add(k, genericExtraText(am.Extras[k]))
+	}
+	return parts, nil
+} //표준 필드와 Extras를 출력 순서대로 k=v 쌍으로 변환
@@ -0,0 +1,502 @@
+package codec
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"unicode"
+	"unicode/utf8"
+
+	"codec/abstraction"
+	"codec/framing"
+)
+
+const DefaultMaxMessageSize = 16 << 20 //스트림에서 메시지 하나의 기본 최대 크기(16 MiB)
+
+type splitFunc func(r *bufio.Reader, limit int) ([]byte, error) //스트림에서 메시지 하나의 바이트를 읽음(끝에 도달 시 io.EOF)
+
+type countingReader struct {
+	r io.Reader
+	n int64 //원본에서 읽은 바이트 수
+} //원본 reader에서 읽은 바이트 수 기록
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+} //읽은 만큼 증가
+
+type Decoder struct {
+	src    *countingReader
+	r      *bufio.Reader
+	opts   ParseOptions
+	split  splitFunc      //포맷별 메시지 경계 판별
+	framer framing.Framer //설정 시 포맷 대신 envelope로 경계 판별
+	frame  framing.Frame  //마지막으로 읽은 frame 메타데이터
+	limit  int            //메시지 최대 크기
+	err    error          //경계를 잃은 뒤의 에러(이후 Decode에서 계속 반환)
+} //io.Reader에서 메시지를 하나씩 parsing
+
+func NewDecoder(r io.Reader, opts ParseOptions) *Decoder {
+	src := &countingReader{r: r}
+	limit := DefaultMaxMessageSize
+	if opts.Limits.MaxBytes > 0 {
+		limit = opts.Limits.MaxBytes
+	}
+	return &Decoder{src: src, r: bufio.NewReader(src), opts: opts, limit: limit}
+} //opts.Format의 framing으로 r을 읽는 Decoder 생성(auto일 시 첫 메시지로 JSON/generic 판별, 메시지 크기 상한은 Limits.MaxBytes 또는 DefaultMaxMessageSize)
+
+func (d *Decoder) SetFramer(f framing.Framer) {
+	d.framer = f
+} //포맷 고유 framing 대신 wire envelope(framing 패키지)로 메시지 경계 판별, payload 포맷은 opts.Format(auto일 시 메시지마다 감지)
+
+func (d *Decoder) Frame() framing.Frame {
+	return d.frame
+} //마지막으로 읽은 메시지의 frame 메타데이터(SetFramer 사용 시 코드/peer, 그 외 Payload만)
+
+func (d *Decoder) SetMaxMessageSize(n int) {
+	d.limit = n
+} //메시지 하나의 최대 크기(초과 시 ErrMessageTooLarge), 메모리 사용량 상한
+
+func (d *Decoder) InputOffset() int64 {
+	return d.src.n - int64(d.r.Buffered())
+} //지금까지 소비한 스트림 바이트 수
+
+func (d *Decoder) Decode() (*abstraction.AbstractMessage, error) {
+	data, err := d.next()
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data, d.opts)
+} //다음 메시지를 parsing(스트림 끝일 시 io.EOF, parsing 에러 후에도 다음 메시지 계속 읽기 가능)
+
+func (d *Decoder) DecodeContext(ctx context.Context) (*abstraction.AbstractMessage, error) {
+	if err := ctx.Err(); err != nil { //경계를 잃지 않도록 읽기 전에만 확인
+		return nil, err
+	}
+	data, err := d.next()
+	if err != nil {
+		return nil, err
+	}
+	return ParseContext(ctx, data, d.opts)
+} //Decode의 취소 가능 버전(메시지를 읽은 뒤 parsing이 취소되어도 다음 호출은 그다음 메시지부터)
+
+func (d *Decoder) next() ([]byte, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	if d.split == nil && d.framer == nil { //envelope가 없을 시 포맷 고유 framing
+		if err := d.init(); err != nil {
+			return nil, err
+		}
+	}
+	start := d.InputOffset()
+	var data []byte
+	var err error
+	if d.framer != nil {
+		d.frame, err = d.framer.ReadFrame(d.r, d.limit)
+		data = d.frame.Payload
+		if err != nil && err != io.EOF {
+			err = unexpectedEOF(err)
+		}
+	} else {
+		data, err = d.split(d.r, d.limit)
+		d.frame = framing.Frame{Payload: data}
+	}
+	switch {
+	case err == io.EOF:
+		return nil, io.EOF
+	case err != nil:
+		d.err = &ParseError{Format: d.opts.Format, Offset: start, Err: err} //stream offset 기준
+		return nil, d.err
+	}
+	return data, nil
+} //다음 메시지 바이트(framing 에러 시 이후 호출도 같은 에러)
+
+func (d *Decoder) init() error {
+	if d.opts.Compression != CompressionAuto && d.opts.Compression != CompressionNone {
+		d.err = fmt.Errorf("%w: %s stream without a framer", ErrUnsupportedFormat, d.opts.Compression)
+		return d.err
+	} //압축된 payload는 포맷 고유 framing으로 경계를 알 수 없음
+	if d.opts.Format == "" || d.opts.Format == FormatAuto {
+		f, err := detectStreamFormat(d.r)
+		if err != nil {
+			return err
+		}
+		d.opts.Format = f
+	}
+	split, ok := splitters[d.opts.Format]
+	if !ok {
+		d.err = fmt.Errorf("%w: %s", ErrUnsupportedFormat, d.opts.Format)
+		return d.err
+	}
+	d.split = split
+	return nil
+} //포맷 결정 및 framing 선택
+
+func detectStreamFormat(r *bufio.Reader) (Format, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return "", err //빈 스트림은 io.EOF
+		}
+		if isSpace(b) {
+			continue
+		}
+		r.UnreadByte()
+		switch {
+		case b == '{' || b == '[':
+			return FormatJSON, nil
+		case b == '"' || b == '_' || b == '@' || isLetter(b): //'@'는 generic 문법 version 표시
+			return FormatGeneric, nil
+		}
+		return "", fmt.Errorf("%w: binary stream needs an explicit format", ErrUnsupportedFormat)
+	}
+} //스트림 첫 글자로 텍스트 포맷 판별(바이너리 포맷은 경계가 모호하여 명시 필요)
+
+var splitters = map[Format]splitFunc{
+	FormatJSON:     splitJSON,
+	FormatGeneric:  splitGeneric,
+	FormatRLP:      splitRLP,
+	FormatMsgPack:  splitMsgPack,
+	FormatProtobuf: splitUvarint,
+	FormatBCS:      splitBCS,
+} //포맷별 스트림 framing
+
+func skipSpace(r *bufio.Reader) error {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if !isSpace(b) {
+			return r.UnreadByte()
+		}
+	}
+} //메시지 사이 공백 건너뜀
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\n'
+} //JSON/generic 공백 문자
+
+func isLetter(b byte) bool {
+	if b < utf8.RuneSelf {
+		return unicode.IsLetter(rune(b))
+	}
+	return true //비 ASCII 이름
+} //generic 메시지명 첫 글자 후보
+
+func tooLarge(n, limit int) error {
+	if n > limit {
+		return fmt.Errorf("%w: more than %d bytes", ErrMessageTooLarge, limit)
+	}
+	return nil
+} //메시지 크기 상한 확인
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if isTruncation(err) {
+		return truncated(err)
+	}
+	return err
+} //메시지 중간에서 끝난 스트림을 ErrTruncatedInput으로
+
+func splitJSON(r *bufio.Reader, limit int) ([]byte, error) {
+	if err := skipSpace(r); err != nil {
+		return nil, err
+	}
+	return splitBalanced(r, limit, false)
+} //연속된 JSON 값(공백/줄바꿈 구분) 하나
+
+func splitGeneric(r *bufio.Reader, limit int) ([]byte, error) {
+	if err := skipSpace(r); err != nil {
+		return nil, err
+	}
+	return splitBalanced(r, limit, true)
+} //Phase(...) 형태 메시지 하나(여러 줄 허용)
+
+func splitBalanced(r *bufio.Reader, limit int, named bool) ([]byte, error) {
+	var buf []byte
+	depth, inString, escaped, opened := 0, false, false, false
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		buf = append(buf, b)
+		if err := tooLarge(len(buf), limit); err != nil {
+			return nil, err
+		}
+		switch {
+		case escaped:
+			escaped = false
+		case inString:
+			escaped = b == '\\'
+			inString = b != '"'
+		case b == '"':
+			inString = true
+		case b == '{' || b == '[' || b == '(':
+			depth++
+			opened = true
+		case b == '}' || b == ']' || b == ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced %q", b)
+			}
+		case !named && !opened:
+			return nil, fmt.Errorf("invalid character %q at start of value", b)
+		}
+		if opened && depth == 0 {
+			return buf, nil
+		}
+	}
+} //따옴표 밖의 괄호 짝이 맞을 때까지 읽음(named일 시 앞의 메시지명 허용)
+
+func splitRLP(r *bufio.Reader, limit int) ([]byte, error) {
+	head, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	hdr := 1
+	if b := head[0]; b >= 0xb8 && b < 0xc0 || b >= 0xf8 {
+		hdr += int(b&0x07) + 1 //길이의 길이(0xb8/0xf8 = 1바이트)
+	}
+	head, err = r.Peek(hdr)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	_, size, ok := rlpHeader(head)
+	if !ok {
+		return nil, fmt.Errorf("invalid rlp header %x", head)
+	}
+	if size > uint64(limit) {
+		return nil, tooLarge(limit+1, limit)
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return buf, nil
+} //RLP 항목(문자열 또는 list) 하나, header의 길이로 경계 판별
+
+func rlpHeader(b []byte) (int, uint64, bool) {
+	if len(b) == 0 {
+		return 0, 0, false
+	}
+	var hdr, n int
+	switch c := b[0]; {
+	case c < 0x80: //단일 바이트
+		return 1, 1, true
+	case c < 0xb8: //짧은 문자열
+		return 1, uint64(1 + c - 0x80), true
+	case c < 0xc0: //긴 문자열
+		n = int(c - 0xb7)
+	case c < 0xf8: //짧은 list
+		return 1, uint64(1 + c - 0xc0), true
+	default: //긴 list
+		n = int(c - 0xf7)
+	}
+	hdr = 1 + n
+	if len(b) < hdr || b[1] == 0 { //길이 부족 또는 비정규 길이
+		return 0, 0, false
+	}
+	var size uint64
+	for _, x := range b[1:hdr] {
+		size = size<<8 | uint64(x)
+	}
+	if size < 56 || size > 1<<62 {
+		return 0, 0, false
+	}
+	return hdr, uint64(hdr) + size, true
+} //RLP header 길이와 header 포함 전체 크기
+
+func splitUvarint(r *bufio.Reader, limit int) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, unexpectedEOF(err)
+	}
+	if n > uint64(limit) {
+		return nil, tooLarge(limit+1, limit)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return buf, nil
+} //uvarint 길이 prefix로 구분된 메시지(protobuf delimited, prefix 제외)
+
+func splitBCS(r *bufio.Reader, limit int) ([]byte, error) {
+	var prefix []byte
+	var n uint64
+	for shift := 0; ; shift += 7 {
+		b, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(prefix) == 0 {
+				return nil, io.EOF
+			}
+			return nil, unexpectedEOF(err)
+		}
+		prefix = append(prefix, b)
+		if shift > 63 {
+			return nil, fmt.Errorf("bcs length overflows uleb128")
+		}
+		n |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			break
+		}
+	}
+	if n > uint64(limit) || len(prefix)+int(n) > limit { //limit은 prefix 포함(RLP header와 같음)
+		return nil, tooLarge(limit+1, limit)
+	}
+	buf := make([]byte, len(prefix)+int(n))
+	copy(buf, prefix)
+	if _, err := io.ReadFull(r, buf[len(prefix):]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return buf, nil
+} //BCS 바이트열(uleb128 길이 + JSON) 하나, prefix 포함
+
+func splitMsgPack(r *bufio.Reader, limit int) ([]byte, error) {
+	var buf []byte
+	read := func(n int) ([]byte, error) {
+		if err := tooLarge(len(buf)+n, limit); err != nil {
+			return nil, err
+		}
+		start := len(buf)
+		buf = append(buf, make([]byte, n)...)
+		if _, err := io.ReadFull(r, buf[start:]); err != nil {
+			if err == io.EOF && start == 0 {
+				return nil, io.EOF
+			}
+			return nil, unexpectedEOF(err)
+		}
+		return buf[start:], nil
+	}
+	for pending := uint64(1); pending > 0; pending-- {
+		h, err := read(1)
+		if err != nil {
+			return nil, err
+		}
+		lenBytes, extra, children, n, err := msgpackHeader(h[0])
+		if err != nil {
+			return nil, err
+		}
+		if lenBytes > 0 {
+			lb, err := read(lenBytes)
+			if err != nil {
+				return nil, err
+			}
+			for _, x := range lb {
+				n = n<<8 | uint64(x)
+			}
+		}
+		switch children { //n: 길이 필드 또는 header에 든 길이/원소 수
+		case 1: //배열 원소 n개
+			pending += n
+		case 2: //map key/value n쌍
+			pending += 2 * n
+		default: //payload 바이트
+			if n+uint64(extra) > uint64(limit) {
+				return nil, tooLarge(limit+1, limit)
+			}
+			if _, err := read(int(n) + extra); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return buf, nil
+} //MessagePack 값 하나(중첩 포함), header만 읽어 경계 판별하므로 길이 폭탄에도 limit 이상 할당 안 함
+
+func msgpackHeader(b byte) (lenBytes, extra, children int, count uint64, err error) {
+	switch {
+	case b <= 0x7f || b >= 0xe0 || b == 0xc0 || b == 0xc2 || b == 0xc3: //fixint, nil, bool
+		return 0, 0, 0, 0, nil
+	case b <= 0x8f: //fixmap
+		return 0, 0, 2, uint64(b & 0x0f), nil
+	case b <= 0x9f: //fixarray
+		return 0, 0, 1, uint64(b & 0x0f), nil
+	case b <= 0xbf: //fixstr
+		return 0, 0, 0, uint64(b & 0x1f), nil
+	}
+	switch b {
+	case 0xc4, 0xd9: //bin8, str8
+		return 1, 0, 0, 0, nil
+	case 0xc5, 0xda: //bin16, str16
+		return 2, 0, 0, 0, nil
+	case 0xc6, 0xdb: //bin32, str32
+		return 4, 0, 0, 0, nil
+	case 0xc7: //ext8
+		return 1, 1, 0, 0, nil
+	case 0xc8: //ext16
+		return 2, 1, 0, 0, nil
+	case 0xc9: //ext32
+		return 4, 1, 0, 0, nil
+	case 0xca, 0xd2, 0xce: //float32, int32, uint32
+		return 0, 4, 0, 0, nil
+	case 0xcb, 0xd3, 0xcf: //float64, int64, uint64
+		return 0, 8, 0, 0, nil
+	case 0xcc, 0xd0: //uint8, int8
+		return 0, 1, 0, 0, nil
+	case 0xcd, 0xd1: //uint16, int16
+		return 0, 2, 0, 0, nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8: //fixext 1/2/4/8/16(type 1바이트 + data)
+		return 0, 1 + 1<<(b-0xd4), 0, 0, nil
+	case 0xdc: //array16
+		return 2, 0, 1, 0, nil
+	case 0xdd: //array32
+		return 4, 0, 1, 0, nil
+	case 0xde: //map16
+		return 2, 0, 2, 0, nil
+	case 0xdf: //map32
+		return 4, 0, 2, 0, nil
+	}
+	return 0, 0, 0, 0, fmt.Errorf("invalid msgpack code 0x%02x", b)
+} //MessagePack header: 길이 필드 바이트 수, 고정 payload 크기, 자식 종류(0 없음, 1 배열, 2 map), header에 든 길이/원소 수
+
+type Encoder struct {
+	w      io.Writer
+	opts   SerializeOptions
+	framer framing.Framer //설정 시 포맷 framing 대신 사용
+} //io.Writer에 메시지를 하나씩 직렬화
+
+func NewEncoder(w io.Writer, opts SerializeOptions) *Encoder {
+	return &Encoder{w: w, opts: opts}
+} //opts.Format의 framing으로 w에 쓰는 Encoder 생성
+
+func (e *Encoder) SetFramer(f framing.Framer) {
+	e.framer = f
+} //포맷 고유 framing 대신 wire envelope로 감싸 씀
+
+func (e *Encoder) Encode(am *abstraction.AbstractMessage) error {
+	return e.EncodeFrame(am, framing.Frame{})
+} //메시지 하나를 직렬화하여 framing과 함께 씀
+
+func (e *Encoder) EncodeFrame(am *abstraction.AbstractMessage, meta framing.Frame) error {
+	if e.framer == nil && e.opts.Compression != CompressionAuto && e.opts.Compression != CompressionNone {
+		return fmt.Errorf("%w: %s stream without a framer", ErrUnsupportedFormat, e.opts.Compression)
+	} //압축된 payload는 포맷 고유 framing으로 경계를 알 수 없음
+	data, err := Serialize(am, e.opts)
+	if err != nil {
+		return err
+	}
+	if e.framer != nil {
+		meta.Payload = data
+		return e.framer.WriteFrame(e.w, meta)
+	}
+	switch e.opts.Format {
+	case FormatProtobuf: //uvarint 길이 prefix(delimited)
+		data = append(binary.AppendUvarint(nil, uint64(len(data))), data...)
+	case FormatRLP, FormatMsgPack, FormatBCS: //값 자체에 길이가 있음
+	default: //JSON, generic: 줄 단위
+		data = append(data, '\n')
+	}
+	_, err = e.w.Write(data)
+	return err
+} //메시지 하나를 meta의 코드/peer와 함께 씀(코드/peer는 SetFramer의 framer만 사용)
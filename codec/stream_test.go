@@ -0,0 +1,243 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"codec/abstraction"
+)
+
+var streamFormats = []Format{FormatGeneric, FormatJSON, FormatProtobuf, FormatRLP, FormatMsgPack, FormatBCS}
+
+var streamBad = map[Format][]byte{
+	FormatGeneric:  []byte("Commit(=1)\n"),
+	FormatJSON:     []byte("[1,2]\n"),
+	FormatProtobuf: {0x01, 0xff}, //길이 1 + 잘못된 wire 바이트
+	FormatRLP:      {0xc1, 0x01},
+	FormatMsgPack:  {0x01},
+	FormatBCS:      []byte("\x02[]"),
+} //경계는 맞지만 Parse가 거부하는 메시지
+
+var streamEqual = abstraction.EqualOptions{IgnoreRawPayload: true} //map 순서가 달라 직렬화 바이트는 매번 다를 수 있음
+
+type streamMessage struct {
+	am   *abstraction.AbstractMessage
+	data []byte //Serialize 결과(framing 제외)
+	end  int64  //스트림에서 메시지가 끝나는 위치
+} //Encoder로 쓴 메시지와 그 위치
+
+func encodeStream(t *testing.T, format Format) ([]byte, []streamMessage) {
+	t.Helper()
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, fuzzSerializeOptions(format))
+	var msgs []streamMessage
+	for _, am := range fuzzFixtures() {
+		data, err := Serialize(am, fuzzSerializeOptions(format))
+		if err != nil {
+			continue //대상 포맷에 담을 수 없는 fixture
+		}
+		if err := enc.Encode(am); err != nil {
+			t.Fatalf("%s: Encode: %v", format, err)
+		}
+		end := int64(buf.Len())
+		if format == FormatJSON || format == FormatGeneric {
+			end-- //줄바꿈은 다음 Decode가 건너뜀
+		}
+		msgs = append(msgs, streamMessage{am, data, end})
+	}
+	if len(msgs) < 2 {
+		t.Fatalf("%s: only %d fixtures encode", format, len(msgs))
+	}
+	return buf.Bytes(), msgs
+} //fixture를 Encoder로 이어 쓴 스트림
+
+func TestStreamRoundTrip(t *testing.T) {
+	fuzzSetup(t)
+	for _, format := range streamFormats {
+		stream, msgs := encodeStream(t, format)
+		opts := []ParseOptions{fuzzParseOptions(format)}
+		if format == FormatJSON || format == FormatGeneric {
+			opts = append(opts, fuzzParseOptions(FormatAuto)) //첫 글자로 판별
+		}
+		for _, popts := range opts {
+			dec := NewDecoder(bytes.NewReader(stream), popts)
+			for i, m := range msgs {
+				got, err := dec.Decode()
+				if err != nil {
+					t.Fatalf("%s/%s message %d: %v", format, popts.Format, i, err)
+				}
+				want, err := Parse(m.data, fuzzParseOptions(format))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := got.Diff(want, streamEqual); len(diff) > 0 {
+					t.Errorf("%s/%s message %d differs from Parse: %s", format, popts.Format, i, diff)
+				}
+				if again, err := Parse(dec.Frame().Payload, fuzzParseOptions(format)); err != nil || !identicalMessage(got, again) {
+					t.Errorf("%s message %d: Frame().Payload does not parse to the decoded message (%v)", format, i, err)
+				}
+			}
+			if _, err := dec.Decode(); err != io.EOF {
+				t.Errorf("%s/%s: Decode after the last message = %v, want io.EOF", format, popts.Format, err)
+			}
+		}
+	}
+} //포맷별로 Encoder가 쓴 메시지를 Decoder가 같은 순서/값으로 읽음
+
+func TestStreamInputOffset(t *testing.T) {
+	fuzzSetup(t)
+	for _, format := range streamFormats {
+		stream, msgs := encodeStream(t, format)
+		dec := NewDecoder(bytes.NewReader(stream), fuzzParseOptions(format))
+		if got := dec.InputOffset(); got != 0 {
+			t.Errorf("%s: InputOffset before Decode = %d", format, got)
+		}
+		for i, m := range msgs {
+			if _, err := dec.Decode(); err != nil {
+				t.Fatalf("%s message %d: %v", format, i, err)
+			}
+			if got := dec.InputOffset(); got != m.end {
+				t.Errorf("%s message %d: InputOffset = %d, want %d", format, i, got, m.end)
+			}
+		}
+	}
+} //InputOffset은 읽은 메시지의 끝(bufio가 미리 읽은 바이트 제외)
+
+func TestStreamTruncated(t *testing.T) {
+	fuzzSetup(t)
+	for _, format := range streamFormats {
+		stream, msgs := encodeStream(t, format)
+		start := msgs[len(msgs)-2].end //이전 메시지 끝(그 뒤 줄바꿈도 이번 Decode가 읽음)
+		cut := stream[:msgs[len(msgs)-1].end-1]
+		dec := NewDecoder(bytes.NewReader(cut), fuzzParseOptions(format))
+		for range len(msgs) - 1 {
+			if _, err := dec.Decode(); err != nil {
+				t.Fatalf("%s: %v before the truncated message", format, err)
+			}
+		}
+		_, err := dec.Decode()
+		var pe *ParseError
+		if !errors.Is(err, ErrTruncatedInput) || !errors.As(err, &pe) {
+			t.Fatalf("%s: truncated final message = %v, want ParseError wrapping ErrTruncatedInput", format, err)
+		}
+		if pe.Offset != start {
+			t.Errorf("%s: ParseError.Offset = %d, want %d (start of the truncated message)", format, pe.Offset, start)
+		}
+		if _, again := dec.Decode(); again != err {
+			t.Errorf("%s: Decode after a framing error = %v, want the same error", format, again)
+		}
+	}
+} //마지막 메시지가 잘린 스트림은 그 메시지 위치의 ErrTruncatedInput(이후에도 같은 에러)
+
+func TestStreamMaxMessageSize(t *testing.T) {
+	fuzzSetup(t)
+	for _, format := range streamFormats {
+		stream, msgs := encodeStream(t, format)
+		first := stream[:msgs[0].end] //limit은 protobuf 길이 prefix를 뺀 payload 기준
+		first = first[len(first)-len(msgs[0].data):]
+		dec := NewDecoder(bytes.NewReader(stream), fuzzParseOptions(format))
+		dec.SetMaxMessageSize(len(first))
+		if _, err := dec.Decode(); err != nil {
+			t.Fatalf("%s: message of exactly the limit: %v", format, err)
+		}
+		dec = NewDecoder(bytes.NewReader(stream), fuzzParseOptions(format))
+		dec.SetMaxMessageSize(len(first) - 1)
+		_, err := dec.Decode()
+		var pe *ParseError
+		if !errors.Is(err, ErrMessageTooLarge) || !errors.As(err, &pe) || pe.Offset != 0 {
+			t.Errorf("%s: message one byte over the limit = %v, want ParseError at 0 wrapping ErrMessageTooLarge", format, err)
+		}
+		popts := fuzzParseOptions(format)
+		popts.Limits.MaxBytes = len(first) - 1 //SetMaxMessageSize 대신 Limits.MaxBytes
+		if _, err := NewDecoder(bytes.NewReader(stream), popts).Decode(); !errors.Is(err, ErrMessageTooLarge) {
+			t.Errorf("%s: Limits.MaxBytes over the limit = %v", format, err)
+		}
+	}
+	huge := map[Format][]byte{ //길이 필드만 큰 메시지(본문을 할당하지 않고 거부)
+		FormatProtobuf: {0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01},
+		FormatRLP:      {0xfb, 0x01, 0, 0, 0, 0, 0},
+		FormatMsgPack:  {0xc6, 0x7f, 0xff, 0xff, 0xff},
+		FormatBCS:      {0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01},
+	}
+	for format, data := range huge {
+		dec := NewDecoder(bytes.NewReader(data), fuzzParseOptions(format))
+		dec.SetMaxMessageSize(1 << 10)
+		if _, err := dec.Decode(); !errors.Is(err, ErrMessageTooLarge) {
+			t.Errorf("%s: length header beyond the limit = %v, want ErrMessageTooLarge", format, err)
+		}
+	}
+} //SetMaxMessageSize/Limits.MaxBytes와 같은 크기는 통과, 넘으면 ErrMessageTooLarge
+
+func TestStreamKeepsGoingAfterParseError(t *testing.T) {
+	fuzzSetup(t)
+	for _, format := range streamFormats {
+		_, msgs := encodeStream(t, format)
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf, fuzzSerializeOptions(format))
+		if err := enc.Encode(msgs[0].am); err != nil {
+			t.Fatal(err)
+		}
+		buf.Write(streamBad[format])
+		if err := enc.Encode(msgs[1].am); err != nil {
+			t.Fatal(err)
+		}
+		dec := NewDecoder(&buf, fuzzParseOptions(format))
+		if _, err := dec.Decode(); err != nil {
+			t.Fatalf("%s: first message: %v", format, err)
+		}
+		if _, err := dec.Decode(); err == nil {
+			t.Errorf("%s: malformed message %q decoded without error", format, streamBad[format])
+		}
+		got, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("%s: message after a parse error: %v", format, err)
+		}
+		want, _ := Parse(msgs[1].data, fuzzParseOptions(format))
+		if diff := got.Diff(want, streamEqual); len(diff) > 0 {
+			t.Errorf("%s: message after a parse error differs: %s", format, diff)
+		}
+		if _, err := dec.Decode(); err != io.EOF {
+			t.Errorf("%s: end of stream = %v, want io.EOF", format, err)
+		}
+	}
+	dec := NewDecoder(bytes.NewReader([]byte("{\"type\":\"Commit\"}\n}{\"type\":\"Commit\"}")), fuzzParseOptions(FormatJSON))
+	if _, err := dec.Decode(); err != nil {
+		t.Fatal(err)
+	}
+	_, err := dec.Decode()
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.Offset != 17 {
+		t.Fatalf("unbalanced '}' = %v, want ParseError at offset 17", err)
+	}
+	if _, again := dec.Decode(); again != err {
+		t.Errorf("Decode after losing the boundary = %v, want the same error", again)
+	}
+} //parsing 에러는 그 메시지만 실패, 경계를 잃는 framing 에러는 이후에도 반환
+
+func TestStreamDecodeContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	dec := NewDecoder(bytes.NewReader([]byte(`{"type":"Commit"}`)), ParseOptions{Format: FormatJSON})
+	if _, err := dec.DecodeContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("DecodeContext with a cancelled context = %v", err)
+	}
+	if dec.InputOffset() != 0 {
+		t.Errorf("cancelled DecodeContext consumed %d bytes", dec.InputOffset())
+	}
+	if am, err := dec.DecodeContext(context.Background()); err != nil || am.Type != abstraction.MsgTypeCommit {
+		t.Errorf("DecodeContext after cancel = %v, %v", am, err)
+	}
+} //취소된 context는 읽기 전에 거부(메시지를 잃지 않음)
+
+func TestStreamAutoBinary(t *testing.T) {
+	_, err := NewDecoder(bytes.NewReader([]byte{0x01, 0x02}), ParseOptions{Format: FormatAuto}).Decode()
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("auto format on a binary stream = %v, want ErrUnsupportedFormat", err)
+	}
+	if _, err := NewDecoder(bytes.NewReader(nil), ParseOptions{}).Decode(); err != io.EOF {
+		t.Errorf("empty stream = %v, want io.EOF", err)
+	}
+} //auto는 텍스트 스트림만 판별
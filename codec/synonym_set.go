@@ -0,0 +1,420 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+type SynonymSet struct {
+	mu       sync.RWMutex
+	phases   map[string]string         //원본 메시지명 -> 표준 타입명
+	fields   map[string]string         //원본 필드명 -> 표준 필드명
+	paths    map[string]string         //중첩 JSON 경로 규칙 -> 표준 필드명(예: header.parent_hash)
+	priority map[string][]string       //표준 필드명 -> 한 메시지에 유의어가 여럿일 때 채택할 원본 key 순서
+	folded   atomic.Pointer[foldIndex] //대소문자/구분자 정규화 index(필요 시 생성, 사전 수정 시 nil)
+} //메시지명/필드명 유의어 사전, 여러 layer를 겹쳐 구성 가능
+
+type foldIndex struct {
+	exactPhases map[string]string   //원본 메시지명 -> 표준 타입명(사전 사본)
+	exactFields map[string]string   //원본 필드명 -> 표준 필드명(사전 사본)
+	phases      map[string][]string //정규화된 이름 -> 표준 타입명 후보(정렬)
+	fields      map[string][]string //정규화된 이름 -> 표준 필드명 후보(정렬)
+	paths       *pathRules          //컴파일된 경로 규칙
+	names       map[string]string   //사전에 있는 원본 이름(바이트에서 읽은 key를 할당 없이 같은 문자열로)
+} //사전 사본과 FoldName 기준 index(만든 뒤 수정하지 않으므로 잠금 없이 조회)
+
+type SynonymCollision struct {
+	Kind       string   //"phase" 또는 "field"
+	Folded     string   //정규화된 이름
+	Names      []string //충돌하는 원본 이름들
+	Candidates []string //서로 다른 표준 이름들
+} //정규화 후 같은 이름이 서로 다른 표준 이름으로 매핑되는 경우
+
+type AmbiguousSynonymError struct {
+	Kind       string   //"phase" 또는 "field"
+	Name       string   //입력 이름
+	Candidates []string //가능한 표준 이름들
+} //정규화 후 여러 표준 이름과 일치하는 입력
+
+func (e *AmbiguousSynonymError) Error() string {
+	return fmt.Sprintf("ambiguous %s name %q: matches %s", e.Kind, e.Name, strings.Join(e.Candidates, ", "))
+} //에러 메시지
+
+func (e *AmbiguousSynonymError) Is(target error) bool {
+	return target == ErrAmbiguousSynonym
+} //errors.Is(err, ErrAmbiguousSynonym) 지원
+
+type synonymFile struct {
+	Phases   map[string]string   `json:"phases,omitempty" yaml:"phases,omitempty"`
+	Fields   map[string]string   `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Paths    map[string]string   `json:"paths,omitempty" yaml:"paths,omitempty"`
+	Priority map[string][]string `json:"priority,omitempty" yaml:"priority,omitempty"`
+} //JSON/YAML 파일 구조
+
+var builtinSynonyms = &SynonymSet{
+	phases:   copyStringMap(PhaseSynonyms),
+	fields:   copyStringMap(FieldSynonyms),
+	paths:    copyStringMap(PathSynonyms),
+	priority: map[string][]string{},
+} //package 초기화 시점의 전역 사전 사본으로 만든 기본 set(수정하지 않음)
+
+func NewSynonymSet() *SynonymSet {
+	return &SynonymSet{phases: map[string]string{}, fields: map[string]string{}, paths: map[string]string{}, priority: map[string][]string{}}
+} //빈 SynonymSet 생성
+
+func DefaultSynonyms() *SynonymSet {
+	return builtinSynonyms.Clone()
+} //내장 사전(PhaseSynonyms/FieldSynonyms/PathSynonyms)의 독립 사본, 수정해도 내장 사전과 전역 map은 바뀌지 않음
+
+func (s *SynonymSet) Phase(name string) (string, bool) {
+	v, ok, _ := s.lookup("phase", name, false)
+	return v, ok
+} //원본 메시지명 -> 표준 타입명(정확히 일치 우선, 없을 시 정규화 비교, 모호할 시 false)
+
+func (s *SynonymSet) Field(name string) (string, bool) {
+	v, ok, _ := s.lookup("field", name, false)
+	return v, ok
+} //원본 필드명 -> 표준 필드명(정확히 일치 우선, 없을 시 정규화 비교, 모호할 시 false)
+
+func (s *SynonymSet) lookup(kind, name string, exact bool) (string, bool, error) {
+	return s.index().lookup(kind, name, exact)
+} //kind("phase"/"field") 사전에서 name 조회, 정규화 결과가 모호할 시 AmbiguousSynonymError
+
+func (idx *foldIndex) lookup(kind, name string, exact bool) (string, bool, error) {
+	dict, folded := idx.exactFields, idx.fields
+	if kind == "phase" {
+		dict, folded = idx.exactPhases, idx.phases
+	}
+	if v, ok := dict[name]; ok { //정확히 일치
+		return v, true, nil
+	}
+	if exact {
+		return "", false, nil
+	}
+	var buf [64]byte
+	cands := folded[string(appendFold(buf[:0], name))] //짧은 이름은 stack 버퍼에서 정규화(할당 없음)
+	switch len(cands) {
+	case 0:
+		return "", false, nil
+	case 1:
+		return cands[0], true, nil
+	}
+	return "", false, &AmbiguousSynonymError{Kind: kind, Name: name, Candidates: cands}
+} //index를 만든 시점의 사전에서 조회(잠금 없음)
+
+func (s *SynonymSet) index() *foldIndex {
+	if idx := s.folded.Load(); idx != nil { //parsing마다 잠그지 않음
+		return idx
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if idx := s.folded.Load(); idx != nil { //다른 goroutine이 먼저 생성
+		return idx
+	}
+	idx := &foldIndex{
+		exactPhases: copyStringMap(s.phases),
+		exactFields: copyStringMap(s.fields),
+		phases:      foldDict(s.phases),
+		fields:      foldDict(s.fields),
+		paths:       compilePathRules(s.paths),
+		names:       internNames(s.phases, s.fields),
+	}
+	s.folded.Store(idx)
+	return idx
+} //정규화 index 반환, 없거나 index 생성 후 사전이 수정되었을 시 재생성
+
+func internNames(dicts ...map[string]string) map[string]string {
+	out := map[string]string{"type": "type"}
+	for _, dict := range dicts {
+		for name := range dict {
+			out[name] = name
+		}
+	}
+	return out
+} //사전의 원본 이름 집합(이름 -> 같은 이름)
+
+func foldDict(dict map[string]string) map[string][]string {
+	sets := map[string]map[string]bool{}
+	for name, canon := range dict {
+		f := FoldName(name)
+		if sets[f] == nil {
+			sets[f] = map[string]bool{}
+		}
+		sets[f][canon] = true
+	}
+	out := make(map[string][]string, len(sets))
+	for f, set := range sets {
+		out[f] = sortedMapKeys(set)
+	}
+	return out
+} //원본 이름 -> 표준 이름 사전을 정규화된 이름 -> 표준 이름 후보로 변환
+
+func (s *SynonymSet) Collisions() []SynonymCollision {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []SynonymCollision
+	for _, d := range []struct {
+		kind string
+		dict map[string]string
+	}{{"phase", s.phases}, {"field", s.fields}} {
+		names := map[string][]string{}
+		cands := map[string]map[string]bool{}
+		for name, canon := range d.dict {
+			f := FoldName(name)
+			names[f] = append(names[f], name)
+			if cands[f] == nil {
+				cands[f] = map[string]bool{}
+			}
+			cands[f][canon] = true
+		}
+		for f, set := range cands {
+			if len(set) < 2 {
+				continue
+			}
+			ns := names[f]
+			sort.Strings(ns)
+			out = append(out, SynonymCollision{Kind: d.kind, Folded: f, Names: ns, Candidates: sortedMapKeys(set)})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		return out[i].Folded < out[j].Folded
+	})
+	return out
+} //정규화 후 서로 다른 표준 이름으로 갈리는 항목 목록
+
+func FoldName(name string) string {
+	return string(appendFold(nil, name))
+} //blockHash, BlockHash, block-hash, BLOCK_HASH를 모두 blockhash로 정규화
+
+func appendFold(dst []byte, name string) []byte {
+	for _, r := range name {
+		switch {
+		case r == '_' || r == '-' || r == '.' || unicode.IsSpace(r): //snake/kebab/공백 구분자 제거
+		case 'A' <= r && r <= 'Z':
+			dst = append(dst, byte(r)+'a'-'A')
+		case r < utf8.RuneSelf:
+			dst = append(dst, byte(r))
+		default:
+			dst = utf8.AppendRune(dst, unicode.ToLower(r)) //camel/대문자 -> 소문자
+		}
+	}
+	return dst
+} //FoldName 결과를 dst 뒤에 추가
+
+func (s *SynonymSet) SetPhase(name, canonical string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.phases[name] = canonical
+	s.folded.Store(nil) //다음 조회 시 index 재생성
+} //메시지명 유의어 추가/변경
+
+func (s *SynonymSet) SetField(name, canonical string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fields[name] = canonical
+	s.folded.Store(nil) //다음 조회 시 index 재생성
+} //필드명 유의어 추가/변경
+
+func (s *SynonymSet) Phases() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return copyStringMap(s.phases)
+} //메시지명 유의어 사본
+
+func (s *SynonymSet) Fields() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return copyStringMap(s.fields)
+} //필드명 유의어 사본
+
+func (s *SynonymSet) Paths() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return copyStringMap(s.paths)
+} //중첩 경로 규칙 사본
+
+func (s *SynonymSet) SetPath(pattern, canonical string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paths[pattern] = canonical
+	s.folded.Store(nil) //다음 조회 시 index 재생성
+} //중첩 JSON 경로 규칙 추가/변경('.'로 key 구분, '*'는 임의 key, '[*]'는 임의 배열 원소)
+
+func (s *SynonymSet) Priority(canonical string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.priority[canonical]...)
+} //표준 필드의 원본 key 우선순위 목록
+
+func (s *SynonymSet) SetPriority(canonical string, names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.priority[canonical] = append([]string(nil), names...)
+	s.folded.Store(nil) //다음 조회 시 index 재생성
+} //한 메시지에 같은 필드의 유의어가 여럿일 때 채택할 원본 key 순서 지정
+
+func (s *SynonymSet) priorities() map[string][]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string][]string, len(s.priority))
+	for k, v := range s.priority {
+		out[k] = append([]string(nil), v...)
+	}
+	return out
+} //우선순위 설정 사본
+
+func (s *SynonymSet) Clone() *SynonymSet {
+	return &SynonymSet{phases: s.Phases(), fields: s.Fields(), paths: s.Paths(), priority: s.priorities()}
+} //독립적으로 수정 가능한 사본 생성
+
+func (s *SynonymSet) Layer(overlays ...*SynonymSet) *SynonymSet {
+	out := s.Clone()
+	for _, o := range overlays {
+		if o == nil {
+			continue
+		}
+		for k, v := range o.Phases() { //뒤 layer가 앞 layer를 덮어씀
+			out.phases[k] = v
+		}
+		for k, v := range o.Fields() {
+			out.fields[k] = v
+		}
+		for k, v := range o.Paths() {
+			out.paths[k] = v
+		}
+		for k, v := range o.priorities() { //우선순위는 필드 단위로 덮어씀
+			out.priority[k] = v
+		}
+	}
+	return out
+} //s 위에 overlays를 순서대로 겹친 새 set 반환(s는 변경되지 않음)
+
+func ParseSynonymSet(data []byte, format string) (*SynonymSet, error) {
+	var f synonymFile
+	switch strings.ToLower(format) {
+	case "json":
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("synonym set json: %w", err)
+		}
+	case "yaml", "yml":
+		if err := yaml.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("synonym set yaml: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("synonym set: %w: %s", ErrUnsupportedFormat, format)
+	}
+	s := NewSynonymSet()
+	for k, v := range f.Phases {
+		s.phases[k] = v
+	}
+	for k, v := range f.Fields {
+		s.fields[k] = v
+	}
+	for k, v := range f.Paths {
+		s.paths[k] = v
+	}
+	for k, v := range f.Priority {
+		s.priority[k] = v
+	}
+	return s, nil
+} //JSON/YAML 바이트를 SynonymSet으로 변환({phases, fields, paths, priority})
+
+func LoadSynonymSet(path string) (*SynonymSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := ParseSynonymSet(data, strings.TrimPrefix(filepath.Ext(path), "."))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+} //.json/.yaml/.yml 파일에서 SynonymSet 로딩
+
+func LoadSynonymLayers(paths ...string) (*SynonymSet, error) {
+	layers := make([]*SynonymSet, 0, len(paths))
+	for _, p := range paths {
+		s, err := LoadSynonymSet(p)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, s)
+	}
+	return builtinSynonyms.Layer(layers...), nil
+} //내장 사전 위에 파일들을 순서대로 겹침(예: 내장 -> 팀 -> 실행별)
+
+func (s *SynonymSet) MarshalJSON() ([]byte, error) {
+	return json.MarshalIndent(s.file(), "", "  ")
+} //JSON으로 내보내기
+
+func (s *SynonymSet) MarshalYAML() (interface{}, error) {
+	return s.file(), nil
+} //YAML로 내보내기
+
+func (s *SynonymSet) WriteFile(path string) error {
+	var (
+		data []byte
+		err  error
+	)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		data, err = s.MarshalJSON()
+	case ".yaml", ".yml":
+		data, err = yaml.Marshal(s)
+	default:
+		return fmt.Errorf("synonym set: %w: %s", ErrUnsupportedFormat, ext)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+} //확장자에 맞는 포맷으로 파일 저장
+
+func (s *SynonymSet) file() synonymFile {
+	return synonymFile{Phases: s.Phases(), Fields: s.Fields(), Paths: s.Paths(), Priority: s.priorities()}
+} //파일 구조로 변환
+
+func copyStringMap(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+} //map[string]string 사본
+
+func (o ParseOptions) resolve(kind, name string) (string, bool, error) {
+	return o.resolveIn(o.synonyms().index(), kind, name)
+} //옵션에 따라 메시지명/필드명을 표준 이름으로 변환
+
+func (o ParseOptions) resolveIn(idx *foldIndex, kind, name string) (string, bool, error) {
+	canon, ok, err := idx.lookup(kind, name, o.ExactSynonyms)
+	if err != nil && !o.StrictSynonyms { //모호한 이름은 매핑하지 않고 Extras로 보존, callback으로 알림
+		var ae *AmbiguousSynonymError
+		if o.OnAmbiguousSynonym != nil && errors.As(err, &ae) {
+			o.OnAmbiguousSynonym(ae)
+		}
+		return "", false, nil
+	}
+	return canon, ok, err
+} //resolve(여러 이름을 조회할 때 index를 한 번만 가져옴)
+
+func (o ParseOptions) synonyms() *SynonymSet {
+	if o.Synonyms != nil {
+		return o.Synonyms
+	}
+	return builtinSynonyms
+} //parsing에 사용할 SynonymSet
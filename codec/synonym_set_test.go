@@ -0,0 +1,151 @@
+package codec
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"codec/abstraction"
+)
+
+func TestDefaultSynonymsIsCopy(t *testing.T) {
+	const alias = "zzz_alias"
+	s := DefaultSynonyms()
+	s.SetField(alias, "Height")
+	if _, ok := FieldSynonyms[alias]; ok {
+		t.Fatal("DefaultSynonyms().SetField changed FieldSynonyms")
+	}
+	if _, ok := DefaultSynonyms().Field(alias); ok {
+		t.Fatal("DefaultSynonyms().SetField changed the built-in set")
+	}
+	am, err := Parse([]byte(`{"type":"Commit","zzz_alias":7}`), ParseOptions{Format: FormatJSON})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if am.Height != nil {
+		t.Fatalf("default parse used the edited copy: Height = %v", am.Height)
+	}
+	am, err = Parse([]byte(`{"type":"Commit","zzz_alias":7}`), ParseOptions{Format: FormatJSON, Synonyms: s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if am.Height == nil || am.Height.Int64() != 7 {
+		t.Fatalf("edited copy not applied: Height = %v", am.Height)
+	}
+	FieldSynonyms[alias] = "Height" //deprecated 전역 map 수정은 반영되지 않음
+	defer delete(FieldSynonyms, alias)
+	if am, err := Parse([]byte(`{"type":"Commit","zzz_alias":7}`), ParseOptions{Format: FormatJSON}); err != nil || am.Height != nil {
+		t.Fatalf("editing FieldSynonyms changed parsing: %+v, %v", am, err)
+	}
+} //DefaultSynonyms는 내장 사전/전역 map과 독립된 사본
+
+func TestSynonymSetRetarget(t *testing.T) {
+	s := NewSynonymSet()
+	s.SetField("Foo_Bar", "Height")
+	opts := ParseOptions{Format: FormatJSON, Synonyms: s}
+	am, err := Parse([]byte(`{"type":"Commit","foobar":7}`), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if am.Height == nil || am.Height.Int64() != 7 {
+		t.Fatalf("Height = %v, want 7", am.Height)
+	}
+	s.SetField("Foo_Bar", "Round") //사전 크기는 그대로
+	am, err = Parse([]byte(`{"type":"Commit","foobar":7}`), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if am.Height != nil || am.Round == nil || am.Round.Int64() != 7 {
+		t.Fatalf("after retarget Height = %v, Round = %v, want nil, 7", am.Height, am.Round)
+	}
+} //같은 key의 대상만 바꿔도 정규화 index 갱신
+
+func TestAmbiguousSynonymReported(t *testing.T) {
+	s := NewSynonymSet()
+	s.SetField("foo_bar", "Height")
+	s.SetField("FooBar", "Round")
+	var got []*AmbiguousSynonymError
+	opts := ParseOptions{Format: FormatJSON, Synonyms: s, OnAmbiguousSynonym: func(e *AmbiguousSynonymError) { got = append(got, e) }}
+	am, err := Parse([]byte(`{"type":"Commit","FOO-BAR":7}`), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if am.Height != nil || am.Round != nil || am.Extras["FOO-BAR"].Int == nil {
+		t.Fatalf("ambiguous key not kept in Extras: %+v", am)
+	}
+	if len(got) != 1 || got[0].Name != "FOO-BAR" || len(got[0].Candidates) != 2 {
+		t.Fatalf("OnAmbiguousSynonym got %v, want one report for FOO-BAR", got)
+	}
+	opts.StrictSynonyms = true
+	if _, err := Parse([]byte(`{"type":"Commit","FOO-BAR":7}`), opts); !errors.Is(err, ErrAmbiguousSynonym) {
+		t.Fatalf("strict err = %v, want ErrAmbiguousSynonym", err)
+	}
+} //모호한 이름은 Extras로 보존하고 callback으로 보고(Strict일 시 에러)
+
+func TestImplementationSynonyms(t *testing.T) {
+	cases := []struct {
+		in   string
+		want abstraction.AbstractMessage
+	}{
+		{`{"type":"SIGNED_MSG_TYPE_PREVOTE","height":1}`, abstraction.AbstractMessage{Type: abstraction.MsgTypePrepare, Height: big.NewInt(1)}}, //CometBFT SignedMsgType
+		{`{"type":"SIGNED_MSG_TYPE_PRECOMMIT","height":1}`, abstraction.AbstractMessage{Type: abstraction.MsgTypeCommit, Height: big.NewInt(1)}},
+		{`{"type":"Commit","commit":{"view":2,"seq":9,"digest":"0xab","signature":{"signer":3,"value":"0xcd"}}}`, //SmartBFT oneof
+			abstraction.AbstractMessage{Type: abstraction.MsgTypeCommit, View: big.NewInt(2), Height: big.NewInt(9), BlockHash: "0xab", Validator: "3", Signature: "0xcd"}},
+		{`{"type":"Soft-Vote","r":{"rnd":9,"per":1,"snd":"0x01","prop":{"dig":"0xab","oprop":"0x02"}},"sig":{"s":"0xcd"}}`, //Algorand vote
+			abstraction.AbstractMessage{Type: abstraction.MsgTypePrepare, Height: big.NewInt(9), Round: big.NewInt(1), Validator: "0x01", BlockHash: "0xab", Proposer: "0x02", Signature: "0xcd"}},
+	}
+	for _, tc := range cases {
+		am, err := Parse([]byte(tc.in), ParseOptions{Format: FormatJSON})
+		if err != nil {
+			t.Fatalf("%s: %v", tc.in, err)
+		}
+		got := abstraction.AbstractMessage{Type: am.Type, Height: am.Height, Round: am.Round, View: am.View, BlockHash: am.BlockHash,
+			Proposer: am.Proposer, Validator: am.Validator, Signature: am.Signature}
+		if d := tc.want.Diff(&got, abstraction.EqualOptions{}); len(d) > 0 {
+			t.Errorf("%s:\n%s", tc.in, d)
+		}
+	}
+} //CometBFT enum, SmartBFT oneof, Algorand 축약 key 유의어
+
+func TestSynonymCollisions(t *testing.T) {
+	if got := DefaultSynonyms().Collisions(); len(got) > 0 {
+		t.Errorf("built-in synonyms collide: %+v", got)
+	}
+	s := NewSynonymSet()
+	s.SetField("view", "View")
+	s.SetField("view", "Round") //같은 이름을 다시 설정하면 덮어씀(충돌 아님)
+	s.SetField("seq", "Height")
+	s.SetField("Seq", "Round") //대소문자만 다름
+	s.SetField("SEQ", "Round")
+	s.SetField("block_id", "BlockHash")
+	s.SetField("block-id", "BlockHash") //같은 표준 이름은 충돌 아님
+	s.SetField("blockId", "PrevHash")   //구분자/camel case만 다름
+	s.SetField("sender", "Proposer")
+	s.SetPhase("pre_vote", "Prevote")
+	s.SetPhase("PreVote", "Vote")
+	s.SetPhase("commit", "Commit")
+	want := []SynonymCollision{
+		{Kind: "field", Folded: "blockid", Names: []string{"block-id", "blockId", "block_id"}, Candidates: []string{"BlockHash", "PrevHash"}},
+		{Kind: "field", Folded: "seq", Names: []string{"SEQ", "Seq", "seq"}, Candidates: []string{"Height", "Round"}},
+		{Kind: "phase", Folded: "prevote", Names: []string{"PreVote", "pre_vote"}, Candidates: []string{"Prevote", "Vote"}},
+	}
+	if got := s.Collisions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Collisions =\n %+v\nwant\n %+v", got, want)
+	}
+	issues := LintSynonyms(s)
+	n := 0
+	for _, is := range issues {
+		if is.Rule == "fold-collision" {
+			n++
+		}
+	}
+	if n != len(want) {
+		t.Errorf("LintSynonyms reported %d fold collisions, want %d: %+v", n, len(want), issues)
+	}
+	s.SetField("blockId", "BlockHash")
+	s.SetPhase("PreVote", "Prevote")
+	if got := s.Collisions(); len(got) != 1 || got[0].Folded != "seq" {
+		t.Errorf("Collisions after retargeting = %+v, want only seq", got)
+	}
+} //정규화(대소문자/구분자) 후 같은 이름이 다른 표준 이름으로 갈리는 쌍을 종류/정규화 이름 순으로 보고
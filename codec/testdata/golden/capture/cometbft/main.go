@@ -0,0 +1,137 @@
+// 단일 validator CometBFT 노드를 kvstore 앱으로 실행해 height 2의 proposal/prevote/precommit을
+// consensus WAL에서 그대로 잘라 cometbft_<종류>.pb로 저장(go run . <출력 디렉터리>)
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cometbft/cometbft/abci/example/kvstore"
+	cfg "github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/node"
+	"github.com/cometbft/cometbft/p2p"
+	"github.com/cometbft/cometbft/privval"
+	"github.com/cometbft/cometbft/proxy"
+	"github.com/cometbft/cometbft/types"
+	cmttime "github.com/cometbft/cometbft/types/time"
+	"github.com/cometbft/cometbft/version"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const captureHeight = 2 //genesis 직후가 아닌 첫 height
+
+func field(b []byte, num protowire.Number, typ protowire.Type) []byte {
+	for len(b) > 0 {
+		n, t, l := protowire.ConsumeTag(b)
+		if l < 0 {
+			return nil
+		}
+		b = b[l:]
+		l = protowire.ConsumeFieldValue(n, t, b)
+		if l < 0 {
+			return nil
+		}
+		if n == num && t == typ {
+			return b[:l]
+		}
+		b = b[l:]
+	}
+	return nil
+} //num번 필드의 wire 값(마지막이 아닌 첫 값)
+
+func message(b []byte, num protowire.Number) []byte {
+	v, _ := protowire.ConsumeBytes(field(b, num, protowire.BytesType))
+	return v
+} //num번 length-delimited 필드의 내용
+
+func varint(b []byte, num protowire.Number) uint64 {
+	v, _ := protowire.ConsumeVarint(field(b, num, protowire.VarintType))
+	return v
+} //num번 varint 필드(없으면 0)
+
+func run(out string) error {
+	root, err := os.MkdirTemp("", "cometbft-capture")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(root)
+	cfg.EnsureRoot(root)
+	c := cfg.DefaultConfig().SetRoot(root)
+	c.Consensus.TimeoutCommit = 200 * time.Millisecond
+	c.P2P.ListenAddress = "tcp://127.0.0.1:0"
+	c.RPC.ListenAddress = "tcp://127.0.0.1:0"
+	pv := privval.LoadOrGenFilePV(c.PrivValidatorKeyFile(), c.PrivValidatorStateFile())
+	pub, err := pv.GetPubKey()
+	if err != nil {
+		return err
+	}
+	gen := types.GenesisDoc{ChainID: "golden-capture", GenesisTime: cmttime.Now(), ConsensusParams: types.DefaultConsensusParams(),
+		Validators: []types.GenesisValidator{{Address: pub.Address(), PubKey: pub, Power: 10}}}
+	if err := gen.SaveAs(c.GenesisFile()); err != nil {
+		return err
+	}
+	nk, err := p2p.LoadOrGenNodeKey(c.NodeKeyFile())
+	if err != nil {
+		return err
+	}
+	n, err := node.NewNode(c, pv, nk, proxy.NewLocalClientCreator(kvstore.NewInMemoryApplication()),
+		node.DefaultGenesisDocProviderFunc(c), cfg.DefaultDBProvider, node.DefaultMetricsProvider(c.Instrumentation), log.NewNopLogger())
+	if err != nil {
+		return err
+	}
+	if err := n.Start(); err != nil {
+		return err
+	}
+	for n.BlockStore().Height() <= captureHeight {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if err := n.Stop(); err != nil {
+		return err
+	}
+	n.Wait()
+	data, err := os.ReadFile(c.Consensus.WalFile())
+	if err != nil {
+		return err
+	}
+	fmt.Println("cometbft", version.TMCoreSemVer)
+	kinds := map[uint64]string{1: "prevote", 2: "precommit", 32: "proposal"}
+	seen := map[string]bool{}
+	for len(data) >= 8 { //crc32, 길이, TimedWALMessage
+		l := binary.BigEndian.Uint32(data[4:8])
+		rec := data[8 : 8+l]
+		data = data[8+l:]
+		msg := message(message(message(rec, 2), 2), 1) //TimedWALMessage.msg → WALMessage.msg_info → MsgInfo.msg
+		inner := message(message(msg, 3), 1)           //ProposalMessage.proposal
+		if inner == nil {
+			inner = message(message(msg, 6), 1) //VoteMessage.vote
+		}
+		kind := kinds[varint(inner, 1)]
+		if inner == nil || kind == "" || varint(inner, 2) != captureHeight || seen[kind] {
+			continue
+		}
+		seen[kind] = true
+		if err := os.WriteFile(filepath.Join(out, "cometbft_"+kind+".pb"), inner, 0o644); err != nil {
+			return err
+		}
+		fmt.Println(kind, len(inner), "bytes")
+	}
+	if len(seen) != len(kinds) {
+		return fmt.Errorf("WAL has only %v at height %d", seen, captureHeight)
+	}
+	return nil
+} //노드를 실행하고 WAL에서 메시지를 추출
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: go run . <output dir>")
+		os.Exit(2)
+	}
+	if err := run(os.Args[1]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,2 @@
+  *H
+ NnVJZk=x$cUXP;$ p!uƲE/T:Vng2:@5Z.yOJ""0T'+B*ݻZp%RPbX
@@ -0,0 +1,138 @@
+package codec
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+type TimestampFormat string
+
+const (
+	TimestampDefault     TimestampFormat = ""            //codec 기본값(TimestampRFC3339Nano, protobuf는 Timestamp 메시지)
+	TimestampRFC3339Nano TimestampFormat = "rfc3339nano" //"2026-10-18T19:39:15.123456789Z"(소수점 이하 0은 생략)
+	TimestampRFC3339     TimestampFormat = "rfc3339"     //초 단위 RFC3339(이전 버전 출력)
+	TimestampUnix        TimestampFormat = "unix"        //epoch seconds 정수
+	TimestampUnixMilli   TimestampFormat = "unixmilli"   //epoch milliseconds 정수
+	TimestampUnixMicro   TimestampFormat = "unixmicro"   //epoch microseconds 정수
+	TimestampUnixNano    TimestampFormat = "unixnano"    //epoch nanoseconds 정수
+	TimestampProto       TimestampFormat = "proto"       //{seconds, nanos} 객체(google.protobuf.Timestamp JSON 구조)
+	TimestampNative      TimestampFormat = "native"      //포맷 고유 타입(msgpack timestamp ext), 없는 포맷은 RFC3339Nano
+)
+
+var epochUnits = []struct {
+	limit *big.Int //절댓값이 limit 미만일 시 해당 단위
+	nanos int64    //단위당 nanoseconds
+}{
+	{big.NewInt(1e11), int64(time.Second)},      //~5138년까지의 seconds
+	{big.NewInt(1e14), int64(time.Millisecond)}, //milliseconds
+	{big.NewInt(1e17), int64(time.Microsecond)}, //microseconds
+} //epoch 수의 크기로 단위 추정, 그 이상은 nanoseconds
+
+func epochTime(r *big.Rat) time.Time {
+	abs := new(big.Int).Quo(r.Num(), r.Denom()) //정수 부분
+	abs.Abs(abs)
+	unit := int64(time.Nanosecond)
+	for _, u := range epochUnits {
+		if abs.Cmp(u.limit) < 0 {
+			unit = u.nanos
+			break
+		}
+	}
+	ns := new(big.Rat).Mul(r, new(big.Rat).SetInt64(unit))
+	n := new(big.Int).Quo(ns.Num(), ns.Denom()) //nanosecond 미만 버림
+	sec, nsec := new(big.Int).DivMod(n, big.NewInt(int64(time.Second)), new(big.Int))
+	if !sec.IsInt64() {
+		return time.Time{}
+	}
+	return time.Unix(sec.Int64(), nsec.Int64()).UTC()
+} //epoch 수(s/ms/µs/ns 자동 판별, 소수 허용)를 time.Time으로 변환
+
+func epochRat(v interface{}) *big.Rat {
+	switch t := v.(type) {
+	case json.Number:
+		return epochRat(t.String())
+	case string:
+		if !isDecimalNotation(t) { //정수(10진수/0x-hex)
+			if x := (coercer{}).parseInt(t); x != nil {
+				return new(big.Rat).SetInt(x)
+			}
+			return nil
+		}
+		if r, ok := new(big.Rat).SetString(t); ok {
+			return r
+		}
+	case float64:
+		if r := new(big.Rat); r.SetFloat64(t) != nil {
+			return r
+		}
+	case float32:
+		return epochRat(float64(t))
+	default:
+		if x := (coercer{}).bigInt(v); x != nil { //msgpack 정수 등
+			return new(big.Rat).SetInt(x)
+		}
+	}
+	return nil
+} //epoch 값을 정확한 유리수로 변환
+
+func secondsNanos(m map[string]interface{}) (time.Time, bool) {
+	sec, ok := m["seconds"]
+	if !ok {
+		return time.Time{}, false
+	}
+	s := (coercer{}).bigInt(sec)
+	if s == nil || !s.IsInt64() {
+		return time.Time{}, false
+	}
+	var ns int64
+	if n := (coercer{}).bigInt(m["nanos"]); n != nil && n.IsInt64() {
+		ns = n.Int64()
+	}
+	return time.Unix(s.Int64(), ns).UTC(), true
+} //{seconds, nanos} 객체를 time.Time으로 변환
+
+func isSecondsNanos(v interface{}) bool {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = m["seconds"]
+	return ok
+} //{seconds, nanos} 형태의 객체인지 확인
+
+func (f TimestampFormat) value(t time.Time) interface{} {
+	t = t.UTC()
+	switch f {
+	case TimestampRFC3339:
+		return t.Format(time.RFC3339)
+	case TimestampUnix:
+		return json.Number(strconv.FormatInt(t.Unix(), 10))
+	case TimestampUnixMilli:
+		return json.Number(strconv.FormatInt(t.UnixMilli(), 10))
+	case TimestampUnixMicro:
+		return json.Number(strconv.FormatInt(t.UnixMicro(), 10))
+	case TimestampUnixNano:
+		ns := new(big.Int).Mul(big.NewInt(t.Unix()), big.NewInt(int64(time.Second))) //int64 nanoseconds 범위(1678~2262년) 밖도 표현
+		ns.Add(ns, big.NewInt(int64(t.Nanosecond())))
+		return json.Number(ns.String())
+	case TimestampProto:
+		return map[string]interface{}{
+			"seconds": json.Number(strconv.FormatInt(t.Unix(), 10)),
+			"nanos":   json.Number(strconv.Itoa(t.Nanosecond())),
+		}
+	}
+	return t.Format(time.RFC3339Nano) //기본값, TimestampNative를 지원하지 않는 포맷
+} //JSON 계열 출력에 넣을 timestamp 값
+
+func (f TimestampFormat) text(t time.Time) string {
+	switch v := f.value(t).(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	t = t.UTC()
+	return "{seconds=" + strconv.FormatInt(t.Unix(), 10) + ",nanos=" + strconv.Itoa(t.Nanosecond()) + "}" //TimestampProto는 record로
+} //generic 포맷 출력에 넣을 timestamp 표기
@@ -0,0 +1,127 @@
+package codec
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"codec/abstraction"
+)
+
+func TestEpochTime(t *testing.T) {
+	cases := []struct {
+		in   string
+		want time.Time
+	}{
+		{"0", time.Unix(0, 0)},
+		{"1714979289", time.Unix(1714979289, 0)},
+		{"99999999999", time.Unix(99999999999, 0)}, //1e11 미만은 seconds
+		{"100000000000", time.Unix(100000000, 0)},  //1e11부터 milliseconds
+		{"1714979289123", time.Unix(1714979289, 123e6)},
+		{"99999999999999", time.Unix(99999999999, 999e6)},
+		{"100000000000000", time.Unix(100000000, 0)}, //1e14부터 microseconds
+		{"1714979289123456", time.Unix(1714979289, 123456e3)},
+		{"99999999999999999", time.Unix(99999999999, 999999e3)},
+		{"100000000000000000", time.Unix(100000000, 0)}, //1e17부터 nanoseconds
+		{"1714979289123456789", time.Unix(1714979289, 123456789)},
+		{"-1", time.Unix(-1, 0)},
+		{"-99999999999", time.Unix(-99999999999, 0)}, //단위는 절댓값으로 판별
+		{"-100000000000", time.Unix(-100000000, 0)},
+		{"-1714979289123", time.Unix(-1714979290, 877e6)},
+		{"-100000000000000000", time.Unix(-100000000, 0)},
+		{"1.5", time.Unix(1, 5e8)},
+		{"-1.5", time.Unix(-2, 5e8)},
+		{"1714979289123.4567", time.Unix(1714979289, 123456700)}, //소수 milliseconds
+		{"0.0000000001", time.Unix(0, 0)},                        //nanosecond 미만 버림
+		{"1" + strings.Repeat("0", 30), time.Time{}},             //int64 seconds 범위 밖
+	}
+	for _, tc := range cases {
+		r, ok := new(big.Rat).SetString(tc.in)
+		if !ok {
+			t.Fatalf("bad test input %q", tc.in)
+		}
+		want := tc.want
+		if !want.IsZero() {
+			want = want.UTC()
+		}
+		if got := epochTime(r); !got.Equal(want) || got.Location() != want.Location() {
+			t.Errorf("epochTime(%s) = %v, want %v", tc.in, got, want)
+		}
+	}
+	for _, in := range []string{"99999999999", "100000000000", "99999999999999", "100000000000000", "99999999999999999", "100000000000000000", "-100000000000"} {
+		am, err := Parse([]byte(`{"type":"Commit","timestamp":`+in+`}`), ParseOptions{Format: FormatJSON})
+		r, _ := new(big.Rat).SetString(in)
+		if err != nil || !am.Timestamp.Equal(epochTime(r)) {
+			t.Errorf("JSON timestamp %s = %+v, %v, want %v", in, am, err, epochTime(r))
+		}
+		am, err = Parse([]byte(`{"type":"Commit","timestamp":"`+in+`"}`), ParseOptions{Format: FormatJSON})
+		if err != nil || !am.Timestamp.Equal(epochTime(r)) {
+			t.Errorf("JSON timestamp string %q = %+v, %v, want %v", in, am, err, epochTime(r))
+		}
+	}
+} //epoch 수의 크기(1e11, 1e14, 1e17 경계)로 s/ms/µs/ns 판별, 음수와 소수 포함
+
+func TestTimestampFormat(t *testing.T) {
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.FixedZone("KST", 9*3600)) //출력은 UTC
+	cases := []struct {
+		f     TimestampFormat
+		value interface{}
+		text  string
+	}{
+		{TimestampDefault, "2024-05-05T22:08:09.123456789Z", "2024-05-05T22:08:09.123456789Z"},
+		{TimestampRFC3339Nano, "2024-05-05T22:08:09.123456789Z", "2024-05-05T22:08:09.123456789Z"},
+		{TimestampRFC3339, "2024-05-05T22:08:09Z", "2024-05-05T22:08:09Z"},
+		{TimestampUnix, json.Number("1714946889"), "1714946889"},
+		{TimestampUnixMilli, json.Number("1714946889123"), "1714946889123"},
+		{TimestampUnixMicro, json.Number("1714946889123456"), "1714946889123456"},
+		{TimestampUnixNano, json.Number("1714946889123456789"), "1714946889123456789"},
+		{TimestampProto, map[string]interface{}{"seconds": json.Number("1714946889"), "nanos": json.Number("123456789")}, "{seconds=1714946889,nanos=123456789}"},
+		{TimestampNative, "2024-05-05T22:08:09.123456789Z", "2024-05-05T22:08:09.123456789Z"}, //JSON/generic에는 고유 타입이 없음
+	}
+	for _, tc := range cases {
+		if got := tc.f.value(ts); !reflect.DeepEqual(got, tc.value) {
+			t.Errorf("%q value = %#v, want %#v", tc.f, got, tc.value)
+		}
+		if got := tc.f.text(ts); got != tc.text {
+			t.Errorf("%q text = %q, want %q", tc.f, got, tc.text)
+		}
+	}
+	whole := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	if got := TimestampRFC3339Nano.value(whole); got != "2024-05-06T07:08:09Z" {
+		t.Errorf("RFC3339Nano of a whole second = %v, want no fraction", got)
+	}
+	old := time.Date(1600, 1, 1, 0, 0, 0, 1, time.UTC) //int64 nanoseconds 범위 밖
+	if got := TimestampUnixNano.value(old); got != json.Number("-11676095999999999999") {
+		t.Errorf("UnixNano of year 1600 = %v", got)
+	}
+
+	fuzzSetup(t)
+	am := &abstraction.AbstractMessage{Type: abstraction.MsgTypeCommit, Timestamp: ts}
+	for _, format := range []Format{FormatJSON, FormatMsgPack, FormatGeneric} {
+		for _, tc := range cases {
+			out, err := Serialize(am, SerializeOptions{Format: format, TimestampFormat: tc.f})
+			if err != nil {
+				t.Fatalf("%s/%q: %v", format, tc.f, err)
+			}
+			got, err := Parse(out, ParseOptions{Format: format})
+			if err != nil {
+				t.Fatalf("%s/%q: Parse(%q): %v", format, tc.f, out, err)
+			}
+			want := ts.UTC()
+			switch tc.f {
+			case TimestampRFC3339, TimestampUnix:
+				want = want.Truncate(time.Second)
+			case TimestampUnixMilli:
+				want = want.Truncate(time.Millisecond)
+			case TimestampUnixMicro:
+				want = want.Truncate(time.Microsecond)
+			}
+			if !got.Timestamp.Equal(want) {
+				t.Errorf("%s/%q: %q parsed as %v, want %v", format, tc.f, out, got.Timestamp, want)
+			}
+		}
+	}
+} //TimestampFormat별 출력 값/generic 표기와 포맷별로 다시 읽은 시각(단위 이하 버림)
@@ -1,41 +1,135 @@
-package codec
-
-import (
-	"bytes"
-	"encoding/json"
-	"strings"
-)
-
-func jsonFromInterface(v interface{}) ([]byte, error) {
-	return json.Marshal(v)
-} //go-bcs/rlp/msgpack 등 포맷 JSON 바이트로 직렬화(bcs/rlp/msgpack 등의 포맷)
-
-func unmarshalJSON(data []byte, v interface{}) error {
-	dec := json.NewDecoder(bytes.NewReader(data))
-	dec.UseNumber()
-	return dec.Decode(v)
-} //JSON 바이트를 포인터로 역직렬화
-
-func TrimBrackets(s string) string {
-	if i := strings.Index(s, "("); i >= 0 { //첫 '(' 위치
-		if j := strings.LastIndex(s, ")"); j > i { //마지막 ')' 위치
-			return s[i+1 : j] //괄호 안 내용 반환
-		}
-	}
-	return s //괄호가 없거나 형식이 다를 시 원문 반환
-} //Name(k=v, ...) 형식의 문자열에서 괄호 내부 반환
-
-func SplitKeyValuePairs(s string) map[string]string {
-	m := make(map[string]string)
-	if strings.TrimSpace(s) == "" { // 빈 문자열일 경우
-		return m
-	}
-	for _, part := range strings.Split(s, ",") { //','로 분리
-		if kv := strings.SplitN(part, "=", 2); len(kv) == 2 { // '=' 기준으로 분리
-			key := strings.TrimSpace(kv[0]) //key 앞뒤 공백 제거
-			val := strings.TrimSpace(kv[1]) //value 앞뒤 공백 제거
-			m[key] = val
-		}
-	}
-	return m
-} //k=v, k=v, ... 형식 parsing
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+)
+
+func jsonFromInterface(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+} //go-bcs/rlp/msgpack 등 포맷 JSON 바이트로 직렬화(bcs/rlp/msgpack 등의 포맷)
+
+func unmarshalJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() //수는 float64 대신 json.Number(원문 그대로)로
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if off := dec.InputOffset(); len(bytes.TrimSpace(data[off:])) > 0 { //값 뒤에 남은 데이터
+		return &trailingDataError{Offset: int64(len(data) - len(trimJSONSpace(data[off:])))} //공백 다음 첫 바이트
+	}
+	return nil
+} //JSON 바이트를 포인터로 역직렬화(수는 임의 정밀도 유지)
+
+func scalarJSON(b []byte) (interface{}, bool) {
+	switch string(b) {
+	case "null":
+		return nil, true
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	}
+	if s, ok := plainJSONString(b); ok {
+		return string(s), true
+	}
+	if !jsonIntToken(b) {
+		return nil, false
+	}
+	return json.Number(b), true
+} //단일 scalar JSON 값은 decoder 없이 unmarshalJSON(UseNumber)과 같은 값으로(그 외는 false)
+
+func plainJSONString(b []byte) ([]byte, bool) {
+	if len(b) < 2 || b[0] != '"' || b[len(b)-1] != '"' {
+		return nil, false
+	}
+	s := b[1 : len(b)-1]
+	for _, c := range s {
+		if c < 0x20 || c == '"' || c == '\\' {
+			return nil, false
+		}
+	}
+	return s, utf8.Valid(s) //잘못된 UTF-8은 decoder가 치환
+} //escape/제어 문자가 없는 JSON 문자열 token의 내용
+
+func jsonIntToken(b []byte) bool {
+	digits := b
+	if len(digits) > 0 && digits[0] == '-' {
+		digits = digits[1:]
+	}
+	if len(digits) == 0 || (digits[0] == '0' && len(digits) > 1) { //앞자리 0 불가
+		return false
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+} //JSON 정수 표기 token
+
+func jsonStringEnd(b []byte) int {
+	if len(b) == 0 || b[0] != '"' {
+		return -1
+	}
+	i := bytes.IndexByte(b[1:], '"')
+	if i < 0 {
+		return -1
+	}
+	return i + 2
+} //b 앞 문자열 token의 길이(escape된 따옴표는 plainJSONString이 거부)
+
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+} //JSON token 사이 공백
+
+func trimJSONSpace(b []byte) []byte {
+	for len(b) > 0 && isJSONSpace(b[0]) {
+		b = b[1:]
+	}
+	return b
+} //앞쪽 JSON 공백 제거
+
+type trailingDataError struct {
+	Offset int64 //남은 데이터의 첫 바이트 위치
+} //최상위 JSON 값 뒤에 데이터가 남음
+
+func (e *trailingDataError) Error() string {
+	return "invalid character after top-level value"
+} //json.Unmarshal과 같은 메시지
+
+func TrimBrackets(s string) string {
+	if i := strings.Index(s, "("); i >= 0 { //첫 '(' 위치
+		if j := strings.LastIndex(s, ")"); j > i { //마지막 ')' 위치
+			return s[i+1 : j] //괄호 안 내용 반환
+		}
+	}
+	return s //괄호가 없거나 형식이 다를 시 원문 반환
+} //Name(k=v, ...) 형식의 문자열에서 괄호 내부 반환
+
+func SplitKeyValuePairs(s string) map[string]string {
+	m := make(map[string]string)
+	if strings.TrimSpace(s) == "" { // 빈 문자열일 경우
+		return m
+	}
+	for _, part := range strings.Split(s, ",") { //','로 분리
+		if kv := strings.SplitN(part, "=", 2); len(kv) == 2 { // '=' 기준으로 분리
+			key := strings.TrimSpace(kv[0]) //key 앞뒤 공백 제거
+			val := strings.TrimSpace(kv[1]) //value 앞뒤 공백 제거
+			m[key] = val
+		}
+	}
+	return m
+} //k=v, k=v, ... 형식 parsing
+
+func jsonOptions(opts SerializeOptions) SerializeOptions {
+	opts.Format = FormatJSON
+	return opts
+} //JSON을 경유하는 codec이 직렬화 옵션을 유지한 채 JSON 직렬화에 넘길 옵션
+
+func jsonParseOptions(opts ParseOptions) ParseOptions {
+	opts.Format = FormatJSON
+	return opts
+} //JSON을 경유하는 codec이 parsing 옵션을 유지한 채 JSON parsing에 넘길 옵션
@@ -0,0 +1,139 @@
+package codec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"codec/abstraction"
+)
+
+type Vocabulary struct {
+	Name   string                         //프로파일 이름(예: tendermint)
+	Phases map[abstraction.MsgType]string //표준 타입 -> 구현체 메시지명
+	Fields map[string]string              //표준 필드명(Height 등) -> 구현체 필드 key 또는 중첩 경로(예: block_id.hash)
+} //특정 구현체의 메시지명/필드명 어휘(PhaseSynonyms/FieldSynonyms의 역방향)
+
+func NewVocabulary(name string, phaseNames, fieldNames []string) (*Vocabulary, error) {
+	return builtinSynonyms.Vocabulary(name, phaseNames, fieldNames)
+} //내장 사전 기준으로 Vocabulary 생성
+
+func (s *SynonymSet) Vocabulary(name string, phaseNames, fieldNames []string) (*Vocabulary, error) {
+	v := &Vocabulary{
+		Name:   name,
+		Phases: make(map[abstraction.MsgType]string, len(phaseNames)),
+		Fields: make(map[string]string, len(fieldNames)),
+	}
+	for _, p := range phaseNames {
+		canon, ok := s.Phase(p) //구현체 메시지명 -> 표준 타입
+		if !ok {
+			return nil, fmt.Errorf("vocabulary %s: phase %q is not a known synonym", name, p)
+		}
+		if prev, dup := v.Phases[abstraction.MsgType(canon)]; dup {
+			return nil, fmt.Errorf("vocabulary %s: phases %q and %q both map to %s", name, prev, p, canon)
+		}
+		v.Phases[abstraction.MsgType(canon)] = p
+	}
+	var paths map[string]string
+	tops := make(map[string]string, len(fieldNames)) //최상위 key -> 그 key를 쓰는 이름
+	for _, f := range fieldNames {
+		canon, ok := s.Field(f)             //구현체 필드명 -> 표준 필드명
+		if strings.IndexByte(f, '.') >= 0 { //중첩 경로는 같은 경로 규칙이 있어야 다시 읽힘
+			if paths == nil {
+				paths = s.Paths()
+			}
+			canon, ok = paths[f]
+			if !ok || strings.Contains(f, "..") || strings.ContainsAny(f, "[]*") || f[0] == '.' || f[len(f)-1] == '.' { //객체 key만(배열/wildcard 없이)
+				return nil, fmt.Errorf("vocabulary %s: path %q is not a known path synonym of object keys", name, f)
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("vocabulary %s: field %q is not a known synonym", name, f)
+		}
+		if prev, dup := v.Fields[canon]; dup {
+			return nil, fmt.Errorf("vocabulary %s: fields %q and %q both map to %s", name, prev, f, canon)
+		}
+		top, _, _ := strings.Cut(f, ".")
+		if prev, dup := tops[top]; dup { //한 key에 두 필드를 쓸 수 없음
+			return nil, fmt.Errorf("vocabulary %s: fields %q and %q both use key %q", name, prev, f, top)
+		}
+		tops[top] = f
+		v.Fields[canon] = f
+	}
+	return v, nil
+} //구현체가 쓰는 이름 목록을 사전 s로 역변환하여 Vocabulary 생성(필드는 이름 또는 경로 규칙에 있는 중첩 경로)
+
+func (v *Vocabulary) phase(t abstraction.MsgType) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+	p, ok := v.Phases[t]
+	return p, ok
+} //표준 타입에 대응하는 구현체 메시지명
+
+func (v *Vocabulary) field(canon string) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+	f, ok := v.Fields[canon]
+	return f, ok
+} //표준 필드명에 대응하는 구현체 필드 key
+
+var (
+	vocabMu      sync.RWMutex
+	vocabularies = map[string]*Vocabulary{}
+) //이름 -> Vocabulary 등록부
+
+func RegisterVocabulary(v *Vocabulary) {
+	vocabMu.Lock()
+	defer vocabMu.Unlock()
+	vocabularies[v.Name] = v
+} //Vocabulary 등록(같은 이름은 덮어씀)
+
+func LookupVocabulary(name string) (*Vocabulary, bool) {
+	vocabMu.RLock()
+	defer vocabMu.RUnlock()
+	v, ok := vocabularies[name]
+	return v, ok
+} //이름으로 Vocabulary 조회
+
+func VocabularyNames() []string {
+	vocabMu.RLock()
+	defer vocabMu.RUnlock()
+	names := make([]string, 0, len(vocabularies))
+	for n := range vocabularies {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+} //등록된 Vocabulary 이름 목록(정렬)
+
+func mustVocabulary(name string, phaseNames, fieldNames []string) *Vocabulary {
+	v, err := NewVocabulary(name, phaseNames, fieldNames)
+	if err != nil {
+		panic(err)
+	}
+	return v
+} //내장 프로파일 생성, 사전과 맞지 않을 시 panic
+
+func init() {
+	RegisterVocabulary(mustVocabulary("pbft",
+		[]string{"PrePrepare", "Prepare", "Commit", "ViewChange", "NewView"},
+		[]string{"view", "seq_num", "digest", "replica_id", "timestamp", "signature"}))
+	RegisterVocabulary(mustVocabulary("tendermint",
+		[]string{"Proposal", "Prevote", "Precommit", "NewRound"},
+		[]string{"height", "round", "block_id.hash", "timestamp", "validator_address", "signature"}))
+	RegisterVocabulary(mustVocabulary("fabric",
+		[]string{"PrePrepare", "Prepare", "Commit", "ViewChange", "NewView"},
+		[]string{"view", "seq", "digest", "signer", "signature"}))
+	RegisterVocabulary(mustVocabulary("ibft",
+		[]string{"Preprepare", "Prepare", "Commit", "RoundChange"},
+		[]string{"sequence", "round", "digest", "proposer", "validator_address", "signature", "commit_seals"}))
+	RegisterVocabulary(mustVocabulary("hotstuff",
+		[]string{"Proposal", "Vote", "NewView"},
+		[]string{"view_number", "proposal_hash", "parent_hash", "leader", "replica_id", "signature"}))
+	RegisterVocabulary(mustVocabulary("algorand",
+		[]string{"Propose", "Soft-Vote", "Cert-Vote", "Next-Vote"},
+		[]string{"round", "proposal_id", "signer", "sig"}))
+} //내장 구현체 프로파일 등록
@@ -0,0 +1,151 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"codec/abstraction"
+)
+
+var builtinVocabularies = []string{"pbft", "tendermint", "fabric", "ibft", "hotstuff", "algorand"}
+
+func vocabularyMessage(typ abstraction.MsgType) *abstraction.AbstractMessage {
+	return &abstraction.AbstractMessage{
+		Type:        typ,
+		Height:      big.NewInt(12),
+		Round:       big.NewInt(3),
+		View:        big.NewInt(4),
+		Timestamp:   time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC),
+		BlockHash:   "0xdeadbeef",
+		PrevHash:    "0xfeedbead",
+		Proposer:    "node 1",
+		Validator:   "node 2",
+		Signature:   "SIG",
+		CommitSeals: []string{"seal A", "seal B"},
+	}
+} //profile이 매핑하는 필드를 모두 채운 메시지
+
+func TestVocabularyRoundTrip(t *testing.T) {
+	fuzzSetup(t)
+	for _, name := range builtinVocabularies {
+		v, ok := LookupVocabulary(name)
+		if !ok {
+			t.Fatalf("built-in vocabulary %s is not registered", name)
+		}
+		for typ, phase := range v.Phases {
+			am := vocabularyMessage(typ)
+			for _, format := range []Format{FormatGeneric, FormatJSON, FormatMsgPack, FormatRLP, FormatBCS, FormatProtobuf} {
+				sopts := fuzzSerializeOptions(format)
+				sopts.Vocabulary = name
+				out, err := Serialize(am, sopts)
+				if err != nil {
+					t.Fatalf("%s %s/%s: %v", name, typ, format, err)
+				}
+				got, err := Parse(out, fuzzParseOptions(format))
+				if err != nil {
+					t.Fatalf("%s %s/%s: Parse(%q): %v", name, typ, format, out, err)
+				}
+				if diff := am.Diff(got, abstraction.EqualOptions{IgnoreRawPayload: true}); len(diff) > 0 {
+					t.Errorf("%s %s/%s: round trip differs:\n%s", name, typ, format, diff)
+				}
+				if len(got.Extras) > 0 {
+					t.Errorf("%s %s/%s: fields left in Extras: %v", name, typ, format, got.Extras)
+				}
+				if format != FormatProtobuf && got.OriginalMsgName != phase {
+					t.Errorf("%s %s/%s: message name %q, want %q", name, typ, format, got.OriginalMsgName, phase)
+				}
+			}
+		}
+	}
+} //내장 profile로 직렬화한 메시지를 포맷별로 다시 읽으면 같은 값(profile 이름은 사전으로 되돌아옴)
+
+func TestVocabularyKeys(t *testing.T) {
+	for _, name := range builtinVocabularies {
+		v, _ := LookupVocabulary(name)
+		am := vocabularyMessage(abstraction.MsgTypeCommit)
+		out, err := Serialize(am, SerializeOptions{Format: FormatJSON, Vocabulary: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var obj map[string]interface{}
+		if err := json.Unmarshal(out, &obj); err != nil {
+			t.Fatal(err)
+		}
+		for canon, key := range v.Fields {
+			var at interface{} = obj
+			for _, k := range strings.Split(key, ".") {
+				m, ok := at.(map[string]interface{})
+				if !ok {
+					at = nil
+					break
+				}
+				at = m[k]
+			}
+			if _, isObj := at.(map[string]interface{}); at == nil || isObj {
+				t.Errorf("%s: %s is not a value at %q in %s", name, canon, key, out)
+			}
+			if k := canonicalFieldKeys[canon]; k != key && obj[k] != nil {
+				t.Errorf("%s: %s also written under the standard key %q", name, canon, k)
+			}
+		}
+	}
+	vote := []byte(`{"type":"Prevote","height":"12","round":0,"block_id":{"hash":"0xdeadbeef","parts":{"total":1}},"validator_address":"n2"}`)
+	am, err := Parse(vote, ParseOptions{Format: FormatJSON})
+	if err != nil || am.BlockHash != "0xdeadbeef" {
+		t.Fatalf("tendermint vote = %+v, %v", am, err)
+	}
+	out, err := Serialize(am, SerializeOptions{Format: FormatJSON, Vocabulary: "tendermint"})
+	var obj struct {
+		BlockID map[string]interface{} `json:"block_id"` //tendermint의 block_id는 객체
+	}
+	if err != nil || json.Unmarshal(out, &obj) != nil || obj.BlockID["hash"] != "0xdeadbeef" {
+		t.Errorf("tendermint JSON output = %s, %v, want block_id.hash", out, err)
+	}
+	out, err = Serialize(vocabularyMessage(abstraction.MsgTypeCommit), SerializeOptions{Format: FormatGeneric, Vocabulary: "tendermint"})
+	if err != nil || !strings.Contains(string(out), `block_id={hash=0xdeadbeef}`) {
+		t.Errorf("tendermint generic output = %s, %v, want block_id={hash=...}", out, err)
+	}
+} //profile의 각 필드가 그 key(중첩 경로 포함)에 값으로 쓰임
+
+func TestVocabularyErrors(t *testing.T) {
+	cases := []struct {
+		name           string
+		phases, fields []string
+		want           string
+	}{
+		{"unknown phase", []string{"Bogus"}, nil, "not a known synonym"},
+		{"duplicate phase", []string{"Prevote", "Prepare"}, nil, "both map to"},
+		{"unknown field", nil, []string{"bogus"}, "not a known synonym"},
+		{"duplicate field", nil, []string{"height", "seq_num"}, "both map to"},
+		{"unknown path", nil, []string{"block_id.nope"}, "not a known path synonym"},
+		{"wildcard path", nil, []string{"*.height"}, "not a known path synonym"},
+		{"paths under different keys", nil, []string{"block_id.hash", "vote.height"}, ""},
+	}
+	for _, tc := range cases {
+		_, err := NewVocabulary(tc.name, tc.phases, tc.fields)
+		if tc.want == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tc.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: NewVocabulary = %v, want an error containing %q", tc.name, err, tc.want)
+		}
+	}
+	s := DefaultSynonyms()
+	s.SetPath("block_id.parts", "PrevHash")
+	s.SetField("vote", "Validator")
+	for _, fields := range [][]string{{"block_id.hash", "block_id.parts"}, {"vote.height", "vote"}} {
+		if _, err := s.Vocabulary("x", nil, fields); err == nil || !strings.Contains(err.Error(), "both use key") {
+			t.Errorf("%v under one key = %v", fields, err)
+		}
+	}
+	if _, err := Serialize(&abstraction.AbstractMessage{}, SerializeOptions{Format: FormatJSON, Vocabulary: "nope"}); err == nil || errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("unknown vocabulary = %v", err)
+	}
+} //사전에 없는 이름/경로, 중복 매핑, 한 key를 공유하는 필드는 에러
@@ -0,0 +1,231 @@
+package framing
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var ErrTooLarge = errors.New("message too large") //frame이 최대 크기 초과
+
+type Frame struct {
+	Payload []byte //envelope를 벗긴 메시지 바이트
+	Code    uint64 //devp2p 메시지 코드(HasCode일 시)
+	HasCode bool   //Code가 있는지
+	Peer    string //메시지를 보낸/받을 peer(알 수 없을 시 빈 문자열)
+} //wire envelope를 벗긴 payload와 메타데이터
+
+type Framer interface {
+	ReadFrame(r *bufio.Reader, limit int) (Frame, error) //frame 하나 읽음(스트림 끝일 시 io.EOF, 중간에 끝날 시 io.ErrUnexpectedEOF)
+	WriteFrame(w io.Writer, f Frame) error               //frame 하나 씀
+} //바이트 스트림을 payload 단위로 나누고 다시 감싸는 envelope
+
+func tooLarge(n uint64, limit int) error {
+	if limit > 0 && n > uint64(limit) {
+		return fmt.Errorf("%w: %d bytes (limit %d)", ErrTooLarge, n, limit)
+	}
+	return nil
+} //크기 상한 확인(limit 0 이하는 무제한)
+
+func readPayload(r *bufio.Reader, n uint64, limit int) ([]byte, error) {
+	if err := tooLarge(n, limit); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, unexpected(err)
+	}
+	return buf, nil
+} //길이 확인 후 payload 읽음
+
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+} //frame 중간에서 끝난 스트림
+
+type lengthPrefix32 struct{} //4바이트 길이 prefix
+
+func LengthPrefix32() Framer {
+	return lengthPrefix32{}
+} //4바이트 big-endian 길이 prefix
+
+func (lengthPrefix32) ReadFrame(r *bufio.Reader, limit int) (Frame, error) {
+	var hdr [4]byte
+	if n, err := io.ReadFull(r, hdr[:]); err != nil {
+		if n == 0 && err == io.EOF {
+			return Frame{}, io.EOF
+		}
+		return Frame{}, unexpected(err)
+	}
+	b, err := readPayload(r, uint64(binary.BigEndian.Uint32(hdr[:])), limit)
+	return Frame{Payload: b}, err
+} //길이 4바이트 + payload
+
+func (lengthPrefix32) WriteFrame(w io.Writer, f Frame) error {
+	if uint64(len(f.Payload)) > 1<<32-1 {
+		return fmt.Errorf("%w: %d bytes do not fit a 32-bit length", ErrTooLarge, len(f.Payload))
+	}
+	var hdr [4]byte
+	binary.BigEndian.PutUint32(hdr[:], uint32(len(f.Payload)))
+	if _, err := w.Write(hdr[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(f.Payload)
+	return err
+} //길이 4바이트 + payload
+
+type uvarintPrefix struct{} //uvarint 길이 prefix
+
+func Uvarint() Framer {
+	return uvarintPrefix{}
+} //uvarint 길이 prefix(protobuf delimited 등)
+
+func (uvarintPrefix) ReadFrame(r *bufio.Reader, limit int) (Frame, error) {
+	var n uint64
+	for i := 0; ; i++ {
+		c, err := r.ReadByte()
+		if err != nil {
+			if i == 0 {
+				return Frame{}, err //prefix 첫 바이트 전에 끝날 시 io.EOF
+			}
+			return Frame{}, unexpected(err)
+		}
+		if i == binary.MaxVarintLen64-1 && c > 1 {
+			return Frame{}, fmt.Errorf("%w: uvarint length overflows 64 bits", ErrTooLarge)
+		}
+		n |= uint64(c&0x7f) << (7 * i)
+		if c < 0x80 {
+			break
+		}
+	}
+	b, err := readPayload(r, n, limit)
+	return Frame{Payload: b}, err
+} //uvarint 길이 + payload
+
+func (uvarintPrefix) WriteFrame(w io.Writer, f Frame) error {
+	_, err := w.Write(append(binary.AppendUvarint(nil, uint64(len(f.Payload))), f.Payload...))
+	return err
+} //uvarint 길이 + payload
+
+type newline struct{} //줄 단위
+
+func Newline() Framer {
+	return newline{}
+} //줄 단위 텍스트(빈 줄 무시, 끝의 \r\n 제거)
+
+func (newline) ReadFrame(r *bufio.Reader, limit int) (Frame, error) {
+	for {
+		var line []byte
+		for {
+			chunk, err := r.ReadSlice('\n')
+			line = append(line, chunk...)
+			if n := len(bytes.TrimRight(line, "\r\n")); limit > 0 && n > limit { //줄바꿈 제외 후 limit 초과
+				return Frame{}, tooLarge(uint64(n), limit)
+			}
+			if err == bufio.ErrBufferFull {
+				continue
+			}
+			if err == io.EOF && len(line) > 0 { //마지막 줄에 줄바꿈이 없어도 허용
+				break
+			}
+			if err != nil {
+				return Frame{}, err
+			}
+			break
+		}
+		line = bytes.TrimRight(line, "\r\n")
+		if len(bytes.TrimSpace(line)) > 0 {
+			return Frame{Payload: line}, nil
+		}
+	}
+} //다음 빈 줄이 아닌 줄
+
+func (newline) WriteFrame(w io.Writer, f Frame) error {
+	if bytes.IndexByte(f.Payload, '\n') >= 0 {
+		return fmt.Errorf("newline framing: payload contains a newline")
+	}
+	_, err := w.Write(append(append([]byte(nil), f.Payload...), '\n'))
+	return err
+} //payload + 줄바꿈
+
+type devp2p struct {
+	inner Framer //코드+payload를 담은 바깥 envelope
+} //devp2p 메시지 코드
+
+func Devp2p(inner Framer) Framer {
+	return devp2p{inner: inner}
+} //inner frame의 payload 앞에 RLP 정수로 붙은 devp2p 메시지 코드를 분리(복호화된 RLPx frame 등)
+
+func (d devp2p) ReadFrame(r *bufio.Reader, limit int) (Frame, error) {
+	f, err := d.inner.ReadFrame(r, limit)
+	if err != nil {
+		return f, err
+	}
+	code, rest, err := rlp.SplitUint64(f.Payload)
+	if err != nil {
+		return Frame{}, fmt.Errorf("devp2p message code: %w", err)
+	}
+	f.Code, f.HasCode, f.Payload = code, true, rest
+	return f, nil
+} //inner frame에서 메시지 코드 분리
+
+func (d devp2p) WriteFrame(w io.Writer, f Frame) error {
+	f.Payload = append(rlp.AppendUint64(nil, f.Code), f.Payload...)
+	return d.inner.WriteFrame(w, f)
+} //메시지 코드를 붙여 inner frame으로 씀
+
+type withPeer struct {
+	inner Framer
+	peer  string //frame에 붙일 peer
+} //고정 peer 표시
+
+func WithPeer(inner Framer, peer string) Framer {
+	return withPeer{inner: inner, peer: peer}
+} //inner가 읽은 모든 frame에 peer 표시(캡처 파일이 peer별로 나뉘어 있을 때)
+
+func (p withPeer) ReadFrame(r *bufio.Reader, limit int) (Frame, error) {
+	f, err := p.inner.ReadFrame(r, limit)
+	if err == nil && f.Peer == "" {
+		f.Peer = p.peer
+	}
+	return f, err
+} //inner frame에 peer 설정
+
+func (p withPeer) WriteFrame(w io.Writer, f Frame) error {
+	return p.inner.WriteFrame(w, f)
+} //inner로 그대로 씀
+
+func Lookup(spec string) (Framer, error) {
+	parts := strings.Split(spec, "+") //바깥 envelope부터(예: devp2p+len32)
+	var f Framer
+	for i := len(parts) - 1; i >= 0; i-- {
+		switch name := strings.TrimSpace(parts[i]); name {
+		case "len32":
+			f = LengthPrefix32()
+		case "uvarint":
+			f = Uvarint()
+		case "newline":
+			f = Newline()
+		case "devp2p":
+			if f == nil {
+				return nil, fmt.Errorf("framing %q: devp2p needs an inner framer (e.g. devp2p+len32)", spec)
+			}
+			f = Devp2p(f)
+			continue
+		default:
+			return nil, fmt.Errorf("unknown framing %q", name)
+		}
+		if i != len(parts)-1 {
+			return nil, fmt.Errorf("framing %q: %s must be the innermost framer", spec, parts[i])
+		}
+	}
+	return f, nil
+} //이름으로 framer 생성(len32, uvarint, newline, devp2p+<inner>)
@@ -0,0 +1,177 @@
+package framing
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+var testPayloads = [][]byte{[]byte("a"), []byte("hello world"), bytes.Repeat([]byte("x"), 300), {0x00, 0xff, '\r'}}
+
+func writeFrames(t *testing.T, f Framer, frames []Frame) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, fr := range frames {
+		if err := f.WriteFrame(&buf, fr); err != nil {
+			t.Fatalf("WriteFrame(%q): %v", fr.Payload, err)
+		}
+	}
+	return buf.Bytes()
+} //frame들을 이어 쓴 스트림
+
+func readFrames(f Framer, data []byte, limit int) ([]Frame, error) {
+	r := bufio.NewReaderSize(bytes.NewReader(data), 16) //작은 buffer로 ErrBufferFull 경로 포함
+	var frames []Frame
+	for {
+		fr, err := f.ReadFrame(r, limit)
+		if err != nil {
+			return frames, err
+		}
+		frames = append(frames, fr)
+	}
+} //스트림 끝(또는 에러)까지 frame을 읽음
+
+func TestRoundTrip(t *testing.T) {
+	framers := map[string]Framer{
+		"len32":          LengthPrefix32(),
+		"uvarint":        Uvarint(),
+		"devp2p+len32":   Devp2p(LengthPrefix32()),
+		"devp2p+uvarint": Devp2p(Uvarint()),
+		"peer+len32":     WithPeer(LengthPrefix32(), "n1"),
+	}
+	for name, f := range framers {
+		var frames []Frame
+		for i, p := range append(testPayloads, []byte{}) {
+			fr := Frame{Payload: p}
+			if strings.HasPrefix(name, "devp2p") {
+				fr.Code, fr.HasCode = uint64(i*0x40), true //0, 단일 바이트, 2바이트 RLP 정수
+			}
+			if strings.HasPrefix(name, "peer") {
+				fr.Peer = "n1"
+			}
+			frames = append(frames, fr)
+		}
+		got, err := readFrames(f, writeFrames(t, f, frames), 0)
+		if err != io.EOF {
+			t.Errorf("%s: end of stream = %v, want io.EOF", name, err)
+		}
+		if len(got) != len(frames) {
+			t.Fatalf("%s: read %d frames, want %d", name, len(got), len(frames))
+		}
+		for i := range frames {
+			if !bytes.Equal(got[i].Payload, frames[i].Payload) || got[i].Code != frames[i].Code || got[i].HasCode != frames[i].HasCode || got[i].Peer != frames[i].Peer {
+				t.Errorf("%s frame %d = %+v, want %+v", name, i, got[i], frames[i])
+			}
+		}
+	}
+} //framer별로 쓴 frame을 같은 payload/코드/peer로 읽음
+
+func TestNewline(t *testing.T) {
+	f := Newline()
+	var frames []Frame
+	for _, p := range []string{"a", "hello world", strings.Repeat("x", 300), "tab\tand space"} {
+		frames = append(frames, Frame{Payload: []byte(p)})
+	}
+	got, err := readFrames(f, writeFrames(t, f, frames), 0)
+	if err != io.EOF || len(got) != len(frames) {
+		t.Fatalf("read %d frames, %v", len(got), err)
+	}
+	for i := range frames {
+		if !bytes.Equal(got[i].Payload, frames[i].Payload) {
+			t.Errorf("frame %d = %q, want %q", i, got[i].Payload, frames[i].Payload)
+		}
+	}
+	got, err = readFrames(f, []byte("\n  \r\none\r\n\r\ntwo\nlast"), 0)
+	if err != io.EOF || len(got) != 3 || string(got[0].Payload) != "one" || string(got[1].Payload) != "two" || string(got[2].Payload) != "last" {
+		t.Errorf("blank lines/CRLF/no final newline = %d frames %v", len(got), err)
+	}
+	if err := f.WriteFrame(io.Discard, Frame{Payload: []byte("a\nb")}); err == nil {
+		t.Error("WriteFrame accepted a payload with a newline")
+	}
+} //빈 줄 무시, \r\n 제거, 마지막 줄바꿈 없어도 허용
+
+func TestTruncated(t *testing.T) {
+	for name, f := range map[string]Framer{"len32": LengthPrefix32(), "uvarint": Uvarint(), "devp2p+len32": Devp2p(LengthPrefix32())} {
+		first := len(writeFrames(t, f, []Frame{{Payload: []byte("first")}}))
+		data := writeFrames(t, f, []Frame{{Payload: []byte("first")}, {Payload: []byte("second")}})
+		for cut := first + 1; cut < len(data); cut++ { //두 번째 frame의 길이 prefix 중간과 payload 중간
+			got, err := readFrames(f, data[:cut], 0)
+			if len(got) != 1 || !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("%s cut at %d: %d frames, %v, want io.ErrUnexpectedEOF after one frame", name, cut, len(got), err)
+			}
+		}
+	}
+	if _, err := readFrames(Uvarint(), []byte{0x80}, 0); err != io.ErrUnexpectedEOF {
+		t.Errorf("uvarint prefix cut mid-varint = %v, want io.ErrUnexpectedEOF", err)
+	}
+	if _, err := readFrames(LengthPrefix32(), []byte{0, 0}, 0); err != io.ErrUnexpectedEOF {
+		t.Errorf("len32 prefix cut = %v, want io.ErrUnexpectedEOF", err)
+	}
+	if _, err := readFrames(Devp2p(LengthPrefix32()), []byte{0, 0, 0, 1, 0xb8}, 0); err == nil || errors.Is(err, io.EOF) {
+		t.Errorf("devp2p frame with a broken code = %v", err)
+	}
+} //frame 중간에서 끝난 스트림은 io.ErrUnexpectedEOF
+
+func TestLimit(t *testing.T) {
+	payload := []byte("0123456789")
+	for name, f := range map[string]Framer{"len32": LengthPrefix32(), "uvarint": Uvarint(), "newline": Newline()} {
+		data := writeFrames(t, f, []Frame{{Payload: payload}})
+		if got, err := readFrames(f, data, len(payload)); err != io.EOF || len(got) != 1 {
+			t.Errorf("%s: frame of exactly the limit = %d frames, %v", name, len(got), err)
+		}
+		if _, err := readFrames(f, data, len(payload)-1); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("%s: frame one byte over the limit = %v, want ErrTooLarge", name, err)
+		}
+	}
+	for _, in := range []string{"0123456789\r\n", "0123456789\n", "0123456789"} {
+		if got, err := readFrames(Newline(), []byte(in), 10); err != io.EOF || len(got) != 1 {
+			t.Errorf("newline %q with limit 10 = %d frames, %v", in, len(got), err)
+		}
+	}
+	for _, in := range []string{"0123456789a\n", "0123456789ab", "0123456789a\r\n", strings.Repeat("x", 100) + "\n"} { //줄바꿈 제외 길이 기준
+		if _, err := readFrames(Newline(), []byte(in), 10); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("newline %q with limit 10 = %v, want ErrTooLarge", in, err)
+		}
+	}
+	huge := map[string][]byte{
+		"len32":    {0xff, 0xff, 0xff, 0xff},
+		"uvarint":  {0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01},
+		"overflow": {0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x02}, //64비트 초과 uvarint
+	}
+	for name, data := range huge {
+		f := Uvarint()
+		if name == "len32" {
+			f = LengthPrefix32()
+		}
+		if _, err := readFrames(f, data, 1<<20); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("%s: length header beyond the limit = %v, want ErrTooLarge", name, err)
+		}
+	}
+	if _, err := readFrames(Uvarint(), huge["overflow"], 0); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("overflowing uvarint without a limit = %v, want ErrTooLarge", err)
+	}
+} //limit과 같은 frame은 통과, 넘으면 payload 할당 전에 ErrTooLarge
+
+func TestLookup(t *testing.T) {
+	good := map[string]Framer{
+		"len32":              lengthPrefix32{},
+		"uvarint":            uvarintPrefix{},
+		"newline":            newline{},
+		"devp2p+len32":       devp2p{inner: lengthPrefix32{}},
+		" devp2p + uvarint ": devp2p{inner: uvarintPrefix{}},
+	}
+	for spec, want := range good {
+		f, err := Lookup(spec)
+		if err != nil || f != want {
+			t.Errorf("Lookup(%q) = %#v, %v, want %#v", spec, f, err, want)
+		}
+	}
+	for _, spec := range []string{"", "len64", "LEN32", "devp2p", "devp2p+", "len32+uvarint", "len32+devp2p", "devp2p+devp2p"} {
+		if f, err := Lookup(spec); err == nil {
+			t.Errorf("Lookup(%q) = %#v, want an error", spec, f)
+		}
+	}
+} //이름 parsing(바깥 envelope부터, devp2p는 inner 필요, 잘못된 이름은 에러)
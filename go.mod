@@ -5,8 +5,11 @@ go 1.23.6
 require (
 	github.com/ethereum/go-ethereum v1.16.2
 	github.com/fardream/go-bcs v0.9.0
+	github.com/golang/snappy v1.0.0
+	github.com/klauspost/compress v1.18.0
 	github.com/vmihailenco/msgpack/v5 v5.4.1
 	google.golang.org/protobuf v1.36.7
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (